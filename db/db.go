@@ -15,6 +15,11 @@ var (
 	QuestionsCollection      *mongo.Collection
 	TestCasesCollection      *mongo.Collection
 	CodeSubmissionCollection *mongo.Collection
+	RejudgeJobsCollection    *mongo.Collection
+	RevisionsCollection      *mongo.Collection
+	TagsCollection           *mongo.Collection
+	PerformanceCollection    *mongo.Collection
+	AttachmentsCollection    *mongo.Collection
 	client                   *mongo.Client // Move the client to a package-level variable
 )
 
@@ -41,8 +46,14 @@ func ConnectDB() {
 	QuestionsCollection = client.Database("code_compiler").Collection("questions")
 	TestCasesCollection = client.Database("code_compiler").Collection("testcases")
 	CodeSubmissionCollection = client.Database("code_compiler").Collection("codeSubmission")
+	RejudgeJobsCollection = client.Database("code_compiler").Collection("rejudgeJobs")
+	RevisionsCollection = client.Database("code_compiler").Collection("questionRevisions")
+	TagsCollection = client.Database("code_compiler").Collection("tags")
+	PerformanceCollection = client.Database("code_compiler").Collection("performanceDistributions")
+	AttachmentsCollection = client.Database("code_compiler").Collection("attachments")
 
 	createIndexes()
+	applySchemaValidators(client.Database("code_compiler"))
 
 	fmt.Println("Pinged your deployment. You successfully connected to MongoDB!")
 }
@@ -87,6 +98,72 @@ func createIndexes() {
 	} else {
 		fmt.Println("Unique index created on CodeSubmissionCollection for question and email")
 	}
+
+	// Rejudge selects submissions of a question within a time range
+	codeSubmissionCreatedIndexModel := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "question", Value: 1},
+			{Key: "createdAt", Value: 1},
+		},
+	}
+	_, err = CodeSubmissionCollection.Indexes().CreateOne(context.TODO(), codeSubmissionCreatedIndexModel)
+	if err != nil {
+		log.Fatal("Failed to create index on CodeSubmissionCollection: ", err)
+	} else {
+		fmt.Println("Index created on CodeSubmissionCollection for question and createdAt")
+	}
+
+	// Old slugs are looked up to redirect to the current one
+	previousSlugsIndexModel := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "previousSlugs", Value: 1},
+		},
+	}
+	_, err = QuestionsCollection.Indexes().CreateOne(context.TODO(), previousSlugsIndexModel)
+	if err != nil {
+		log.Fatal("Failed to create index on QuestionsCollection: ", err)
+	} else {
+		fmt.Println("Index created on QuestionsCollection for previousSlugs")
+	}
+
+	revisionIndexModel := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "questionId", Value: 1},
+			{Key: "revision", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+	_, err = RevisionsCollection.Indexes().CreateOne(context.TODO(), revisionIndexModel)
+	if err != nil {
+		log.Fatal("Failed to create index on RevisionsCollection: ", err)
+	} else {
+		fmt.Println("Unique index created on RevisionsCollection for questionId and revision")
+	}
+
+	// Aliases are resolved to their canonical tag
+	tagAliasIndexModel := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "aliases", Value: 1},
+		},
+	}
+	_, err = TagsCollection.Indexes().CreateOne(context.TODO(), tagAliasIndexModel)
+	if err != nil {
+		log.Fatal("Failed to create index on TagsCollection: ", err)
+	} else {
+		fmt.Println("Index created on TagsCollection for aliases")
+	}
+
+	attachmentIndexModel := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "questionId", Value: 1},
+		},
+	}
+	_, err = AttachmentsCollection.Indexes().CreateOne(context.TODO(), attachmentIndexModel)
+	if err != nil {
+		log.Fatal("Failed to create index on AttachmentsCollection: ", err)
+	} else {
+		fmt.Println("Index created on AttachmentsCollection for questionId")
+	}
 }
 
 // DisconnectDB closes the MongoDB client connection.
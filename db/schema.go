@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var numberTypes = bson.A{"double", "int", "long", "decimal"}
+
+// questionSchema mirrors the checks of the question patch so writes that skip
+// the API are still rejected
+var questionSchema = bson.M{
+	"bsonType": "object",
+	"required": bson.A{"_id", "title", "slug", "difficulty"},
+	"properties": bson.M{
+		"_id":   bson.M{"bsonType": "string"},
+		"title": bson.M{"bsonType": "string", "minLength": 1},
+		"slug":  bson.M{"bsonType": "string", "minLength": 1},
+		"previousSlugs": bson.M{
+			"bsonType": "array",
+			"items":    bson.M{"bsonType": "string"},
+		},
+		"description":     bson.M{"bsonType": "string"},
+		"descriptionHtml": bson.M{"bsonType": "string"},
+		"difficulty":      bson.M{"enum": bson.A{"easy", "medium", "hard"}},
+		"tags": bson.M{
+			"bsonType": bson.A{"array", "null"},
+			"items":    bson.M{"bsonType": "string"},
+		},
+		"codeTemplates": bson.M{
+			"bsonType":             bson.A{"object", "null"},
+			"patternProperties":    bson.M{"^(c|cpp|java|go|py|js)$": bson.M{"bsonType": "object"}},
+			"additionalProperties": false,
+		},
+		"signature": bson.M{
+			"bsonType": bson.A{"object", "null"},
+			"required": bson.A{"functionName", "parameters", "returnType"},
+		},
+		"timeLimit":       bson.M{"bsonType": numberTypes, "minimum": 0, "exclusiveMinimum": true},
+		"memoryLimit":     bson.M{"bsonType": numberTypes, "minimum": 0, "exclusiveMinimum": true},
+		"isPublic":        bson.M{"bsonType": "bool"},
+		"solutionPolicy":  bson.M{"enum": bson.A{"after_solve", "after_time", "always", "never"}},
+		"submissionCount": bson.M{"bsonType": bson.A{"int", "long"}, "minimum": 0},
+		"successRate":     bson.M{"bsonType": numberTypes},
+		"stats":           bson.M{"bsonType": bson.A{"object", "null"}},
+		"locale":          bson.M{"bsonType": "string", "minLength": 2},
+		"users":           bson.M{"bsonType": bson.A{"object", "null"}},
+		"lifecycle":       bson.M{"enum": bson.A{"draft", "in_review", "scheduled", "published", "archived"}},
+		"publishAt":       bson.M{"bsonType": bson.A{"date", "null"}},
+		"revision":        bson.M{"bsonType": bson.A{"int", "long"}, "minimum": 0},
+		"translations": bson.M{
+			"bsonType": bson.A{"object", "null"},
+			"additionalProperties": bson.M{
+				"bsonType": "object",
+				"required": bson.A{"title", "description"},
+			},
+		},
+	},
+}
+
+var testCaseSchema = bson.M{
+	"bsonType": "object",
+	"required": bson.A{"_id", "questionId", "ioPairs"},
+	"properties": bson.M{
+		"_id":        bson.M{"bsonType": "string"},
+		"questionId": bson.M{"bsonType": "string", "minLength": 1},
+		"ioPairs": bson.M{
+			"bsonType": "array",
+			"items": bson.M{
+				"bsonType": "object",
+				"properties": bson.M{
+					"input":  bson.M{"bsonType": "string"},
+					"output": bson.M{"bsonType": "string"},
+				},
+			},
+		},
+		"group":        bson.M{"bsonType": "string"},
+		"visibility":   bson.M{"enum": bson.A{"", "public", "show_on_fail", "hidden"}},
+		"approved":     bson.M{"bsonType": "bool"},
+		"reviewStatus": bson.M{"enum": bson.A{"pending", "approved", "rejected"}},
+	},
+}
+
+// applySchemaValidators attaches the JSON schemas to their collections. The
+// moderate level leaves documents that were already invalid editable, so
+// older data does not block updates to the fields it does have right.
+func applySchemaValidators(database *mongo.Database) {
+	validators := map[string]bson.M{
+		"questions": questionSchema,
+		"testcases": testCaseSchema,
+	}
+	for collection, schema := range validators {
+		if err := applySchemaValidator(database, collection, schema); err != nil {
+			// Restricted database users may not run collMod, the API still validates
+			log.Println("Failed to apply schema validator on", collection, ":", err)
+		} else {
+			fmt.Println("Schema validator applied on", collection)
+		}
+	}
+}
+
+func applySchemaValidator(database *mongo.Database, collection string, schema bson.M) error {
+	validator := bson.M{"$jsonSchema": schema}
+	err := database.RunCommand(context.TODO(), bson.D{
+		{Key: "collMod", Value: collection},
+		{Key: "validator", Value: validator},
+		{Key: "validationLevel", Value: "moderate"},
+		{Key: "validationAction", Value: "error"},
+	}).Err()
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) && commandErr.Code == 26 { // NamespaceNotFound
+		return database.CreateCollection(context.TODO(), collection, options.CreateCollection().
+			SetValidator(validator).
+			SetValidationLevel("moderate").
+			SetValidationAction("error"))
+	}
+	return err
+}
@@ -0,0 +1,756 @@
+package codegen
+
+import (
+	"code-compiler/internal/models"
+	"fmt"
+	"strings"
+)
+
+var cTypes = map[string]string{
+	models.TypeInt:      "int",
+	models.TypeLong:     "long long",
+	models.TypeDouble:   "double",
+	models.TypeBool:     "bool",
+	models.TypeString:   "char*",
+	models.TypeList:     "struct ListNode*",
+	models.TypeTree:     "struct TreeNode*",
+	models.TypeNaryTree: "struct Node*",
+	models.TypeGraph:    "int**",
+	models.TypeMatrix:   "int**",
+}
+
+const cPrecode = `#include <stdbool.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+`
+
+const cRuntime = `
+void judge_fail(const char* expected) {
+    fprintf(stderr, "invalid input: expected %s\n", expected);
+    exit(1);
+}
+
+int judge_peek(void) {
+    int c = getchar();
+    while (c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == ',') {
+        c = getchar();
+    }
+    if (c != EOF) {
+        ungetc(c, stdin);
+    }
+    return c;
+}
+
+void judge_expect(int expected) {
+    if (judge_peek() != expected) {
+        char what[4] = {'\'', (char)expected, '\'', '\0'};
+        judge_fail(what);
+    }
+    getchar();
+}
+
+long long judge_read_long(void) {
+    long long value;
+    judge_peek();
+    if (scanf("%lld", &value) != 1) {
+        judge_fail("an integer");
+    }
+    return value;
+}
+
+int judge_read_int(void) {
+    return (int)judge_read_long();
+}
+
+bool judge_read_nullable(int* value) {
+    if (judge_peek() == 'n') {
+        char word[5] = {0};
+        if (scanf("%4[a-z]", word) != 1 || strcmp(word, "null") != 0) {
+            judge_fail("null");
+        }
+        return false;
+    }
+    *value = judge_read_int();
+    return true;
+}
+
+double judge_read_double(void) {
+    double value;
+    judge_peek();
+    if (scanf("%lf", &value) != 1) {
+        judge_fail("a number");
+    }
+    return value;
+}
+
+bool judge_read_bool(void) {
+    char word[6] = {0};
+    judge_peek();
+    if (scanf("%5[a-z]", word) != 1 || (strcmp(word, "true") != 0 && strcmp(word, "false") != 0)) {
+        judge_fail("true or false");
+    }
+    return word[0] == 't';
+}
+
+unsigned judge_read_hex4(void) {
+    unsigned value = 0;
+    for (int i = 0; i < 4; i++) {
+        int c = getchar();
+        if (c >= '0' && c <= '9') {
+            value = value << 4 | (unsigned)(c - '0');
+        } else if (c >= 'a' && c <= 'f') {
+            value = value << 4 | (unsigned)(c - 'a' + 10);
+        } else if (c >= 'A' && c <= 'F') {
+            value = value << 4 | (unsigned)(c - 'A' + 10);
+        } else {
+            judge_fail("four hex digits");
+        }
+    }
+    return value;
+}
+
+// judge_read_code_point reads the digits of a \u escape, joining surrogate pairs
+unsigned judge_read_code_point(void) {
+    unsigned high = judge_read_hex4();
+    if (high < 0xD800 || high > 0xDFFF) {
+        return high;
+    }
+    if (high > 0xDBFF || getchar() != '\\' || getchar() != 'u') {
+        judge_fail("a surrogate pair");
+    }
+    unsigned low = judge_read_hex4();
+    if (low < 0xDC00 || low > 0xDFFF) {
+        judge_fail("a surrogate pair");
+    }
+    return 0x10000 + ((high - 0xD800) << 10) + (low - 0xDC00);
+}
+
+// judge_put_utf8 encodes the code point at out and returns its length in bytes
+int judge_put_utf8(char* out, unsigned code) {
+    if (code < 0x80) {
+        out[0] = (char)code;
+        return 1;
+    }
+    if (code < 0x800) {
+        out[0] = (char)(0xC0 | code >> 6);
+        out[1] = (char)(0x80 | (code & 0x3F));
+        return 2;
+    }
+    if (code < 0x10000) {
+        out[0] = (char)(0xE0 | code >> 12);
+        out[1] = (char)(0x80 | (code >> 6 & 0x3F));
+        out[2] = (char)(0x80 | (code & 0x3F));
+        return 3;
+    }
+    out[0] = (char)(0xF0 | code >> 18);
+    out[1] = (char)(0x80 | (code >> 12 & 0x3F));
+    out[2] = (char)(0x80 | (code >> 6 & 0x3F));
+    out[3] = (char)(0x80 | (code & 0x3F));
+    return 4;
+}
+
+char* judge_read_string(void) {
+    size_t length = 0, capacity = 16;
+    char* value = malloc(capacity);
+    judge_expect('"');
+    for (;;) {
+        // room for the longest UTF-8 sequence and the terminator
+        if (length + 5 > capacity) {
+            capacity *= 2;
+            value = realloc(value, capacity);
+        }
+        int c = getchar();
+        if (c == EOF) {
+            judge_fail("the end of the string");
+        }
+        if (c == '"') {
+            break;
+        }
+        if (c == '\\') {
+            switch (c = getchar()) {
+            case 'n': c = '\n'; break;
+            case 't': c = '\t'; break;
+            case 'r': c = '\r'; break;
+            case 'b': c = '\b'; break;
+            case 'f': c = '\f'; break;
+            case '"': case '\\': case '/': break;
+            case 'u':
+                length += judge_put_utf8(value + length, judge_read_code_point());
+                continue;
+            default: judge_fail("a string escape");
+            }
+        }
+        value[length++] = (char)c;
+    }
+    value[length] = '\0';
+    return value;
+}
+
+void judge_write_int(int value) {
+    printf("%d", value);
+}
+
+void judge_write_long(long long value) {
+    printf("%lld", value);
+}
+
+void judge_write_double(double value) {
+    printf("%.5f", value);
+}
+
+void judge_write_bool(bool value) {
+    fputs(value ? "true" : "false", stdout);
+}
+
+void judge_write_string(const char* value) {
+    putchar('"');
+    for (; value != NULL && *value != '\0'; value++) {
+        switch (*value) {
+        case '"': fputs("\\\"", stdout); break;
+        case '\\': fputs("\\\\", stdout); break;
+        case '\n': fputs("\\n", stdout); break;
+        case '\r': fputs("\\r", stdout); break;
+        case '\t': fputs("\\t", stdout); break;
+        default: putchar(*value);
+        }
+    }
+    putchar('"');
+}
+
+void judge_finish(void) {
+    putchar('\n');
+}
+`
+
+// cArrayRuntime reads and writes arrays of {T}, the sizes are passed the way
+// LeetCode's C templates do
+var cArrayRuntime = [...]string{1: `
+{T}* judge_read_{B}_1(int* size) {
+    int capacity = 16;
+    {T}* values = malloc(capacity * sizeof({T}));
+    *size = 0;
+    judge_expect('[');
+    while (judge_peek() != ']') {
+        if (judge_peek() == EOF) {
+            judge_fail("']'");
+        }
+        if (*size == capacity) {
+            capacity *= 2;
+            values = realloc(values, capacity * sizeof({T}));
+        }
+        values[(*size)++] = judge_read_{B}();
+    }
+    getchar();
+    return values;
+}
+
+void judge_write_{B}_1({T}* values, int size) {
+    putchar('[');
+    for (int i = 0; i < size; i++) {
+        if (i > 0) {
+            putchar(',');
+        }
+        judge_write_{B}(values[i]);
+    }
+    putchar(']');
+}
+`, 2: `
+{T}** judge_read_{B}_2(int* size, int** colSizes) {
+    int capacity = 16;
+    {T}** rows = malloc(capacity * sizeof({T}*));
+    *colSizes = malloc(capacity * sizeof(int));
+    *size = 0;
+    judge_expect('[');
+    while (judge_peek() != ']') {
+        if (judge_peek() == EOF) {
+            judge_fail("']'");
+        }
+        if (*size == capacity) {
+            capacity *= 2;
+            rows = realloc(rows, capacity * sizeof({T}*));
+            *colSizes = realloc(*colSizes, capacity * sizeof(int));
+        }
+        rows[*size] = judge_read_{B}_1(&(*colSizes)[*size]);
+        (*size)++;
+    }
+    getchar();
+    return rows;
+}
+
+void judge_write_{B}_2({T}** rows, int size, int* colSizes) {
+    putchar('[');
+    for (int i = 0; i < size; i++) {
+        if (i > 0) {
+            putchar(',');
+        }
+        judge_write_{B}_1(rows[i], colSizes[i]);
+    }
+    putchar(']');
+}
+`}
+
+// cDefinitions declare the node types, users see them as a comment in the stub
+var cDefinitions = map[string]string{
+	models.TypeList: `// Definition for singly-linked list.
+struct ListNode {
+    int val;
+    struct ListNode *next;
+};
+`,
+	models.TypeTree: `// Definition for a binary tree node.
+struct TreeNode {
+    int val;
+    struct TreeNode *left;
+    struct TreeNode *right;
+};
+`,
+	models.TypeNaryTree: `// Definition for a Node.
+struct Node {
+    int val;
+    int numChildren;
+    struct Node** children;
+};
+`,
+}
+
+// cStructureRuntime reads and writes the node types
+var cStructureRuntime = map[string]string{
+	models.TypeList: `
+struct ListNode* judge_read_list(void) {
+    struct ListNode head = {0, NULL};
+    struct ListNode* tail = &head;
+    judge_expect('[');
+    while (judge_peek() != ']') {
+        if (judge_peek() == EOF) {
+            judge_fail("']'");
+        }
+        tail->next = malloc(sizeof(struct ListNode));
+        tail = tail->next;
+        tail->val = judge_read_int();
+        tail->next = NULL;
+    }
+    getchar();
+    return head.next;
+}
+
+void judge_write_list(struct ListNode* node) {
+    putchar('[');
+    for (bool first = true; node != NULL; node = node->next, first = false) {
+        if (!first) {
+            putchar(',');
+        }
+        printf("%d", node->val);
+    }
+    putchar(']');
+}
+`,
+	models.TypeTree: `
+struct TreeNode* judge_read_tree(void) {
+    int size = 0, capacity = 16;
+    struct TreeNode** nodes = malloc(capacity * sizeof(struct TreeNode*));
+    judge_expect('[');
+    while (judge_peek() != ']') {
+        int value;
+        if (judge_peek() == EOF) {
+            judge_fail("']'");
+        }
+        if (size == capacity) {
+            capacity *= 2;
+            nodes = realloc(nodes, capacity * sizeof(struct TreeNode*));
+        }
+        nodes[size] = NULL;
+        if (judge_read_nullable(&value)) {
+            nodes[size] = malloc(sizeof(struct TreeNode));
+            nodes[size]->val = value;
+            nodes[size]->left = NULL;
+            nodes[size]->right = NULL;
+        }
+        size++;
+    }
+    getchar();
+    // The children of each node follow in order, null nodes have none
+    for (int i = 0, child = 1; i < size && child < size; i++) {
+        if (nodes[i] == NULL) {
+            continue;
+        }
+        nodes[i]->left = nodes[child++];
+        if (child < size) {
+            nodes[i]->right = nodes[child++];
+        }
+    }
+    struct TreeNode* root = size > 0 ? nodes[0] : NULL;
+    free(nodes);
+    return root;
+}
+
+void judge_write_tree(struct TreeNode* root) {
+    int size = 0, capacity = 16, last = -1;
+    struct TreeNode** queue = malloc(capacity * sizeof(struct TreeNode*));
+    if (root != NULL) {
+        queue[size++] = root;
+    }
+    for (int i = 0; i < size; i++) {
+        if (queue[i] == NULL) {
+            continue;
+        }
+        last = i;
+        if (size + 2 > capacity) {
+            capacity *= 2;
+            queue = realloc(queue, capacity * sizeof(struct TreeNode*));
+        }
+        queue[size++] = queue[i]->left;
+        queue[size++] = queue[i]->right;
+    }
+    putchar('[');
+    for (int i = 0; i <= last; i++) {
+        if (i > 0) {
+            putchar(',');
+        }
+        if (queue[i] == NULL) {
+            fputs("null", stdout);
+        } else {
+            printf("%d", queue[i]->val);
+        }
+    }
+    putchar(']');
+    free(queue);
+}
+`,
+	models.TypeNaryTree: `
+struct Node* judge_new_node(int value) {
+    struct Node* node = malloc(sizeof(struct Node));
+    node->val = value;
+    node->numChildren = 0;
+    node->children = NULL;
+    return node;
+}
+
+struct Node* judge_read_narytree(void) {
+    int size = 0, capacity = 16;
+    int* values = malloc(capacity * sizeof(int));
+    bool* present = malloc(capacity * sizeof(bool));
+    judge_expect('[');
+    while (judge_peek() != ']') {
+        if (judge_peek() == EOF) {
+            judge_fail("']'");
+        }
+        if (size == capacity) {
+            capacity *= 2;
+            values = realloc(values, capacity * sizeof(int));
+            present = realloc(present, capacity * sizeof(bool));
+        }
+        present[size] = judge_read_nullable(&values[size]);
+        size++;
+    }
+    getchar();
+    struct Node* root = NULL;
+    if (size > 0 && present[0]) {
+        // After the root and a null, each node's children follow ended by a null
+        struct Node** queue = malloc(size * sizeof(struct Node*));
+        int queued = 0, position = 2;
+        root = queue[queued++] = judge_new_node(values[0]);
+        for (int i = 0; i < queued && position < size; i++) {
+            int first = position;
+            while (position < size && present[position]) {
+                position++;
+            }
+            queue[i]->numChildren = position - first;
+            queue[i]->children = malloc((position - first) * sizeof(struct Node*));
+            for (int j = first; j < position; j++) {
+                queue[i]->children[j - first] = queue[queued++] = judge_new_node(values[j]);
+            }
+            position++;
+        }
+        free(queue);
+    }
+    free(values);
+    free(present);
+    return root;
+}
+
+void judge_write_narytree(struct Node* root) {
+    int size = 0, capacity = 16, count = 0;
+    struct Node** queue = malloc(capacity * sizeof(struct Node*));
+    if (root != NULL) {
+        queue[size++] = root;
+    }
+    for (int i = 0; i < size; i++) {
+        for (int j = 0; j < queue[i]->numChildren; j++) {
+            if (size == capacity) {
+                capacity *= 2;
+                queue = realloc(queue, capacity * sizeof(struct Node*));
+            }
+            queue[size++] = queue[i]->children[j];
+        }
+    }
+    int* values = malloc((2 * size + 1) * sizeof(int));
+    bool* present = malloc((2 * size + 1) * sizeof(bool));
+    if (size > 0) {
+        values[count] = root->val;
+        present[count++] = true;
+        present[count++] = false;
+    }
+    for (int i = 0; i < size; i++) {
+        for (int j = 0; j < queue[i]->numChildren; j++) {
+            values[count] = queue[i]->children[j]->val;
+            present[count++] = true;
+        }
+        present[count++] = false;
+    }
+    while (count > 0 && !present[count - 1]) {
+        count--;
+    }
+    putchar('[');
+    for (int i = 0; i < count; i++) {
+        if (i > 0) {
+            putchar(',');
+        }
+        if (present[i]) {
+            printf("%d", values[i]);
+        } else {
+            fputs("null", stdout);
+        }
+    }
+    putchar(']');
+    free(queue);
+    free(values);
+    free(present);
+}
+`,
+}
+
+// cGridRuntime reads the types passed as rows of ints, after the int array helpers
+var cGridRuntime = map[string]string{
+	models.TypeGraph: `
+int** judge_read_graph(int* size, int** colSizes) {
+    int edgeCount;
+    int* edgeSizes;
+    judge_expect('[');
+    int n = judge_read_int();
+    int** edges = judge_read_int_2(&edgeCount, &edgeSizes);
+    judge_expect(']');
+    if (n < 0) {
+        judge_fail("a node count");
+    }
+    int** adjacency = malloc((n + 1) * sizeof(int*));
+    *colSizes = calloc(n + 1, sizeof(int));
+    for (int i = 0; i < edgeCount; i++) {
+        if (edgeSizes[i] != 2 || edges[i][0] < 0 || edges[i][0] >= n || edges[i][1] < 0 || edges[i][1] >= n) {
+            judge_fail("edges [u,v] between nodes 0 to n-1");
+        }
+        (*colSizes)[edges[i][0]]++;
+        (*colSizes)[edges[i][1]]++;
+    }
+    for (int i = 0; i < n; i++) {
+        adjacency[i] = malloc(((*colSizes)[i] + 1) * sizeof(int));
+        (*colSizes)[i] = 0;
+    }
+    for (int i = 0; i < edgeCount; i++) {
+        int u = edges[i][0], v = edges[i][1];
+        adjacency[u][(*colSizes)[u]++] = v;
+        adjacency[v][(*colSizes)[v]++] = u;
+    }
+    *size = n;
+    return adjacency;
+}
+`,
+	models.TypeMatrix: `
+int** judge_read_matrix(int* size, int** colSizes) {
+    int** rows = judge_read_int_2(size, colSizes);
+    for (int i = 1; i < *size; i++) {
+        if ((*colSizes)[i] != (*colSizes)[0]) {
+            judge_fail("rows of the same length");
+        }
+    }
+    return rows;
+}
+`,
+}
+
+// cArrayBases are the element types with array helpers, in the order they are emitted
+var cArrayBases = append(append([]string{}, models.ScalarTypes...), models.TypeList, models.TypeTree)
+
+func cType(t models.ValueType) string {
+	return cTypes[t.Base] + strings.Repeat("*", t.Depth)
+}
+
+// cDims is the number of sizes C passes along with the type
+func cDims(t models.ValueType) int {
+	if t.IsGrid() {
+		return 2
+	}
+	return t.Depth
+}
+
+// cFunctionName is the suffix of the runtime function reading the type
+func cFunctionName(t models.ValueType) string {
+	if t.Depth == 0 {
+		return t.Base
+	}
+	return fmt.Sprintf("%s_%d", t.Base, t.Depth)
+}
+
+// cWriteName is the suffix of the runtime function writing the type
+func cWriteName(t models.ValueType) string {
+	if t.Base == models.TypeMatrix {
+		return "int_2"
+	}
+	return cFunctionName(t)
+}
+
+// cParams spells the parameters with the sizes C needs for arrays
+func cParams(sig signature) string {
+	params := joinParams(sig.params, func(p param) string {
+		switch cDims(p.typ) {
+		case 1:
+			return fmt.Sprintf("%s %s, int %sSize", cType(p.typ), p.name, p.name)
+		case 2:
+			return fmt.Sprintf("%s %s, int %sSize, int* %sColSize", cType(p.typ), p.name, p.name, p.name)
+		}
+		return cType(p.typ) + " " + p.name
+	})
+	switch cDims(sig.result) {
+	case 1:
+		params += ", int* returnSize"
+	case 2:
+		params += ", int* returnSize, int** returnColumnSizes"
+	}
+	return params
+}
+
+// usedStructures lists the data structures of the signature in a stable order
+func usedStructures(sig signature) []string {
+	used := map[string]bool{sig.result.Base: true}
+	for _, p := range sig.params {
+		used[p.typ.Base] = true
+	}
+	structures := []string{}
+	for _, structure := range models.StructureTypes {
+		if used[structure] {
+			structures = append(structures, structure)
+		}
+	}
+	return structures
+}
+
+// definitionComment shows type definitions to the user as a block comment
+func definitionComment(definitions []string) string {
+	if len(definitions) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("/**\n")
+	for i, definition := range definitions {
+		if i > 0 {
+			b.WriteString(" *\n")
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(definition, "\n"), "\n") {
+			b.WriteString(strings.TrimRight(" * "+strings.TrimPrefix(line, "// "), " ") + "\n")
+		}
+	}
+	b.WriteString(" */\n")
+	return b.String()
+}
+
+func cStub(sig signature) string {
+	var b strings.Builder
+	var definitions []string
+	for _, structure := range usedStructures(sig) {
+		if definition, ok := cDefinitions[structure]; ok {
+			definitions = append(definitions, definition)
+		}
+	}
+	b.WriteString(definitionComment(definitions))
+	switch cDims(sig.result) {
+	case 1:
+		b.WriteString("/**\n * Return an array of *returnSize elements allocated with malloc.\n */\n")
+	case 2:
+		b.WriteString("/**\n * Return *returnSize rows allocated with malloc, row i has (*returnColumnSizes)[i]\n * elements and *returnColumnSizes is allocated with malloc too.\n */\n")
+	}
+	fmt.Fprintf(&b, "%s %s(%s) {\n", cType(sig.result), sig.name, cParams(sig))
+	switch {
+	case cDims(sig.result) == 2:
+		b.WriteString("    *returnSize = 0;\n    *returnColumnSizes = NULL;\n    return NULL;\n")
+	case cDims(sig.result) == 1:
+		b.WriteString("    *returnSize = 0;\n    return NULL;\n")
+	case sig.result.IsStructure():
+		b.WriteString("    return NULL;\n")
+	case sig.result.Base == models.TypeBool:
+		b.WriteString("    return false;\n")
+	case sig.result.Base == models.TypeString:
+		b.WriteString("    return \"\";\n")
+	default:
+		b.WriteString("    return 0;\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func cMain(sig signature) string {
+	var b strings.Builder
+	b.WriteString("\nint main(void) {\n")
+	args := make([]string, 0, len(sig.params)+2)
+	for _, p := range sig.params {
+		read := cFunctionName(p.typ)
+		switch cDims(p.typ) {
+		case 0:
+			fmt.Fprintf(&b, "    %s %s = judge_read_%s();\n", cType(p.typ), p.name, read)
+			args = append(args, p.name)
+		case 1:
+			fmt.Fprintf(&b, "    int %sSize;\n    %s %s = judge_read_%s(&%sSize);\n", p.name, cType(p.typ), p.name, read, p.name)
+			args = append(args, p.name, p.name+"Size")
+		case 2:
+			fmt.Fprintf(&b, "    int %sSize;\n    int* %sColSize;\n    %s %s = judge_read_%s(&%sSize, &%sColSize);\n", p.name, p.name, cType(p.typ), p.name, read, p.name, p.name)
+			args = append(args, p.name, p.name+"Size", p.name+"ColSize")
+		}
+	}
+	write := "judge_write_" + cWriteName(sig.result)
+	switch cDims(sig.result) {
+	case 0:
+		fmt.Fprintf(&b, "    %s result = %s(%s);\n    %s(result);\n", cType(sig.result), sig.name, strings.Join(args, ", "), write)
+	case 1:
+		args = append(args, "&returnSize")
+		fmt.Fprintf(&b, "    int returnSize = 0;\n    %s result = %s(%s);\n    %s(result, returnSize);\n", cType(sig.result), sig.name, strings.Join(args, ", "), write)
+	case 2:
+		args = append(args, "&returnSize", "&returnColumnSizes")
+		fmt.Fprintf(&b, "    int returnSize = 0;\n    int* returnColumnSizes = NULL;\n    %s result = %s(%s);\n    %s(result, returnSize, returnColumnSizes);\n", cType(sig.result), sig.name, strings.Join(args, ", "), write)
+	}
+	b.WriteString("    judge_finish();\n    return 0;\n}\n")
+	return b.String()
+}
+
+func generateC(sig signature) models.CodeTemplate {
+	var precode, postcode strings.Builder
+	precode.WriteString(cPrecode)
+	postcode.WriteString(cRuntime)
+	structures := usedStructures(sig)
+	for _, structure := range structures {
+		if definition, ok := cDefinitions[structure]; ok {
+			precode.WriteString("\n" + definition)
+		}
+		postcode.WriteString(cStructureRuntime[structure])
+	}
+	// Array helpers of every base and depth in use, shallower ones first
+	needed := map[models.ValueType]bool{}
+	for _, t := range append([]models.ValueType{sig.result}, paramTypes(sig)...) {
+		if t.IsGrid() {
+			t = models.ValueType{Base: models.TypeInt, Depth: 2}
+		}
+		for ; t.Depth > 0; t = t.Elem() {
+			needed[t] = true
+		}
+	}
+	for depth := 1; depth <= models.MaxArrayDepth; depth++ {
+		for _, base := range cArrayBases {
+			if needed[models.ValueType{Base: base, Depth: depth}] {
+				postcode.WriteString(strings.NewReplacer("{T}", cTypes[base], "{B}", base).Replace(cArrayRuntime[depth]))
+			}
+		}
+	}
+	for _, structure := range structures {
+		postcode.WriteString(cGridRuntime[structure])
+	}
+	postcode.WriteString(cMain(sig))
+	return models.CodeTemplate{Precode: precode.String(), Template: cStub(sig), Postcode: postcode.String()}
+}
@@ -0,0 +1,102 @@
+// Package codegen generates the code templates of a question from its function
+// signature: the stub users fill in and a harness around it that parses the
+// arguments from stdin, calls the function and prints the result.
+//
+// Test case inputs hold one JSON value per parameter, in the order of the
+// signature, separated by whitespace and usually on their own lines:
+//
+//	[2,7,11,15]
+//	9
+//
+// The result is printed as compact JSON on one line, with doubles rounded to
+// five decimals, so expected outputs read the same for every language. Lists,
+// trees, graphs and matrices are read and printed in their LeetCode forms, the
+// harness declares the node types and converts them from and to JSON.
+package codegen
+
+import (
+	"code-compiler/internal/models"
+	"fmt"
+	"strings"
+)
+
+type param struct {
+	name string
+	typ  models.ValueType
+}
+
+// signature is a validated function signature with parsed types
+type signature struct {
+	name   string
+	params []param
+	result models.ValueType
+}
+
+func parseSignature(s models.FunctionSignature) (signature, error) {
+	if err := s.Validate(); err != nil {
+		return signature{}, err
+	}
+	sig := signature{name: s.FunctionName}
+	for _, parameter := range s.Parameters {
+		t, _ := models.ParseValueType(parameter.Type)
+		sig.params = append(sig.params, param{name: parameter.Name, typ: t})
+	}
+	sig.result, _ = models.ParseValueType(s.ReturnType)
+	return sig, nil
+}
+
+// generators build the template of one language
+var generators = map[string]func(sig signature) models.CodeTemplate{
+	"c":    generateC,
+	"cpp":  generateCpp,
+	"java": generateJava,
+	"go":   generateGo,
+	"py":   generatePython,
+	"js":   generateJS,
+}
+
+// Generate returns the templates of every supported language
+func Generate(s models.FunctionSignature) (map[string]models.CodeTemplate, error) {
+	sig, err := parseSignature(s)
+	if err != nil {
+		return nil, err
+	}
+	templates := make(map[string]models.CodeTemplate, len(generators))
+	for _, language := range models.SupportedLanguages {
+		if generate, ok := generators[language]; ok {
+			templates[language] = generate(sig)
+		}
+	}
+	return templates, nil
+}
+
+// GenerateLanguage returns the template of one language
+func GenerateLanguage(s models.FunctionSignature, language string) (models.CodeTemplate, error) {
+	generate, ok := generators[language]
+	if !ok {
+		return models.CodeTemplate{}, fmt.Errorf("no template generator for %q", language)
+	}
+	sig, err := parseSignature(s)
+	if err != nil {
+		return models.CodeTemplate{}, err
+	}
+	return generate(sig), nil
+}
+
+// joinParams formats every parameter and joins them with commas
+func joinParams(params []param, format func(p param) string) string {
+	parts := make([]string, len(params))
+	for i, p := range params {
+		parts[i] = format(p)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// paramTypes lists the types of the parameters
+func paramTypes(sig signature) []models.ValueType {
+	types := make([]models.ValueType, len(sig.params))
+	for i, p := range sig.params {
+		types[i] = p.typ
+	}
+	return types
+}
@@ -0,0 +1,76 @@
+package codegen
+
+import (
+	"code-compiler/internal/models"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	twoSum := models.FunctionSignature{
+		FunctionName: "twoSum",
+		Parameters:   []models.Parameter{{Name: "nums", Type: "int[]"}, {Name: "target", Type: "int"}},
+		ReturnType:   "int[]",
+	}
+	tests := []struct {
+		name      string
+		signature models.FunctionSignature
+		stubs     map[string]string // part of the stub users see, per language
+		wantErr   bool
+	}{
+		{
+			"two sum",
+			twoSum,
+			map[string]string{
+				"c":    "int* twoSum(int* nums, int numsSize, int target, int* returnSize)",
+				"cpp":  "vector<int> twoSum(vector<int>& nums, int target)",
+				"java": "public int[] twoSum(int[] nums, int target)",
+				"go":   "func twoSum(nums []int, target int) []int",
+				"py":   "def twoSum(self, nums: List[int], target: int) -> List[int]:",
+				"js":   "var twoSum = function(nums, target)",
+			},
+			false,
+		},
+		{"no function name", models.FunctionSignature{Parameters: twoSum.Parameters, ReturnType: "int"}, nil, true},
+		{"no parameters", models.FunctionSignature{FunctionName: "f", ReturnType: "int"}, nil, true},
+		{"unknown type", models.FunctionSignature{FunctionName: "f", Parameters: []models.Parameter{{Name: "x", Type: "set"}}, ReturnType: "int"}, nil, true},
+		{"reserved name", models.FunctionSignature{FunctionName: "judgeRead", Parameters: twoSum.Parameters, ReturnType: "int"}, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			templates, err := Generate(test.signature)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Generate() error = %v, want error %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			if len(templates) != len(models.SupportedLanguages) {
+				t.Errorf("got templates for %d languages, want %d", len(templates), len(models.SupportedLanguages))
+			}
+			for language, stub := range test.stubs {
+				if !strings.Contains(templates[language].Template, stub) {
+					t.Errorf("%s stub\n%s\ndoes not contain %q", language, templates[language].Template, stub)
+				}
+				if templates[language].Postcode == "" {
+					t.Errorf("%s template has no harness", language)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateLanguage(t *testing.T) {
+	signature := models.FunctionSignature{FunctionName: "f", Parameters: []models.Parameter{{Name: "x", Type: "int"}}, ReturnType: "int"}
+	if _, err := GenerateLanguage(signature, "rust"); err == nil {
+		t.Error("GenerateLanguage accepted an unsupported language")
+	}
+	template, err := GenerateLanguage(signature, "go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, _ := Generate(signature)
+	if template != all["go"] {
+		t.Error("GenerateLanguage and Generate give different go templates")
+	}
+}
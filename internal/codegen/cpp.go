@@ -0,0 +1,520 @@
+package codegen
+
+import (
+	"code-compiler/internal/models"
+	"fmt"
+	"strings"
+)
+
+var cppTypes = map[string]string{
+	models.TypeInt:      "int",
+	models.TypeLong:     "long long",
+	models.TypeDouble:   "double",
+	models.TypeBool:     "bool",
+	models.TypeString:   "string",
+	models.TypeList:     "ListNode*",
+	models.TypeTree:     "TreeNode*",
+	models.TypeNaryTree: "Node*",
+	models.TypeGraph:    "vector<vector<int>>",
+	models.TypeMatrix:   "vector<vector<int>>",
+}
+
+const cppPrecode = `#include <bits/stdc++.h>
+using namespace std;
+`
+
+// cppRuntime reads and writes the scalar types through overloads
+const cppRuntime = `
+void judgeFail(const string& expected) {
+    cerr << "invalid input: expected " << expected << endl;
+    exit(1);
+}
+
+int judgePeek() {
+    int c = cin.peek();
+    while (c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == ',') {
+        cin.get();
+        c = cin.peek();
+    }
+    return c;
+}
+
+void judgeExpect(char expected) {
+    if (judgePeek() != expected) {
+        judgeFail(string("'") + expected + "'");
+    }
+    cin.get();
+}
+
+void judgeRead(long long& value) {
+    judgePeek();
+    if (!(cin >> value)) {
+        judgeFail("an integer");
+    }
+}
+
+void judgeRead(int& value) {
+    long long wide;
+    judgeRead(wide);
+    value = (int)wide;
+}
+
+bool judgeReadNullable(int& value) {
+    if (judgePeek() == 'n') {
+        string word;
+        while (isalpha(cin.peek())) {
+            word += (char)cin.get();
+        }
+        if (word != "null") {
+            judgeFail("null");
+        }
+        return false;
+    }
+    judgeRead(value);
+    return true;
+}
+
+void judgeRead(double& value) {
+    judgePeek();
+    if (!(cin >> value)) {
+        judgeFail("a number");
+    }
+}
+
+void judgeRead(bool& value) {
+    string word;
+    judgePeek();
+    while (isalpha(cin.peek())) {
+        word += (char)cin.get();
+    }
+    if (word != "true" && word != "false") {
+        judgeFail("true or false");
+    }
+    value = word == "true";
+}
+
+unsigned judgeReadHex4() {
+    unsigned value = 0;
+    for (int i = 0; i < 4; i++) {
+        int c = cin.get();
+        if (!isxdigit(c)) {
+            judgeFail("four hex digits");
+        }
+        value = value << 4 | (unsigned)(isdigit(c) ? c - '0' : tolower(c) - 'a' + 10);
+    }
+    return value;
+}
+
+// judgeReadCodePoint reads the digits of a \u escape, joining surrogate pairs
+unsigned judgeReadCodePoint() {
+    unsigned high = judgeReadHex4();
+    if (high < 0xD800 || high > 0xDFFF) {
+        return high;
+    }
+    if (high > 0xDBFF || cin.get() != '\\' || cin.get() != 'u') {
+        judgeFail("a surrogate pair");
+    }
+    unsigned low = judgeReadHex4();
+    if (low < 0xDC00 || low > 0xDFFF) {
+        judgeFail("a surrogate pair");
+    }
+    return 0x10000 + ((high - 0xD800) << 10) + (low - 0xDC00);
+}
+
+void judgeAppendUtf8(string& value, unsigned code) {
+    if (code < 0x80) {
+        value += (char)code;
+    } else if (code < 0x800) {
+        value += (char)(0xC0 | code >> 6);
+        value += (char)(0x80 | (code & 0x3F));
+    } else if (code < 0x10000) {
+        value += (char)(0xE0 | code >> 12);
+        value += (char)(0x80 | (code >> 6 & 0x3F));
+        value += (char)(0x80 | (code & 0x3F));
+    } else {
+        value += (char)(0xF0 | code >> 18);
+        value += (char)(0x80 | (code >> 12 & 0x3F));
+        value += (char)(0x80 | (code >> 6 & 0x3F));
+        value += (char)(0x80 | (code & 0x3F));
+    }
+}
+
+void judgeRead(string& value) {
+    value.clear();
+    judgeExpect('"');
+    for (;;) {
+        int c = cin.get();
+        if (c == EOF) {
+            judgeFail("the end of the string");
+        }
+        if (c == '"') {
+            break;
+        }
+        if (c == '\\') {
+            switch (c = cin.get()) {
+            case 'n': c = '\n'; break;
+            case 't': c = '\t'; break;
+            case 'r': c = '\r'; break;
+            case 'b': c = '\b'; break;
+            case 'f': c = '\f'; break;
+            case '"': case '\\': case '/': break;
+            case 'u':
+                judgeAppendUtf8(value, judgeReadCodePoint());
+                continue;
+            default: judgeFail("a string escape");
+            }
+        }
+        value += (char)c;
+    }
+}
+
+void judgeWrite(int value) {
+    cout << value;
+}
+
+void judgeWrite(long long value) {
+    cout << value;
+}
+
+void judgeWrite(double value) {
+    char text[64];
+    snprintf(text, sizeof(text), "%.5f", value);
+    cout << text;
+}
+
+void judgeWrite(bool value) {
+    cout << (value ? "true" : "false");
+}
+
+void judgeWrite(const string& value) {
+    cout << '"';
+    for (char c : value) {
+        switch (c) {
+        case '"': cout << "\\\""; break;
+        case '\\': cout << "\\\\"; break;
+        case '\n': cout << "\\n"; break;
+        case '\r': cout << "\\r"; break;
+        case '\t': cout << "\\t"; break;
+        default: cout << c;
+        }
+    }
+    cout << '"';
+}
+
+`
+
+// cppArrayRuntime reads and writes vectors of any type with an overload,
+// recursing into their elements
+const cppArrayRuntime = `
+template <typename T>
+void judgeRead(vector<T>& values) {
+    values.clear();
+    judgeExpect('[');
+    while (judgePeek() != ']') {
+        if (judgePeek() == EOF) {
+            judgeFail("']'");
+        }
+        T value;
+        judgeRead(value);
+        values.push_back(value);
+    }
+    cin.get();
+}
+
+template <typename T>
+void judgeWrite(const vector<T>& values) {
+    bool first = true;
+    cout << '[';
+    for (const auto& value : values) {
+        if (!first) {
+            cout << ',';
+        }
+        first = false;
+        judgeWrite(value);
+    }
+    cout << ']';
+}
+
+void judgeFinish() {
+    cout << '\n';
+}
+`
+
+// cppDefinitions declare the node types, users see them as a comment in the stub
+var cppDefinitions = map[string]string{
+	models.TypeList: `// Definition for singly-linked list.
+struct ListNode {
+    int val;
+    ListNode *next;
+    ListNode() : val(0), next(nullptr) {}
+    ListNode(int x) : val(x), next(nullptr) {}
+    ListNode(int x, ListNode *next) : val(x), next(next) {}
+};
+`,
+	models.TypeTree: `// Definition for a binary tree node.
+struct TreeNode {
+    int val;
+    TreeNode *left;
+    TreeNode *right;
+    TreeNode() : val(0), left(nullptr), right(nullptr) {}
+    TreeNode(int x) : val(x), left(nullptr), right(nullptr) {}
+    TreeNode(int x, TreeNode *left, TreeNode *right) : val(x), left(left), right(right) {}
+};
+`,
+	models.TypeNaryTree: `// Definition for a Node.
+class Node {
+public:
+    int val;
+    vector<Node*> children;
+
+    Node() {}
+    Node(int _val) { val = _val; }
+    Node(int _val, vector<Node*> _children) { val = _val; children = _children; }
+};
+`,
+}
+
+// cppStructureRuntime reads and writes the node types, before the vector
+// overloads so vectors of them resolve
+var cppStructureRuntime = map[string]string{
+	models.TypeList: `
+void judgeRead(ListNode*& head) {
+    ListNode dummy;
+    ListNode* tail = &dummy;
+    judgeExpect('[');
+    while (judgePeek() != ']') {
+        if (judgePeek() == EOF) {
+            judgeFail("']'");
+        }
+        int value;
+        judgeRead(value);
+        tail->next = new ListNode(value);
+        tail = tail->next;
+    }
+    cin.get();
+    head = dummy.next;
+}
+
+void judgeWrite(ListNode* node) {
+    cout << '[';
+    for (bool first = true; node != nullptr; node = node->next, first = false) {
+        if (!first) {
+            cout << ',';
+        }
+        cout << node->val;
+    }
+    cout << ']';
+}
+`,
+	models.TypeTree: `
+void judgeRead(TreeNode*& root) {
+    vector<TreeNode*> nodes;
+    judgeExpect('[');
+    while (judgePeek() != ']') {
+        if (judgePeek() == EOF) {
+            judgeFail("']'");
+        }
+        int value;
+        nodes.push_back(judgeReadNullable(value) ? new TreeNode(value) : nullptr);
+    }
+    cin.get();
+    // The children of each node follow in order, null nodes have none
+    size_t child = 1;
+    for (size_t i = 0; i < nodes.size() && child < nodes.size(); i++) {
+        if (nodes[i] == nullptr) {
+            continue;
+        }
+        nodes[i]->left = nodes[child++];
+        if (child < nodes.size()) {
+            nodes[i]->right = nodes[child++];
+        }
+    }
+    root = nodes.empty() ? nullptr : nodes[0];
+}
+
+void judgeWrite(TreeNode* root) {
+    vector<TreeNode*> queue;
+    size_t last = 0;
+    if (root != nullptr) {
+        queue.push_back(root);
+    }
+    for (size_t i = 0; i < queue.size(); i++) {
+        if (queue[i] != nullptr) {
+            last = i + 1;
+            queue.push_back(queue[i]->left);
+            queue.push_back(queue[i]->right);
+        }
+    }
+    cout << '[';
+    for (size_t i = 0; i < last; i++) {
+        if (i > 0) {
+            cout << ',';
+        }
+        if (queue[i] == nullptr) {
+            cout << "null";
+        } else {
+            cout << queue[i]->val;
+        }
+    }
+    cout << ']';
+}
+`,
+	models.TypeNaryTree: `
+void judgeRead(Node*& root) {
+    vector<int> values;
+    vector<bool> present;
+    judgeExpect('[');
+    while (judgePeek() != ']') {
+        if (judgePeek() == EOF) {
+            judgeFail("']'");
+        }
+        int value = 0;
+        present.push_back(judgeReadNullable(value));
+        values.push_back(value);
+    }
+    cin.get();
+    root = nullptr;
+    if (values.empty() || !present[0]) {
+        return;
+    }
+    // After the root and a null, each node's children follow ended by a null
+    vector<Node*> queue = {new Node(values[0])};
+    size_t position = 2;
+    for (size_t i = 0; i < queue.size() && position < values.size(); i++) {
+        for (; position < values.size() && present[position]; position++) {
+            queue[i]->children.push_back(new Node(values[position]));
+            queue.push_back(queue[i]->children.back());
+        }
+        position++;
+    }
+    root = queue[0];
+}
+
+void judgeWrite(Node* root) {
+    vector<Node*> queue;
+    vector<string> tokens;
+    if (root != nullptr) {
+        queue.push_back(root);
+        tokens = {to_string(root->val), "null"};
+    }
+    for (size_t i = 0; i < queue.size(); i++) {
+        for (Node* child : queue[i]->children) {
+            queue.push_back(child);
+            tokens.push_back(to_string(child->val));
+        }
+        tokens.push_back("null");
+    }
+    while (!tokens.empty() && tokens.back() == "null") {
+        tokens.pop_back();
+    }
+    cout << '[';
+    for (size_t i = 0; i < tokens.size(); i++) {
+        if (i > 0) {
+            cout << ',';
+        }
+        cout << tokens[i];
+    }
+    cout << ']';
+}
+`,
+}
+
+// cppGridRuntime reads the types passed as rows of ints
+var cppGridRuntime = map[string]string{
+	models.TypeGraph: `
+void judgeReadGraph(vector<vector<int>>& adjacency) {
+    int n;
+    vector<vector<int>> edges;
+    judgeExpect('[');
+    judgeRead(n);
+    judgeRead(edges);
+    judgeExpect(']');
+    if (n < 0) {
+        judgeFail("a node count");
+    }
+    adjacency.assign(n, {});
+    for (const auto& edge : edges) {
+        if (edge.size() != 2 || edge[0] < 0 || edge[0] >= n || edge[1] < 0 || edge[1] >= n) {
+            judgeFail("edges [u,v] between nodes 0 to n-1");
+        }
+        adjacency[edge[0]].push_back(edge[1]);
+        adjacency[edge[1]].push_back(edge[0]);
+    }
+}
+`,
+	models.TypeMatrix: `
+void judgeReadMatrix(vector<vector<int>>& rows) {
+    judgeRead(rows);
+    for (const auto& row : rows) {
+        if (row.size() != rows[0].size()) {
+            judgeFail("rows of the same length");
+        }
+    }
+}
+`,
+}
+
+func cppType(t models.ValueType) string {
+	if t.Depth == 0 {
+		return cppTypes[t.Base]
+	}
+	return "vector<" + cppType(t.Elem()) + ">"
+}
+
+func cppStub(sig signature) string {
+	params := joinParams(sig.params, func(p param) string {
+		if p.typ.Depth > 0 || p.typ.IsGrid() {
+			return cppType(p.typ) + "& " + p.name
+		}
+		return cppType(p.typ) + " " + p.name
+	})
+	result := "0"
+	switch {
+	case sig.result.Depth > 0 || sig.result.IsGrid():
+		result = "{}"
+	case sig.result.IsStructure():
+		result = "nullptr"
+	case sig.result.Base == models.TypeBool:
+		result = "false"
+	case sig.result.Base == models.TypeString:
+		result = `""`
+	}
+	var definitions []string
+	for _, structure := range usedStructures(sig) {
+		if definition, ok := cppDefinitions[structure]; ok {
+			definitions = append(definitions, definition)
+		}
+	}
+	return fmt.Sprintf("%sclass Solution {\npublic:\n    %s %s(%s) {\n        return %s;\n    }\n};\n", definitionComment(definitions), cppType(sig.result), sig.name, params, result)
+}
+
+func generateCpp(sig signature) models.CodeTemplate {
+	var precode, postcode strings.Builder
+	precode.WriteString(cppPrecode)
+	postcode.WriteString(cppRuntime)
+	structures := usedStructures(sig)
+	for _, structure := range structures {
+		if definition, ok := cppDefinitions[structure]; ok {
+			precode.WriteString("\n" + definition)
+		}
+		postcode.WriteString(cppStructureRuntime[structure])
+	}
+	postcode.WriteString(cppArrayRuntime)
+	for _, structure := range structures {
+		postcode.WriteString(cppGridRuntime[structure])
+	}
+	postcode.WriteString("\nint main() {\n")
+	for _, p := range sig.params {
+		read := "judgeRead"
+		switch {
+		case p.typ.IsGrid() && p.typ.Base == models.TypeGraph:
+			read = "judgeReadGraph"
+		case p.typ.IsGrid():
+			read = "judgeReadMatrix"
+		}
+		fmt.Fprintf(&postcode, "    %s %s;\n    %s(%s);\n", cppType(p.typ), p.name, read, p.name)
+	}
+	args := joinParams(sig.params, func(p param) string { return p.name })
+	fmt.Fprintf(&postcode, "    Solution solution;\n    %s result = solution.%s(%s);\n    judgeWrite(result);\n    judgeFinish();\n    return 0;\n}\n", cppType(sig.result), sig.name, args)
+	return models.CodeTemplate{Precode: precode.String(), Template: cppStub(sig), Postcode: postcode.String()}
+}
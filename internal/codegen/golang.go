@@ -0,0 +1,502 @@
+package codegen
+
+import (
+	"code-compiler/internal/models"
+	"fmt"
+	"strings"
+)
+
+var goTypes = map[string]string{
+	models.TypeInt:      "int",
+	models.TypeLong:     "int64",
+	models.TypeDouble:   "float64",
+	models.TypeBool:     "bool",
+	models.TypeString:   "string",
+	models.TypeList:     "*ListNode",
+	models.TypeTree:     "*TreeNode",
+	models.TypeNaryTree: "*Node",
+	models.TypeGraph:    "[][]int",
+	models.TypeMatrix:   "[][]int",
+}
+
+// goMethodNames name the judgeReader and judgeWriter methods of a type
+var goMethodNames = map[string]string{
+	models.TypeInt:      "Int",
+	models.TypeLong:     "Int64",
+	models.TypeDouble:   "Float64",
+	models.TypeBool:     "Bool",
+	models.TypeString:   "String",
+	models.TypeList:     "ListNode",
+	models.TypeTree:     "TreeNode",
+	models.TypeNaryTree: "NaryTree",
+	models.TypeGraph:    "Graph",
+	models.TypeMatrix:   "Matrix",
+}
+
+const goPrecode = `package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+`
+
+const goRuntime = `
+type judgeReader struct {
+	input    []byte
+	position int
+}
+
+func (r *judgeReader) fail(expected string) {
+	fmt.Fprintf(os.Stderr, "invalid input: expected %s at byte %d\n", expected, r.position)
+	os.Exit(1)
+}
+
+func (r *judgeReader) peek() byte {
+	for r.position < len(r.input) {
+		switch r.input[r.position] {
+		case ' ', '\n', '\r', '\t', ',':
+			r.position++
+		default:
+			return r.input[r.position]
+		}
+	}
+	return 0
+}
+
+func (r *judgeReader) expect(expected byte) {
+	if r.peek() != expected {
+		r.fail("'" + string(expected) + "'")
+	}
+	r.position++
+}
+
+func (r *judgeReader) token() string {
+	r.peek()
+	start := r.position
+	for r.position < len(r.input) {
+		switch r.input[r.position] {
+		case ' ', '\n', '\r', '\t', ',', ']':
+			return string(r.input[start:r.position])
+		}
+		r.position++
+	}
+	return string(r.input[start:])
+}
+
+func (r *judgeReader) readInt64() int64 {
+	value, err := strconv.ParseInt(r.token(), 10, 64)
+	if err != nil {
+		r.fail("an integer")
+	}
+	return value
+}
+
+func (r *judgeReader) readInt() int {
+	return int(r.readInt64())
+}
+
+// readNullable reads an integer or null, as in the level order of a tree
+func (r *judgeReader) readNullable() *int {
+	token := r.token()
+	if token == "null" {
+		return nil
+	}
+	value, err := strconv.Atoi(token)
+	if err != nil {
+		r.fail("an integer or null")
+	}
+	return &value
+}
+
+func (r *judgeReader) readFloat64() float64 {
+	value, err := strconv.ParseFloat(r.token(), 64)
+	if err != nil {
+		r.fail("a number")
+	}
+	return value
+}
+
+func (r *judgeReader) readBool() bool {
+	value, err := strconv.ParseBool(r.token())
+	if err != nil {
+		r.fail("true or false")
+	}
+	return value
+}
+
+func (r *judgeReader) readString() string {
+	r.expect('"')
+	var value []byte
+	for {
+		if r.position >= len(r.input) {
+			r.fail("the end of the string")
+		}
+		c := r.input[r.position]
+		r.position++
+		if c == '"' {
+			return string(value)
+		}
+		if c == '\\' && r.position < len(r.input) {
+			c = r.input[r.position]
+			r.position++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 't':
+				c = '\t'
+			case 'r':
+				c = '\r'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '"', '\\', '/':
+			case 'u':
+				value = utf8.AppendRune(value, r.readCodePoint())
+				continue
+			default:
+				r.fail("a string escape")
+			}
+		}
+		value = append(value, c)
+	}
+}
+
+func (r *judgeReader) readHex4() rune {
+	if r.position+4 > len(r.input) {
+		r.fail("four hex digits")
+	}
+	value, err := strconv.ParseUint(string(r.input[r.position:r.position+4]), 16, 16)
+	if err != nil {
+		r.fail("four hex digits")
+	}
+	r.position += 4
+	return rune(value)
+}
+
+// readCodePoint reads the digits of a \u escape, joining surrogate pairs
+func (r *judgeReader) readCodePoint() rune {
+	unit := r.readHex4()
+	if !utf16.IsSurrogate(unit) {
+		return unit
+	}
+	if r.position+2 > len(r.input) || r.input[r.position] != '\\' || r.input[r.position+1] != 'u' {
+		r.fail("a surrogate pair")
+	}
+	r.position += 2
+	code := utf16.DecodeRune(unit, r.readHex4())
+	if code == utf8.RuneError {
+		r.fail("a surrogate pair")
+	}
+	return code
+}
+
+func judgeReadSlice[T any](r *judgeReader, read func() T) []T {
+	r.expect('[')
+	values := []T{}
+	for r.peek() != ']' {
+		if r.position >= len(r.input) {
+			r.fail("']'")
+		}
+		values = append(values, read())
+	}
+	r.position++
+	return values
+}
+
+type judgeWriter struct {
+	*bufio.Writer
+}
+
+func (w judgeWriter) writeInt(value int) {
+	w.WriteString(strconv.Itoa(value))
+}
+
+func (w judgeWriter) writeInt64(value int64) {
+	w.WriteString(strconv.FormatInt(value, 10))
+}
+
+func (w judgeWriter) writeFloat64(value float64) {
+	w.WriteString(strconv.FormatFloat(value, 'f', 5, 64))
+}
+
+func (w judgeWriter) writeBool(value bool) {
+	w.WriteString(strconv.FormatBool(value))
+}
+
+func (w judgeWriter) writeString(value string) {
+	w.WriteByte('"')
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '"':
+			w.WriteString("\\\"")
+		case '\\':
+			w.WriteString("\\\\")
+		case '\n':
+			w.WriteString("\\n")
+		case '\r':
+			w.WriteString("\\r")
+		case '\t':
+			w.WriteString("\\t")
+		default:
+			w.WriteByte(value[i])
+		}
+	}
+	w.WriteByte('"')
+}
+
+func judgeWriteSlice[T any](w judgeWriter, values []T, write func(T)) {
+	w.WriteByte('[')
+	for i, value := range values {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		write(value)
+	}
+	w.WriteByte(']')
+}
+
+func judgeNewReader() *judgeReader {
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return &judgeReader{input: input}
+}
+`
+
+// goDefinitions declare the node types, users see them as a comment in the stub
+var goDefinitions = map[string]string{
+	models.TypeList: `// Definition for singly-linked list.
+type ListNode struct {
+	Val  int
+	Next *ListNode
+}
+`,
+	models.TypeTree: `// Definition for a binary tree node.
+type TreeNode struct {
+	Val   int
+	Left  *TreeNode
+	Right *TreeNode
+}
+`,
+	models.TypeNaryTree: `// Definition for a Node.
+type Node struct {
+	Val      int
+	Children []*Node
+}
+`,
+}
+
+// goStructureRuntime reads and writes the node types and grids
+var goStructureRuntime = map[string]string{
+	models.TypeList: `
+func (r *judgeReader) readListNode() *ListNode {
+	head := &ListNode{}
+	tail := head
+	for _, value := range judgeReadSlice(r, r.readInt) {
+		tail.Next = &ListNode{Val: value}
+		tail = tail.Next
+	}
+	return head.Next
+}
+
+func (w judgeWriter) writeListNode(head *ListNode) {
+	w.WriteByte('[')
+	for node := head; node != nil; node = node.Next {
+		if node != head {
+			w.WriteByte(',')
+		}
+		w.WriteString(strconv.Itoa(node.Val))
+	}
+	w.WriteByte(']')
+}
+`,
+	models.TypeTree: `
+func (r *judgeReader) readTreeNode() *TreeNode {
+	values := judgeReadSlice(r, r.readNullable)
+	if len(values) == 0 || values[0] == nil {
+		return nil
+	}
+	// Each node present takes the next two values as its children
+	queue := []*TreeNode{{Val: *values[0]}}
+	next := 1
+	for i := 0; i < len(queue) && next < len(values); i++ {
+		children := []**TreeNode{&queue[i].Left, &queue[i].Right}
+		for _, child := range children {
+			if next < len(values) && values[next] != nil {
+				*child = &TreeNode{Val: *values[next]}
+				queue = append(queue, *child)
+			}
+			next++
+		}
+	}
+	return queue[0]
+}
+
+func (w judgeWriter) writeTreeNode(root *TreeNode) {
+	queue := []*TreeNode{root}
+	last := 0
+	for i := 0; i < len(queue); i++ {
+		if queue[i] != nil {
+			last = i + 1
+			queue = append(queue, queue[i].Left, queue[i].Right)
+		}
+	}
+	w.WriteByte('[')
+	for i, node := range queue[:last] {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		if node == nil {
+			w.WriteString("null")
+		} else {
+			w.WriteString(strconv.Itoa(node.Val))
+		}
+	}
+	w.WriteByte(']')
+}
+`,
+	models.TypeNaryTree: `
+func (r *judgeReader) readNaryTree() *Node {
+	values := judgeReadSlice(r, r.readNullable)
+	if len(values) == 0 || values[0] == nil {
+		return nil
+	}
+	// The children of each node follow in order, each group ended by null
+	queue := []*Node{{Val: *values[0]}}
+	next := 2
+	for i := 0; i < len(queue) && next < len(values); i++ {
+		for ; next < len(values) && values[next] != nil; next++ {
+			child := &Node{Val: *values[next]}
+			queue[i].Children = append(queue[i].Children, child)
+			queue = append(queue, child)
+		}
+		next++
+	}
+	return queue[0]
+}
+
+func (w judgeWriter) writeNaryTree(root *Node) {
+	var tokens []string
+	if root != nil {
+		tokens = append(tokens, strconv.Itoa(root.Val), "null")
+	}
+	for queue := []*Node{root}; root != nil && len(queue) > 0; queue = queue[1:] {
+		for _, child := range queue[0].Children {
+			queue = append(queue, child)
+			tokens = append(tokens, strconv.Itoa(child.Val))
+		}
+		tokens = append(tokens, "null")
+	}
+	for len(tokens) > 0 && tokens[len(tokens)-1] == "null" {
+		tokens = tokens[:len(tokens)-1]
+	}
+	judgeWriteSlice(w, tokens, func(token string) { w.WriteString(token) })
+}
+`,
+	models.TypeGraph: `
+func (r *judgeReader) readGraph() [][]int {
+	r.expect('[')
+	n := r.readInt()
+	edges := judgeReadSlice(r, func() []int { return judgeReadSlice(r, r.readInt) })
+	r.expect(']')
+	if n < 0 {
+		r.fail("a node count")
+	}
+	adjacency := make([][]int, n)
+	for i := range adjacency {
+		adjacency[i] = []int{}
+	}
+	for _, edge := range edges {
+		if len(edge) != 2 || edge[0] < 0 || edge[0] >= n || edge[1] < 0 || edge[1] >= n {
+			r.fail("edges [u,v] between nodes 0 to n-1")
+		}
+		adjacency[edge[0]] = append(adjacency[edge[0]], edge[1])
+		adjacency[edge[1]] = append(adjacency[edge[1]], edge[0])
+	}
+	return adjacency
+}
+`,
+	models.TypeMatrix: `
+func (r *judgeReader) readMatrix() [][]int {
+	rows := judgeReadSlice(r, func() []int { return judgeReadSlice(r, r.readInt) })
+	for _, row := range rows {
+		if len(row) != len(rows[0]) {
+			r.fail("rows of the same length")
+		}
+	}
+	return rows
+}
+
+func (w judgeWriter) writeMatrix(rows [][]int) {
+	judgeWriteSlice(w, rows, func(row []int) { judgeWriteSlice(w, row, w.writeInt) })
+}
+`,
+}
+
+func goType(t models.ValueType) string {
+	return strings.Repeat("[]", t.Depth) + goTypes[t.Base]
+}
+
+// goReadFunc is an expression of type func() T reading the type
+func goReadFunc(t models.ValueType) string {
+	if t.Depth == 0 {
+		return "reader.read" + goMethodNames[t.Base]
+	}
+	return fmt.Sprintf("func() %s { return judgeReadSlice(reader, %s) }", goType(t), goReadFunc(t.Elem()))
+}
+
+// goWriteFunc is an expression of type func(T) writing the type
+func goWriteFunc(t models.ValueType) string {
+	if t.Depth == 0 {
+		return "writer.write" + goMethodNames[t.Base]
+	}
+	return fmt.Sprintf("func(values %s) { judgeWriteSlice(writer, values, %s) }", goType(t), goWriteFunc(t.Elem()))
+}
+
+func goStub(sig signature) string {
+	params := joinParams(sig.params, func(p param) string { return p.name + " " + goType(p.typ) })
+	result := "0"
+	switch {
+	case sig.result.Depth > 0 || sig.result.IsStructure():
+		result = "nil"
+	case sig.result.Base == models.TypeBool:
+		result = "false"
+	case sig.result.Base == models.TypeString:
+		result = `""`
+	}
+	var definitions []string
+	for _, structure := range usedStructures(sig) {
+		if definition, ok := goDefinitions[structure]; ok {
+			definitions = append(definitions, definition)
+		}
+	}
+	return fmt.Sprintf("%sfunc %s(%s) %s {\n\treturn %s\n}\n", definitionComment(definitions), sig.name, params, goType(sig.result), result)
+}
+
+func generateGo(sig signature) models.CodeTemplate {
+	var precode, postcode strings.Builder
+	precode.WriteString(goPrecode)
+	postcode.WriteString(goRuntime)
+	for _, structure := range usedStructures(sig) {
+		if definition, ok := goDefinitions[structure]; ok {
+			precode.WriteString("\n" + definition)
+		}
+		postcode.WriteString(goStructureRuntime[structure])
+	}
+	postcode.WriteString("\nfunc main() {\n\treader := judgeNewReader()\n\twriter := judgeWriter{bufio.NewWriter(os.Stdout)}\n\tdefer writer.Flush()\n")
+	for _, p := range sig.params {
+		fmt.Fprintf(&postcode, "\t%s := (%s)()\n", p.name, goReadFunc(p.typ))
+	}
+	args := joinParams(sig.params, func(p param) string { return p.name })
+	fmt.Fprintf(&postcode, "\tresult := %s(%s)\n\t(%s)(result)\n\twriter.WriteByte('\\n')\n}\n", sig.name, args, goWriteFunc(sig.result))
+	return models.CodeTemplate{Precode: precode.String(), Template: goStub(sig), Postcode: postcode.String()}
+}
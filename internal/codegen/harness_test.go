@@ -0,0 +1,284 @@
+package codegen
+
+import (
+	"bytes"
+	"code-compiler/internal/models"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// harness builds and runs a generated template around a solution
+type harness struct {
+	file    string
+	compile []string // empty for interpreted languages, the binary is written to bin
+	run     []string
+}
+
+var harnesses = map[string]harness{
+	"c":    {file: "main.c", compile: []string{"gcc", "-o", "bin", "main.c"}, run: []string{"./bin"}},
+	"cpp":  {file: "main.cpp", compile: []string{"g++", "-o", "bin", "main.cpp"}, run: []string{"./bin"}},
+	"go":   {file: "main.go", compile: []string{"go", "build", "-o", "bin", "main.go"}, run: []string{"./bin"}},
+	"java": {file: "Main.java", compile: []string{"javac", "Main.java"}, run: []string{"java", "Main"}},
+	"py":   {file: "main.py", run: []string{"python3", "main.py"}},
+	"js":   {file: "main.js", run: []string{"node", "main.js"}},
+}
+
+// echoSolutions return their string argument
+var echoSolutions = map[string]string{
+	"c":    "char* echo(char* s) { return s; }",
+	"cpp":  "class Solution { public: string echo(string s) { return s; } };",
+	"go":   "func echo(s string) string { return s }",
+	"java": "class Solution { public String echo(String s) { return s; } }",
+	"py":   "class Solution:\n    def echo(self, s: str) -> str:\n        return s\n",
+	"js":   "var echo = function(s) { return s; };",
+}
+
+// buildHarness writes the program to a temporary directory and compiles it,
+// it skips the test when the toolchain of the language is missing
+func buildHarness(t *testing.T, language string, template models.CodeTemplate, solution string) (dir string, run []string) {
+	t.Helper()
+	h := harnesses[language]
+	tool := h.run[0]
+	if len(h.compile) > 0 {
+		tool = h.compile[0]
+	}
+	if _, err := exec.LookPath(tool); err != nil {
+		t.Skipf("%s is not installed", tool)
+	}
+	dir = t.TempDir()
+	code := template.Precode + "\n" + solution + "\n" + strings.ReplaceAll(template.Postcode, "{{FILENAME}}", "Main")
+	if err := os.WriteFile(filepath.Join(dir, h.file), []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if len(h.compile) > 0 {
+		cmd := exec.Command(h.compile[0], h.compile[1:]...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("compiling the %s harness: %v\n%s", language, err, output)
+		}
+	}
+	return dir, h.run
+}
+
+func runHarness(dir string, run []string, input string) (string, error) {
+	cmd := exec.Command(run[0], run[1:]...)
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(input)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	err := cmd.Run()
+	return strings.TrimSpace(stdout.String()), err
+}
+
+func TestStringEscapes(t *testing.T) {
+	signature := models.FunctionSignature{FunctionName: "echo", Parameters: []models.Parameter{{Name: "s", Type: "string"}}, ReturnType: "string"}
+	tests := []struct {
+		name  string
+		input string
+		want  string // empty when the input has to be rejected
+	}{
+		{"plain", `"plain"`, `"plain"`},
+		{"short escapes", `"a\tb\"c\\d\/e"`, `"a\tb\"c\\d/e"`},
+		{"two byte", `"h\u00e9"`, `"hé"`},
+		{"upper case digits", `"\u00C9"`, `"É"`},
+		{"three byte", `"\u4e16\u754c"`, `"世界"`},
+		{"ascii", `"\u0041\u0042"`, `"AB"`},
+		{"surrogate pair", `"\ud83d\ude00!"`, `"😀!"`},
+		{"raw utf-8", `"😀é"`, `"😀é"`},
+		{"lone high surrogate", `"\ud83d"`, ""},
+		{"lone low surrogate", `"\ude00"`, ""},
+		{"high surrogate twice", `"\ud83d\ud83d"`, ""},
+		{"short escape", `"\u12"`, ""},
+		{"not hex", `"\uzzzz"`, ""},
+	}
+	for _, language := range []string{"c", "cpp", "go", "java"} {
+		t.Run(language, func(t *testing.T) {
+			template, err := GenerateLanguage(signature, language)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir, run := buildHarness(t, language, template, echoSolutions[language])
+			for _, test := range tests {
+				output, err := runHarness(dir, run, test.input+"\n")
+				if test.want == "" {
+					if err == nil {
+						t.Errorf("%s: input %s was accepted as %s", test.name, test.input, output)
+					}
+					continue
+				}
+				if err != nil || output != test.want {
+					t.Errorf("%s: input %s gave %s (%v), want %s", test.name, test.input, output, err, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestHarnesses(t *testing.T) {
+	tests := []struct {
+		name      string
+		signature models.FunctionSignature
+		solutions map[string]string
+		input     string
+		want      string
+	}{
+		{
+			"echo a string",
+			models.FunctionSignature{FunctionName: "echo", Parameters: []models.Parameter{{Name: "s", Type: "string"}}, ReturnType: "string"},
+			echoSolutions,
+			`"h\u00e9 \"there\""`,
+			`"hé \"there\""`,
+		},
+		{
+			"sum a list",
+			models.FunctionSignature{FunctionName: "total", Parameters: []models.Parameter{{Name: "nums", Type: "int[]"}, {Name: "extra", Type: "long"}}, ReturnType: "long"},
+			map[string]string{
+				"c":    "long long total(int* nums, int numsSize, long long extra) { for (int i = 0; i < numsSize; i++) extra += nums[i]; return extra; }",
+				"cpp":  "class Solution { public: long long total(vector<int>& nums, long long extra) { for (int n : nums) extra += n; return extra; } };",
+				"go":   "func total(nums []int, extra int64) int64 { for _, n := range nums { extra += int64(n) }; return extra }",
+				"java": "class Solution { public long total(int[] nums, long extra) { for (int n : nums) extra += n; return extra; } }",
+				"py":   "class Solution:\n    def total(self, nums: List[int], extra: int) -> int:\n        return sum(nums) + extra\n",
+				"js":   "var total = function(nums, extra) { return nums.reduce((a, b) => a + b, extra); };",
+			},
+			"[1,2,3]\n10000000000",
+			"10000000006",
+		},
+	}
+	for _, test := range tests {
+		for language := range harnesses {
+			t.Run(test.name+"/"+language, func(t *testing.T) {
+				template, err := GenerateLanguage(test.signature, language)
+				if err != nil {
+					t.Fatal(err)
+				}
+				dir, run := buildHarness(t, language, template, test.solutions[language])
+				output, err := runHarness(dir, run, test.input+"\n")
+				if err != nil || output != test.want {
+					t.Errorf("input %s gave %s (%v), want %s", test.input, output, err, test.want)
+				}
+			})
+		}
+	}
+}
+
+// sameSolutions return their argument, per structure and language
+var sameSolutions = map[string]map[string]string{
+	models.TypeList: {
+		"c":    "struct ListNode* same(struct ListNode* x) { return x; }",
+		"cpp":  "class Solution { public: ListNode* same(ListNode* x) { return x; } };",
+		"go":   "func same(x *ListNode) *ListNode { return x }",
+		"java": "class Solution { public ListNode same(ListNode x) { return x; } }",
+		"py":   "class Solution:\n    def same(self, x):\n        return x\n",
+		"js":   "var same = function(x) { return x; };",
+	},
+	models.TypeTree: {
+		"c":    "struct TreeNode* same(struct TreeNode* x) { return x; }",
+		"cpp":  "class Solution { public: TreeNode* same(TreeNode* x) { return x; } };",
+		"go":   "func same(x *TreeNode) *TreeNode { return x }",
+		"java": "class Solution { public TreeNode same(TreeNode x) { return x; } }",
+		"py":   "class Solution:\n    def same(self, x):\n        return x\n",
+		"js":   "var same = function(x) { return x; };",
+	},
+	models.TypeNaryTree: {
+		"c":    "struct Node* same(struct Node* x) { return x; }",
+		"cpp":  "class Solution { public: Node* same(Node* x) { return x; } };",
+		"go":   "func same(x *Node) *Node { return x }",
+		"java": "class Solution { public Node same(Node x) { return x; } }",
+		"py":   "class Solution:\n    def same(self, x):\n        return x\n",
+		"js":   "var same = function(x) { return x; };",
+	},
+	models.TypeMatrix: {
+		"c":    "int** same(int** x, int xSize, int* xColSize, int* returnSize, int** returnColumnSizes) { *returnSize = xSize; *returnColumnSizes = xColSize; return x; }",
+		"cpp":  "class Solution { public: vector<vector<int>> same(vector<vector<int>>& x) { return x; } };",
+		"go":   "func same(x [][]int) [][]int { return x }",
+		"java": "class Solution { public int[][] same(int[][] x) { return x; } }",
+		"py":   "class Solution:\n    def same(self, x):\n        return x\n",
+		"js":   "var same = function(x) { return x; };",
+	},
+	// Graphs are only parameters, the adjacency lists come back as int[][]
+	models.TypeGraph: {
+		"c":    "int** same(int** x, int xSize, int* xColSize, int* returnSize, int** returnColumnSizes) { *returnSize = xSize; *returnColumnSizes = xColSize; return x; }",
+		"cpp":  "class Solution { public: vector<vector<int>> same(vector<vector<int>>& x) { return x; } };",
+		"go":   "func same(x [][]int) [][]int { return x }",
+		"java": "class Solution { public int[][] same(List<List<Integer>> x) { int[][] r = new int[x.size()][]; for (int i = 0; i < r.length; i++) r[i] = x.get(i).stream().mapToInt(Integer::intValue).toArray(); return r; } }",
+		"py":   "class Solution:\n    def same(self, x):\n        return x\n",
+		"js":   "var same = function(x) { return x; };",
+	},
+}
+
+func TestStructureHarnesses(t *testing.T) {
+	tests := []struct {
+		structure string
+		cases     [][2]string // input and the output it is written back as, empty when it has to be rejected
+	}{
+		{models.TypeList, [][2]string{
+			{"[1,2,3]", "[1,2,3]"},
+			{"[-7]", "[-7]"},
+			{"[]", "[]"},
+			{"[1,2", ""},
+		}},
+		{models.TypeTree, [][2]string{
+			{"[1,null,2,3]", "[1,null,2,3]"},
+			{"[3,9,20,null,null,15,7]", "[3,9,20,null,null,15,7]"},
+			{"[1,null,2,null,null]", "[1,null,2]"},
+			{"[1]", "[1]"},
+			{"[]", "[]"},
+		}},
+		{models.TypeNaryTree, [][2]string{
+			{"[1,null,3,2,4,null,5,6]", "[1,null,3,2,4,null,5,6]"},
+			{"[1,null,2,3,null,null,4]", "[1,null,2,3,null,null,4]"},
+			{"[1]", "[1]"},
+			{"[]", "[]"},
+		}},
+		{models.TypeMatrix, [][2]string{
+			{"[[1,2],[3,4]]", "[[1,2],[3,4]]"},
+			{"[[-5]]", "[[-5]]"},
+			{"[]", "[]"},
+			{"[[1,2],[3]]", ""},
+		}},
+		{models.TypeGraph, [][2]string{
+			{"[3,[[0,1],[1,2]]]", "[[1],[0,2],[1]]"},
+			{"[4,[[0,3],[0,1]]]", "[[3,1],[0],[],[0]]"},
+			{"[2,[]]", "[[],[]]"},
+			{"[0,[]]", "[]"},
+			{"[2,[[0,2]]]", ""},
+			{"[-1,[]]", ""},
+			{"[1.5,[]]", ""},
+		}},
+	}
+	for _, test := range tests {
+		returnType := test.structure
+		if test.structure == models.TypeGraph {
+			returnType = "int[][]"
+		}
+		signature := models.FunctionSignature{
+			FunctionName: "same",
+			Parameters:   []models.Parameter{{Name: "x", Type: test.structure}},
+			ReturnType:   returnType,
+		}
+		for language := range harnesses {
+			t.Run(test.structure+"/"+language, func(t *testing.T) {
+				template, err := GenerateLanguage(signature, language)
+				if err != nil {
+					t.Fatal(err)
+				}
+				dir, run := buildHarness(t, language, template, sameSolutions[test.structure][language])
+				for _, c := range test.cases {
+					output, err := runHarness(dir, run, c[0]+"\n")
+					if c[1] == "" {
+						if err == nil {
+							t.Errorf("input %s was accepted as %s", c[0], output)
+						}
+						continue
+					}
+					if err != nil || output != c[1] {
+						t.Errorf("input %s gave %s (%v), want %s", c[0], output, err, c[1])
+					}
+				}
+			})
+		}
+	}
+}
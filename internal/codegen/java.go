@@ -0,0 +1,529 @@
+package codegen
+
+import (
+	"code-compiler/internal/models"
+	"fmt"
+	"strings"
+)
+
+var javaTypes = map[string]string{
+	models.TypeInt:      "int",
+	models.TypeLong:     "long",
+	models.TypeDouble:   "double",
+	models.TypeBool:     "boolean",
+	models.TypeString:   "String",
+	models.TypeList:     "ListNode",
+	models.TypeTree:     "TreeNode",
+	models.TypeNaryTree: "Node",
+	models.TypeGraph:    "List<List<Integer>>",
+	models.TypeMatrix:   "int[][]",
+}
+
+// javaMethodNames name the Judge methods reading a type
+var javaMethodNames = map[string]string{
+	models.TypeInt:      "Int",
+	models.TypeLong:     "Long",
+	models.TypeDouble:   "Double",
+	models.TypeBool:     "Bool",
+	models.TypeString:   "String",
+	models.TypeList:     "ListNode",
+	models.TypeTree:     "TreeNode",
+	models.TypeNaryTree: "NaryTree",
+	models.TypeGraph:    "Graph",
+	models.TypeMatrix:   "Matrix",
+}
+
+const javaPrecode = `import java.io.*;
+import java.nio.charset.StandardCharsets;
+import java.util.*;
+import java.util.function.*;
+`
+
+// javaRuntime opens the Judge class with the readers of the scalar types,
+// the structure methods and javaWriteRuntime follow
+const javaRuntime = `
+class Judge {
+    private final byte[] input;
+    private int position;
+    private final StringBuilder output = new StringBuilder();
+
+    Judge() throws IOException {
+        input = System.in.readAllBytes();
+    }
+
+    private RuntimeException fail(String expected) {
+        return new IllegalArgumentException("invalid input: expected " + expected + " at byte " + position);
+    }
+
+    private int peek() {
+        while (position < input.length && (input[position] == ' ' || input[position] == '\n'
+                || input[position] == '\r' || input[position] == '\t' || input[position] == ',')) {
+            position++;
+        }
+        return position < input.length ? input[position] : -1;
+    }
+
+    private void expect(char expected) {
+        if (peek() != expected) {
+            throw fail("'" + expected + "'");
+        }
+        position++;
+    }
+
+    private String token() {
+        peek();
+        int start = position;
+        while (position < input.length && input[position] != ',' && input[position] != ']'
+                && !Character.isWhitespace(input[position])) {
+            position++;
+        }
+        return new String(input, start, position - start, StandardCharsets.UTF_8);
+    }
+
+    int readInt() {
+        return Integer.parseInt(token());
+    }
+
+    long readLong() {
+        return Long.parseLong(token());
+    }
+
+    double readDouble() {
+        return Double.parseDouble(token());
+    }
+
+    Integer readNullable() {
+        String token = token();
+        return token.equals("null") ? null : Integer.valueOf(token);
+    }
+
+    boolean readBool() {
+        String token = token();
+        if (!token.equals("true") && !token.equals("false")) {
+            throw fail("true or false");
+        }
+        return token.equals("true");
+    }
+
+    String readString() {
+        expect('"');
+        ByteArrayOutputStream bytes = new ByteArrayOutputStream();
+        for (;;) {
+            if (position >= input.length) {
+                throw fail("the end of the string");
+            }
+            int c = input[position++];
+            if (c == '"') {
+                break;
+            }
+            if (c == '\\' && position < input.length) {
+                switch (c = input[position++]) {
+                    case 'n': c = '\n'; break;
+                    case 't': c = '\t'; break;
+                    case 'r': c = '\r'; break;
+                    case 'b': c = '\b'; break;
+                    case 'f': c = '\f'; break;
+                    case '"': case '\\': case '/': break;
+                    case 'u':
+                        byte[] encoded = new String(Character.toChars(readCodePoint())).getBytes(StandardCharsets.UTF_8);
+                        bytes.write(encoded, 0, encoded.length);
+                        continue;
+                    default: throw fail("a string escape");
+                }
+            }
+            bytes.write(c);
+        }
+        return new String(bytes.toByteArray(), StandardCharsets.UTF_8);
+    }
+
+    private char readHex4() {
+        int value = 0;
+        for (int i = 0; i < 4; i++) {
+            int digit = position < input.length ? Character.digit(input[position++], 16) : -1;
+            if (digit < 0) {
+                throw fail("four hex digits");
+            }
+            value = value << 4 | digit;
+        }
+        return (char) value;
+    }
+
+    // readCodePoint reads the UTF-16 code units of a \u escape, joining surrogate pairs
+    private int readCodePoint() {
+        char unit = readHex4();
+        if (!Character.isSurrogate(unit)) {
+            return unit;
+        }
+        if (!Character.isHighSurrogate(unit) || position + 1 >= input.length
+                || input[position] != '\\' || input[position + 1] != 'u') {
+            throw fail("a surrogate pair");
+        }
+        position += 2;
+        char low = readHex4();
+        if (!Character.isLowSurrogate(low)) {
+            throw fail("a surrogate pair");
+        }
+        return Character.toCodePoint(unit, low);
+    }
+
+    <T> List<T> readList(Supplier<T> element) {
+        expect('[');
+        List<T> values = new ArrayList<>();
+        while (peek() != ']') {
+            if (peek() == -1) {
+                throw fail("']'");
+            }
+            values.add(element.get());
+        }
+        position++;
+        return values;
+    }
+
+    int[] readIntArray() {
+        return readList(this::readInt).stream().mapToInt(Integer::intValue).toArray();
+    }
+
+    long[] readLongArray() {
+        return readList(this::readLong).stream().mapToLong(Long::longValue).toArray();
+    }
+
+    double[] readDoubleArray() {
+        return readList(this::readDouble).stream().mapToDouble(Double::doubleValue).toArray();
+    }
+
+    boolean[] readBoolArray() {
+        List<Boolean> values = readList(this::readBool);
+        boolean[] array = new boolean[values.size()];
+        for (int i = 0; i < array.length; i++) {
+            array[i] = values.get(i);
+        }
+        return array;
+    }
+
+    String[] readStringArray() {
+        return readList(this::readString).toArray(new String[0]);
+    }
+`
+
+// javaWriteRuntime writes results and closes the Judge class, node types
+// have writers of their own so a null node prints as empty
+const javaWriteRuntime = `
+    <T> void writeArray(T[] values, Consumer<T> write) {
+        output.append('[');
+        for (int i = 0; i < values.length; i++) {
+            if (i > 0) {
+                output.append(',');
+            }
+            write.accept(values[i]);
+        }
+        output.append(']');
+    }
+
+    void write(Object value) {
+        if (value instanceof Double) {
+            output.append(String.format(Locale.ROOT, "%.5f", (Double) value));
+        } else if (value instanceof String) {
+            output.append('"');
+            for (char c : ((String) value).toCharArray()) {
+                switch (c) {
+                    case '"': output.append("\\\""); break;
+                    case '\\': output.append("\\\\"); break;
+                    case '\n': output.append("\\n"); break;
+                    case '\r': output.append("\\r"); break;
+                    case '\t': output.append("\\t"); break;
+                    default: output.append(c);
+                }
+            }
+            output.append('"');
+        } else if (value instanceof int[]) {
+            write(Arrays.stream((int[]) value).boxed().toArray());
+        } else if (value instanceof long[]) {
+            write(Arrays.stream((long[]) value).boxed().toArray());
+        } else if (value instanceof double[]) {
+            write(Arrays.stream((double[]) value).boxed().toArray());
+        } else if (value instanceof boolean[]) {
+            boolean[] array = (boolean[]) value;
+            Object[] boxed = new Object[array.length];
+            for (int i = 0; i < array.length; i++) {
+                boxed[i] = array[i];
+            }
+            write(boxed);
+        } else if (value instanceof Object[]) {
+            Object[] values = (Object[]) value;
+            output.append('[');
+            for (int i = 0; i < values.length; i++) {
+                if (i > 0) {
+                    output.append(',');
+                }
+                write(values[i]);
+            }
+            output.append(']');
+        } else {
+            output.append(value);
+        }
+    }
+
+    void finish() {
+        System.out.println(output);
+    }
+}
+`
+
+// javaDefinitions declare the node types, users see them as a comment in the stub
+var javaDefinitions = map[string]string{
+	models.TypeList: `// Definition for singly-linked list.
+class ListNode {
+    int val;
+    ListNode next;
+    ListNode() {}
+    ListNode(int val) { this.val = val; }
+    ListNode(int val, ListNode next) { this.val = val; this.next = next; }
+}
+`,
+	models.TypeTree: `// Definition for a binary tree node.
+class TreeNode {
+    int val;
+    TreeNode left;
+    TreeNode right;
+    TreeNode() {}
+    TreeNode(int val) { this.val = val; }
+    TreeNode(int val, TreeNode left, TreeNode right) {
+        this.val = val;
+        this.left = left;
+        this.right = right;
+    }
+}
+`,
+	models.TypeNaryTree: `// Definition for a Node.
+class Node {
+    public int val;
+    public List<Node> children;
+
+    public Node() {}
+
+    public Node(int _val) {
+        val = _val;
+    }
+
+    public Node(int _val, List<Node> _children) {
+        val = _val;
+        children = _children;
+    }
+}
+`,
+}
+
+// javaStructureRuntime are the Judge methods reading and writing a data structure
+var javaStructureRuntime = map[string]string{
+	models.TypeList: `
+    ListNode readListNode() {
+        ListNode dummy = new ListNode();
+        ListNode tail = dummy;
+        for (int value : readIntArray()) {
+            tail.next = new ListNode(value);
+            tail = tail.next;
+        }
+        return dummy.next;
+    }
+
+    void writeListNode(ListNode node) {
+        output.append('[');
+        for (boolean first = true; node != null; node = node.next, first = false) {
+            if (!first) {
+                output.append(',');
+            }
+            output.append(node.val);
+        }
+        output.append(']');
+    }
+`,
+	models.TypeTree: `
+    TreeNode readTreeNode() {
+        List<TreeNode> nodes = new ArrayList<>();
+        for (Integer value : readList(this::readNullable)) {
+            nodes.add(value == null ? null : new TreeNode(value));
+        }
+        // The children of each node follow in order, null nodes have none
+        int child = 1;
+        for (int i = 0; i < nodes.size() && child < nodes.size(); i++) {
+            if (nodes.get(i) == null) {
+                continue;
+            }
+            nodes.get(i).left = nodes.get(child++);
+            if (child < nodes.size()) {
+                nodes.get(i).right = nodes.get(child++);
+            }
+        }
+        return nodes.isEmpty() ? null : nodes.get(0);
+    }
+
+    void writeTreeNode(TreeNode root) {
+        List<TreeNode> queue = new ArrayList<>();
+        int last = 0;
+        if (root != null) {
+            queue.add(root);
+        }
+        for (int i = 0; i < queue.size(); i++) {
+            if (queue.get(i) != null) {
+                last = i + 1;
+                queue.add(queue.get(i).left);
+                queue.add(queue.get(i).right);
+            }
+        }
+        output.append('[');
+        for (int i = 0; i < last; i++) {
+            if (i > 0) {
+                output.append(',');
+            }
+            output.append(queue.get(i) == null ? "null" : String.valueOf(queue.get(i).val));
+        }
+        output.append(']');
+    }
+`,
+	models.TypeNaryTree: `
+    Node readNaryTree() {
+        List<Integer> values = readList(this::readNullable);
+        if (values.isEmpty() || values.get(0) == null) {
+            return null;
+        }
+        // After the root and a null, each node's children follow ended by a null
+        List<Node> queue = new ArrayList<>();
+        queue.add(new Node(values.get(0), new ArrayList<>()));
+        int position = 2;
+        for (int i = 0; i < queue.size() && position < values.size(); i++) {
+            for (; position < values.size() && values.get(position) != null; position++) {
+                Node child = new Node(values.get(position), new ArrayList<>());
+                queue.get(i).children.add(child);
+                queue.add(child);
+            }
+            position++;
+        }
+        return queue.get(0);
+    }
+
+    void writeNaryTree(Node root) {
+        List<Node> queue = new ArrayList<>();
+        List<String> tokens = new ArrayList<>();
+        if (root != null) {
+            queue.add(root);
+            tokens.add(String.valueOf(root.val));
+            tokens.add("null");
+        }
+        for (int i = 0; i < queue.size(); i++) {
+            if (queue.get(i).children != null) {
+                for (Node child : queue.get(i).children) {
+                    queue.add(child);
+                    tokens.add(String.valueOf(child.val));
+                }
+            }
+            tokens.add("null");
+        }
+        while (!tokens.isEmpty() && tokens.get(tokens.size() - 1).equals("null")) {
+            tokens.remove(tokens.size() - 1);
+        }
+        output.append('[').append(String.join(",", tokens)).append(']');
+    }
+`,
+	models.TypeGraph: `
+    List<List<Integer>> readGraph() {
+        expect('[');
+        int n = readInt();
+        int[][] edges = readList(this::readIntArray).toArray(new int[0][]);
+        expect(']');
+        if (n < 0) {
+            throw fail("a node count");
+        }
+        List<List<Integer>> adjacency = new ArrayList<>();
+        for (int i = 0; i < n; i++) {
+            adjacency.add(new ArrayList<>());
+        }
+        for (int[] edge : edges) {
+            if (edge.length != 2 || edge[0] < 0 || edge[0] >= n || edge[1] < 0 || edge[1] >= n) {
+                throw fail("edges [u,v] between nodes 0 to n-1");
+            }
+            adjacency.get(edge[0]).add(edge[1]);
+            adjacency.get(edge[1]).add(edge[0]);
+        }
+        return adjacency;
+    }
+`,
+	models.TypeMatrix: `
+    int[][] readMatrix() {
+        int[][] rows = readList(this::readIntArray).toArray(new int[0][]);
+        for (int[] row : rows) {
+            if (row.length != rows[0].length) {
+                throw fail("rows of the same length");
+            }
+        }
+        return rows;
+    }
+`,
+}
+
+func javaType(t models.ValueType) string {
+	return javaTypes[t.Base] + strings.Repeat("[]", t.Depth)
+}
+
+func javaRead(t models.ValueType) string {
+	switch {
+	case t.Depth == 0:
+		return "judge.read" + javaMethodNames[t.Base] + "()"
+	case t.IsStructure():
+		return fmt.Sprintf("judge.readList(judge::read%s).toArray(new %s[0])", javaMethodNames[t.Base], javaTypes[t.Base])
+	case t.Depth == 1:
+		return "judge.read" + javaMethodNames[t.Base] + "Array()"
+	}
+	return fmt.Sprintf("judge.readList(judge::read%sArray).toArray(new %s[0][])", javaMethodNames[t.Base], javaTypes[t.Base])
+}
+
+func javaStub(sig signature) string {
+	params := joinParams(sig.params, func(p param) string { return javaType(p.typ) + " " + p.name })
+	result := "0"
+	switch {
+	case sig.result.Base == models.TypeMatrix:
+		result = "new int[0][]"
+	case sig.result.Depth > 0:
+		result = "new " + javaTypes[sig.result.Base] + "[0]" + strings.Repeat("[]", sig.result.Depth-1)
+	case sig.result.IsStructure():
+		result = "null"
+	case sig.result.Base == models.TypeBool:
+		result = "false"
+	case sig.result.Base == models.TypeString:
+		result = `""`
+	}
+	var definitions []string
+	for _, structure := range usedStructures(sig) {
+		if definition, ok := javaDefinitions[structure]; ok {
+			definitions = append(definitions, definition)
+		}
+	}
+	return fmt.Sprintf("%sclass Solution {\n    public %s %s(%s) {\n        return %s;\n    }\n}\n", definitionComment(definitions), javaType(sig.result), sig.name, params, result)
+}
+
+func generateJava(sig signature) models.CodeTemplate {
+	var precode, postcode strings.Builder
+	precode.WriteString(javaPrecode)
+	postcode.WriteString(javaRuntime)
+	structures := usedStructures(sig)
+	for _, structure := range structures {
+		if definition, ok := javaDefinitions[structure]; ok {
+			precode.WriteString("\n" + definition)
+		}
+		postcode.WriteString(javaStructureRuntime[structure])
+	}
+	postcode.WriteString(javaWriteRuntime)
+	postcode.WriteString("\npublic class {{FILENAME}} {\n    public static void main(String[] args) throws IOException {\n        Judge judge = new Judge();\n")
+	for _, p := range sig.params {
+		fmt.Fprintf(&postcode, "        %s %s = %s;\n", javaType(p.typ), p.name, javaRead(p.typ))
+	}
+	args := joinParams(sig.params, func(p param) string { return p.name })
+	write := "judge.write(result)"
+	if _, ok := javaDefinitions[sig.result.Base]; ok {
+		write = "judge.write" + javaMethodNames[sig.result.Base] + "(result)"
+		if sig.result.Depth > 0 {
+			write = "judge.writeArray(result, judge::write" + javaMethodNames[sig.result.Base] + ")"
+		}
+	}
+	fmt.Fprintf(&postcode, "        %s result = new Solution().%s(%s);\n        %s;\n        judge.finish();\n    }\n}\n", javaType(sig.result), sig.name, args, write)
+	return models.CodeTemplate{Precode: precode.String(), Template: javaStub(sig), Postcode: postcode.String()}
+}
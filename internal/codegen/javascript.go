@@ -0,0 +1,281 @@
+package codegen
+
+import (
+	"code-compiler/internal/models"
+	"fmt"
+	"strings"
+)
+
+var jsTypes = map[string]string{
+	models.TypeInt:      "number",
+	models.TypeLong:     "number",
+	models.TypeDouble:   "number",
+	models.TypeBool:     "boolean",
+	models.TypeString:   "string",
+	models.TypeList:     "ListNode",
+	models.TypeTree:     "TreeNode",
+	models.TypeNaryTree: "_Node",
+	models.TypeGraph:    "number[][]",
+	models.TypeMatrix:   "number[][]",
+}
+
+const jsRuntime = `
+function judgeReadValues(text) {
+    const values = [];
+    let position = 0;
+    for (;;) {
+        while (position < text.length && " \t\r\n,".includes(text[position])) {
+            position++;
+        }
+        if (position >= text.length) {
+            return values;
+        }
+        const start = position;
+        let depth = 0;
+        let inString = false;
+        for (; position < text.length; position++) {
+            const c = text[position];
+            if (inString) {
+                if (c === "\\") {
+                    position++;
+                } else if (c === '"') {
+                    inString = false;
+                    if (depth === 0) {
+                        position++;
+                        break;
+                    }
+                }
+            } else if (c === '"') {
+                inString = true;
+            } else if (c === "[") {
+                depth++;
+            } else if (c === "]") {
+                depth--;
+                if (depth === 0) {
+                    position++;
+                    break;
+                }
+            } else if (depth === 0 && " \t\r\n,".includes(c)) {
+                break;
+            }
+        }
+        values.push(JSON.parse(text.slice(start, position)));
+    }
+}
+
+function judgeFail(expected) {
+    process.stderr.write("invalid input: expected " + expected + "\n");
+    process.exit(1);
+}
+
+const judgeParsers = {};
+const judgeFormatters = {};
+
+function judgeParse(value, valueType) {
+    if (valueType.endsWith("[]")) {
+        return value.map((element) => judgeParse(element, valueType.slice(0, -2)));
+    }
+    return valueType in judgeParsers ? judgeParsers[valueType](value) : value;
+}
+
+function judgeFormat(value, valueType) {
+    if (valueType in judgeFormatters) {
+        return judgeFormatters[valueType](value);
+    }
+    if (value === null || value === undefined) {
+        return "null";
+    }
+    if (valueType.endsWith("[]")) {
+        return "[" + value.map((element) => judgeFormat(element, valueType.slice(0, -2))).join(",") + "]";
+    }
+    switch (valueType) {
+        case "double":
+            return Number(value).toFixed(5);
+        case "bool":
+            return value ? "true" : "false";
+        case "string":
+            return JSON.stringify(value);
+    }
+    return String(Math.trunc(value));
+}
+`
+
+// jsDefinitions declare the node types, users see them as a comment in the stub
+var jsDefinitions = map[string]string{
+	models.TypeList: `// Definition for singly-linked list.
+function ListNode(val, next) {
+    this.val = (val === undefined ? 0 : val);
+    this.next = (next === undefined ? null : next);
+}
+`,
+	models.TypeTree: `// Definition for a binary tree node.
+function TreeNode(val, left, right) {
+    this.val = (val === undefined ? 0 : val);
+    this.left = (left === undefined ? null : left);
+    this.right = (right === undefined ? null : right);
+}
+`,
+	models.TypeNaryTree: `// Definition for a _Node.
+function _Node(val, children) {
+    this.val = val;
+    this.children = children;
+}
+`,
+}
+
+// jsStructureRuntime converts the node types and grids from and to their JSON form
+var jsStructureRuntime = map[string]string{
+	models.TypeList: `
+judgeParsers.list = (values) => {
+    const head = new ListNode();
+    let tail = head;
+    for (const value of values) {
+        tail.next = new ListNode(value);
+        tail = tail.next;
+    }
+    return head.next;
+};
+
+judgeFormatters.list = (head) => {
+    const values = [];
+    for (let node = head; node; node = node.next) {
+        values.push(String(node.val));
+    }
+    return "[" + values.join(",") + "]";
+};
+`,
+	models.TypeTree: `
+judgeParsers.tree = (values) => {
+    if (values.length === 0 || values[0] === null) {
+        return null;
+    }
+    const queue = [new TreeNode(values[0])];
+    let next = 1;
+    for (let i = 0; i < queue.length && next < values.length; i++) {
+        for (const side of ["left", "right"]) {
+            if (next < values.length && values[next] !== null) {
+                queue[i][side] = new TreeNode(values[next]);
+                queue.push(queue[i][side]);
+            }
+            next++;
+        }
+    }
+    return queue[0];
+};
+
+judgeFormatters.tree = (root) => {
+    const queue = [root];
+    for (let i = 0; i < queue.length; i++) {
+        if (queue[i]) {
+            queue.push(queue[i].left, queue[i].right);
+        }
+    }
+    while (queue.length > 0 && !queue[queue.length - 1]) {
+        queue.pop();
+    }
+    return "[" + queue.map((node) => (node ? String(node.val) : "null")).join(",") + "]";
+};
+`,
+	models.TypeNaryTree: `
+judgeParsers.narytree = (values) => {
+    if (values.length === 0 || values[0] === null) {
+        return null;
+    }
+    // The children of each node follow in order, each group ended by null
+    const queue = [new _Node(values[0], [])];
+    let next = 2;
+    for (let i = 0; i < queue.length && next < values.length; i++) {
+        for (; next < values.length && values[next] !== null; next++) {
+            const child = new _Node(values[next], []);
+            queue[i].children.push(child);
+            queue.push(child);
+        }
+        next++;
+    }
+    return queue[0];
+};
+
+judgeFormatters.narytree = (root) => {
+    if (!root) {
+        return "[]";
+    }
+    const tokens = [String(root.val), "null"];
+    const queue = [root];
+    for (let i = 0; i < queue.length; i++) {
+        for (const child of queue[i].children || []) {
+            queue.push(child);
+            tokens.push(String(child.val));
+        }
+        tokens.push("null");
+    }
+    while (tokens[tokens.length - 1] === "null") {
+        tokens.pop();
+    }
+    return "[" + tokens.join(",") + "]";
+};
+`,
+	models.TypeGraph: `
+judgeParsers.graph = ([n, edges]) => {
+    if (!Number.isInteger(n) || n < 0) {
+        judgeFail("a node count");
+    }
+    const adjacency = Array.from({ length: n }, () => []);
+    for (const edge of edges) {
+        if (edge.length !== 2 || !edge.every((node) => node >= 0 && node < n)) {
+            judgeFail("edges [u,v] between nodes 0 to n-1");
+        }
+        adjacency[edge[0]].push(edge[1]);
+        adjacency[edge[1]].push(edge[0]);
+    }
+    return adjacency;
+};
+`,
+	models.TypeMatrix: `
+judgeParsers.matrix = (rows) => {
+    if (rows.some((row) => row.length !== rows[0].length)) {
+        judgeFail("rows of the same length");
+    }
+    return rows;
+};
+
+judgeFormatters.matrix = (rows) => judgeFormat(rows, "int[][]");
+`,
+}
+
+func jsType(t models.ValueType) string {
+	return jsTypes[t.Base] + strings.Repeat("[]", t.Depth)
+}
+
+func generateJS(sig signature) models.CodeTemplate {
+	var precode, stub, postcode strings.Builder
+	var definitions []string
+	postcode.WriteString(jsRuntime)
+	for _, structure := range usedStructures(sig) {
+		if definition, ok := jsDefinitions[structure]; ok {
+			if precode.Len() > 0 {
+				precode.WriteString("\n")
+			}
+			precode.WriteString(definition)
+			definitions = append(definitions, definition)
+		}
+		postcode.WriteString(jsStructureRuntime[structure])
+	}
+	stub.WriteString(definitionComment(definitions))
+	stub.WriteString("/**\n")
+	for _, p := range sig.params {
+		fmt.Fprintf(&stub, " * @param {%s} %s\n", jsType(p.typ), p.name)
+	}
+	args := joinParams(sig.params, func(p param) string { return p.name })
+	fmt.Fprintf(&stub, " * @return {%s}\n */\nvar %s = function(%s) {\n\n};\n", jsType(sig.result), sig.name, args)
+
+	postcode.WriteString("\n(function judgeMain() {\n    const values = judgeReadValues(require(\"fs\").readFileSync(0, \"utf8\"));\n")
+	for i, p := range sig.params {
+		if p.typ.IsStructure() {
+			fmt.Fprintf(&postcode, "    const %s = judgeParse(values[%d], %q);\n", p.name, i, p.typ.String())
+		} else {
+			fmt.Fprintf(&postcode, "    const %s = values[%d];\n", p.name, i)
+		}
+	}
+	fmt.Fprintf(&postcode, "    const result = %s(%s);\n    console.log(judgeFormat(result, %q));\n})();\n", sig.name, args, sig.result.String())
+	return models.CodeTemplate{Precode: precode.String(), Template: stub.String(), Postcode: postcode.String()}
+}
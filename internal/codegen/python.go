@@ -0,0 +1,260 @@
+package codegen
+
+import (
+	"code-compiler/internal/models"
+	"fmt"
+	"strings"
+)
+
+var pythonTypes = map[string]string{
+	models.TypeInt:      "int",
+	models.TypeLong:     "int",
+	models.TypeDouble:   "float",
+	models.TypeBool:     "bool",
+	models.TypeString:   "str",
+	models.TypeList:     "Optional[ListNode]",
+	models.TypeTree:     "Optional[TreeNode]",
+	models.TypeNaryTree: "'Node'",
+	models.TypeGraph:    "List[List[int]]",
+	models.TypeMatrix:   "List[List[int]]",
+}
+
+const pythonPrecode = `import json
+import sys
+from typing import List, Optional
+`
+
+const pythonRuntime = `
+
+def judge_read_values(text):
+    decoder = json.JSONDecoder()
+    position = 0
+    while True:
+        while position < len(text) and text[position] in " \t\r\n,":
+            position += 1
+        if position == len(text):
+            return
+        value, position = decoder.raw_decode(text, position)
+        yield value
+
+
+def judge_fail(expected):
+    sys.stderr.write("invalid input: expected %s\n" % expected)
+    sys.exit(1)
+
+
+judge_parsers = {}
+judge_formatters = {}
+
+
+def judge_parse(value, value_type):
+    if value_type.endswith("[]"):
+        return [judge_parse(element, value_type[:-2]) for element in value]
+    if value_type in judge_parsers:
+        return judge_parsers[value_type](value)
+    return value
+
+
+def judge_format(value, value_type):
+    if value_type in judge_formatters:
+        return judge_formatters[value_type](value)
+    if value is None:
+        return "null"
+    if value_type.endswith("[]"):
+        return "[" + ",".join(judge_format(element, value_type[:-2]) for element in value) + "]"
+    if value_type == "double":
+        return "%.5f" % value
+    if value_type == "bool":
+        return "true" if value else "false"
+    if value_type == "string":
+        return json.dumps(value, ensure_ascii=False)
+    return str(int(value))
+`
+
+// pythonDefinitions declare the node types, users see them as a comment in the stub
+var pythonDefinitions = map[string]string{
+	models.TypeList: `# Definition for singly-linked list.
+class ListNode:
+    def __init__(self, val=0, next=None):
+        self.val = val
+        self.next = next
+`,
+	models.TypeTree: `# Definition for a binary tree node.
+class TreeNode:
+    def __init__(self, val=0, left=None, right=None):
+        self.val = val
+        self.left = left
+        self.right = right
+`,
+	models.TypeNaryTree: `# Definition for a Node.
+class Node:
+    def __init__(self, val=None, children=None):
+        self.val = val
+        self.children = children
+`,
+}
+
+// pythonStructureRuntime converts the node types and grids from and to their JSON form
+var pythonStructureRuntime = map[string]string{
+	models.TypeList: `
+
+def judge_parse_list(values):
+    head = tail = ListNode()
+    for value in values:
+        tail.next = ListNode(value)
+        tail = tail.next
+    return head.next
+
+
+def judge_format_list(head):
+    values = []
+    while head is not None:
+        values.append(str(head.val))
+        head = head.next
+    return "[" + ",".join(values) + "]"
+
+
+judge_parsers["list"] = judge_parse_list
+judge_formatters["list"] = judge_format_list
+`,
+	models.TypeTree: `
+
+def judge_parse_tree(values):
+    if not values or values[0] is None:
+        return None
+    root = TreeNode(values[0])
+    queue = [root]
+    children = iter(values[1:])
+    for node in queue:
+        for side in ("left", "right"):
+            value = next(children, None)
+            if value is not None:
+                setattr(node, side, TreeNode(value))
+                queue.append(getattr(node, side))
+    return root
+
+
+def judge_format_tree(root):
+    queue = [root]
+    for node in queue:
+        if node is not None:
+            queue += [node.left, node.right]
+    while queue and queue[-1] is None:
+        queue.pop()
+    return "[" + ",".join("null" if node is None else str(node.val) for node in queue) + "]"
+
+
+judge_parsers["tree"] = judge_parse_tree
+judge_formatters["tree"] = judge_format_tree
+`,
+	models.TypeNaryTree: `
+
+def judge_parse_narytree(values):
+    if not values or values[0] is None:
+        return None
+    root = Node(values[0], [])
+    queue = [root]
+    position = 2
+    # The children of each node follow in order, each group ended by null
+    for node in queue:
+        while position < len(values) and values[position] is not None:
+            child = Node(values[position], [])
+            node.children.append(child)
+            queue.append(child)
+            position += 1
+        position += 1
+    return root
+
+
+def judge_format_narytree(root):
+    if root is None:
+        return "[]"
+    tokens = [str(root.val), "null"]
+    queue = [root]
+    for node in queue:
+        for child in node.children or []:
+            queue.append(child)
+            tokens.append(str(child.val))
+        tokens.append("null")
+    while tokens[-1] == "null":
+        tokens.pop()
+    return "[" + ",".join(tokens) + "]"
+
+
+judge_parsers["narytree"] = judge_parse_narytree
+judge_formatters["narytree"] = judge_format_narytree
+`,
+	models.TypeGraph: `
+
+def judge_parse_graph(value):
+    n, edges = value
+    if not isinstance(n, int) or isinstance(n, bool) or n < 0:
+        judge_fail("a node count")
+    adjacency = [[] for _ in range(n)]
+    for edge in edges:
+        if len(edge) != 2 or not all(0 <= node < n for node in edge):
+            judge_fail("edges [u,v] between nodes 0 to n-1")
+        adjacency[edge[0]].append(edge[1])
+        adjacency[edge[1]].append(edge[0])
+    return adjacency
+
+
+judge_parsers["graph"] = judge_parse_graph
+`,
+	models.TypeMatrix: `
+
+def judge_parse_matrix(rows):
+    if any(len(row) != len(rows[0]) for row in rows):
+        judge_fail("rows of the same length")
+    return rows
+
+
+def judge_format_matrix(rows):
+    return judge_format(rows, "int[][]")
+
+
+judge_parsers["matrix"] = judge_parse_matrix
+judge_formatters["matrix"] = judge_format_matrix
+`,
+}
+
+func pythonType(t models.ValueType) string {
+	if t.Depth == 0 {
+		return pythonTypes[t.Base]
+	}
+	return "List[" + pythonType(t.Elem()) + "]"
+}
+
+func generatePython(sig signature) models.CodeTemplate {
+	params := joinParams(sig.params, func(p param) string { return p.name + ": " + pythonType(p.typ) })
+	var precode, stub, postcode strings.Builder
+	precode.WriteString(pythonPrecode)
+	postcode.WriteString(pythonRuntime)
+	for _, structure := range usedStructures(sig) {
+		if definition, ok := pythonDefinitions[structure]; ok {
+			precode.WriteString("\n\n" + definition)
+			// Shown as comments, the classes are already defined by the precode
+			for _, line := range strings.Split(strings.TrimSuffix(definition, "\n"), "\n") {
+				if !strings.HasPrefix(line, "#") {
+					line = "# " + line
+				}
+				stub.WriteString(line + "\n")
+			}
+			stub.WriteString("\n")
+		}
+		postcode.WriteString(pythonStructureRuntime[structure])
+	}
+	fmt.Fprintf(&stub, "class Solution:\n    def %s(self, %s) -> %s:\n        pass\n", sig.name, params, pythonType(sig.result))
+
+	postcode.WriteString("\n\ndef judge_main():\n    values = judge_read_values(sys.stdin.read())\n")
+	for _, p := range sig.params {
+		if p.typ.IsStructure() {
+			fmt.Fprintf(&postcode, "    %s = judge_parse(next(values), %q)\n", p.name, p.typ.String())
+		} else {
+			fmt.Fprintf(&postcode, "    %s = next(values)\n", p.name)
+		}
+	}
+	args := joinParams(sig.params, func(p param) string { return p.name })
+	fmt.Fprintf(&postcode, "    result = Solution().%s(%s)\n    print(judge_format(result, %q))\n\n\njudge_main()\n", sig.name, args, sig.result.String())
+	return models.CodeTemplate{Precode: precode.String(), Template: stub.String(), Postcode: postcode.String()}
+}
@@ -2,10 +2,11 @@ package commontypes
 
 // CodeRunnerType represents the structure of code runner input.
 type CodeRunnerType struct {
-	UserId	   string `json:"userId"`
+	UserId     string `json:"userId"`
 	Language   string `json:"language"`
 	Code       string `json:"code"`
 	QuestionId string `json:"questionId"`
+	IsAdmin    bool   `json:"-"` // Admins can run questions that are not published yet
 }
 
 // TestResult represents the result of executing a test case.
@@ -17,6 +18,35 @@ type TestResult struct {
 	Passed         bool    `json:"passed"`
 	TimeTaken      float64 `json:"timeTaken"`
 	MemoryUsed     float64 `json:"memoryUsed"`
+	Visibility     string  `json:"visibility,omitempty"`
+	Redacted       bool    `json:"redacted,omitempty"`
+}
+
+// Redact returns a copy of the result without the test data when the test
+// case is hidden. Results stored before visibility existed count as hidden.
+func (t *TestResult) Redact() *TestResult {
+	if t == nil {
+		return nil
+	}
+	redacted := *t
+	if t.Visibility == "public" || t.Visibility == "show_on_fail" {
+		return &redacted
+	}
+	redacted.Input = ""
+	redacted.ExpectedOutput = ""
+	redacted.ActualOutput = ""
+	redacted.Redacted = true
+	return &redacted
+}
+
+// GroupResult represents the score earned on one test group.
+type GroupResult struct {
+	Name     string  `json:"name" bson:"name"`
+	Score    float64 `json:"score" bson:"score"`
+	MaxScore float64 `json:"maxScore" bson:"maxScore"`
+	Passed   int     `json:"passed" bson:"passed"`
+	Total    int     `json:"total" bson:"total"`
+	Skipped  bool    `json:"skipped,omitempty" bson:"skipped,omitempty"`
 }
 
 // InputOutput represents the input and output for test cases.
@@ -0,0 +1,40 @@
+// Package markdown renders question statements written in Markdown with
+// LaTeX math to sanitized HTML, so every client shows the same statement.
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+var converter = goldmark.New(
+	goldmark.WithExtensions(extension.Table, extension.Strikethrough, extension.Linkify, mathExtension{}),
+)
+
+// policy allows user generated content plus the classes of math and of
+// highlighted code blocks. Raw HTML in the source is already dropped by the
+// converter, the policy guards the rendered output.
+var policy = func() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^math (inline|display)$`)).OnElements("span", "div")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}()
+
+// Render converts Markdown to sanitized HTML
+func Render(source string) (string, error) {
+	if source == "" {
+		return "", nil
+	}
+	var out bytes.Buffer
+	if err := converter.Convert([]byte(source), &out); err != nil {
+		return "", err
+	}
+	return policy.Sanitize(out.String()), nil
+}
@@ -0,0 +1,96 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"empty", "", ""},
+		{"script block", "<script>alert(1)</script>", "\n"},
+		{"inline script", "Hi <script>alert(1)</script> there", "<p>Hi alert(1) there</p>\n"},
+		{"javascript link", "[click](javascript:alert(1))", "<p>click</p>\n"},
+		{"mixed case javascript link", "[click](JaVaScRiPt:alert(1))", "<p>click</p>\n"},
+		{"raw javascript anchor", `<a href="javascript:alert(1)">x</a>`, "<p>x</p>\n"},
+		{"raw image with handler", "<img src=x onerror=alert(1)>", "\n"},
+		{"raw block html", `<div onclick="x()">raw</div>`, "\n"},
+		{"raw math class", `<span class="math inline">x</span>`, "<p>x</p>\n"},
+		{
+			"external link",
+			"[site](https://example.com)",
+			`<p><a href="https://example.com" rel="nofollow noopener" target="_blank">site</a></p>` + "\n",
+		},
+		{
+			"code block",
+			"```go\nfmt.Println(\"<b>\")\n```",
+			`<pre><code class="language-go">fmt.Println(&#34;&lt;b&gt;&#34;)` + "\n</code></pre>\n",
+		},
+		{"prices", "It costs $5 and $10.", "<p>It costs $5 and $10.</p>\n"},
+		{"digit after closing dollar", "$x$5", "<p>$x$5</p>\n"},
+		{"space inside dollars", "Let $ x $ be.", "<p>Let $ x $ be.</p>\n"},
+		{"inline math", "Let $x^2$ be.", `<p>Let <span class="math inline">\(x^2\)</span> be.</p>` + "\n"},
+		{
+			"html in inline math",
+			`$a<b$ and $\alpha$`,
+			`<p><span class="math inline">\(a&lt;b\)</span> and <span class="math inline">\(\alpha\)</span></p>` + "\n",
+		},
+		{
+			"script in inline math",
+			"$</span><script>alert(1)</script>$",
+			`<p><span class="math inline">\(&lt;/span&gt;&lt;script&gt;alert(1)&lt;/script&gt;\)</span></p>` + "\n",
+		},
+		{
+			"escaped dollars",
+			`Escaped \$5 and $a \$ b$`,
+			`<p>Escaped $5 and <span class="math inline">\(a \$ b\)</span></p>` + "\n",
+		},
+		{"display math on one line", `$$\sum_{i=1}^n i$$`, `<div class="math display">\[\sum_{i=1}^n i\]</div>` + "\n"},
+		{"display math with spaces", "$$ x + y $$", `<div class="math display">\[ x + y \]</div>` + "\n"},
+		{
+			"display math block",
+			"$$\n\\frac{a<b}{c}\n$$",
+			`<div class="math display">\[\frac{a&lt;b}{c}` + "\n" + `\]</div>` + "\n",
+		},
+		{"unclosed display math", "$$ unclosed", "<p>$$ unclosed</p>\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Render(test.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("Render(%q)\n got %q\nwant %q", test.source, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRenderNeverKeepsScripts(t *testing.T) {
+	sources := []string{
+		"<script>alert(1)</script>",
+		"<iframe src=\"https://example.com\"></iframe>",
+		"<svg onload=alert(1)>",
+		"[x](javascript:alert(1))",
+		"[x](data:text/html;base64,PHNjcmlwdD4=)",
+		"![x](javascript:alert(1))",
+		"$$\n<script>alert(1)</script>\n$$",
+		"<style>body{display:none}</style>",
+	}
+	for _, source := range sources {
+		got, err := Render(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, unsafe := range []string{"<script", "<iframe", "<svg", "<style", "javascript:", "data:", "onload"} {
+			if strings.Contains(strings.ToLower(got), unsafe) {
+				t.Errorf("Render(%q) = %q keeps %q", source, got, unsafe)
+			}
+		}
+	}
+}
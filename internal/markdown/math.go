@@ -0,0 +1,173 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// LaTeX is written as $inline$, $$display$$ or a block fenced by $$ lines. It
+// is kept as escaped TeX inside math elements for KaTeX or MathJax on the
+// client, so the markdown syntax never mangles it.
+
+var (
+	kindMathInline = ast.NewNodeKind("MathInline")
+	kindMathBlock  = ast.NewNodeKind("MathBlock")
+)
+
+type mathInline struct {
+	ast.BaseInline
+	tex     []byte
+	display bool
+}
+
+func (n *mathInline) Kind() ast.NodeKind { return kindMathInline }
+
+func (n *mathInline) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"TeX": string(n.tex)}, nil)
+}
+
+type mathBlock struct {
+	ast.BaseBlock
+	closed bool
+}
+
+func (n *mathBlock) Kind() ast.NodeKind { return kindMathBlock }
+
+func (n *mathBlock) IsRaw() bool { return true }
+
+func (n *mathBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+type mathInlineParser struct{}
+
+func (p mathInlineParser) Trigger() []byte { return []byte{'$'} }
+
+func (p mathInlineParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	if bytes.HasPrefix(line, []byte("$$")) {
+		end := bytes.Index(line[2:], []byte("$$"))
+		if end <= 0 {
+			return nil
+		}
+		block.Advance(end + 4)
+		return &mathInline{tex: append([]byte(nil), line[2:end+2]...), display: true}
+	}
+	// Like pandoc, "$5 and $10" is no math: no space inside the dollars and no digit after them
+	if len(line) < 3 || util.IsSpace(line[1]) {
+		return nil
+	}
+	for i := 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '$':
+			if util.IsSpace(line[i-1]) || i+1 < len(line) && line[i+1] >= '0' && line[i+1] <= '9' {
+				return nil
+			}
+			block.Advance(i + 1)
+			return &mathInline{tex: append([]byte(nil), line[1:i]...)}
+		}
+	}
+	return nil
+}
+
+type mathBlockParser struct{}
+
+func (p mathBlockParser) Trigger() []byte { return []byte{'$'} }
+
+func (p mathBlockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 || !bytes.HasPrefix(line[pos:], []byte("$$")) {
+		return nil, parser.NoChildren
+	}
+	rest := util.TrimRightSpace(line[pos+2:])
+	node := &mathBlock{}
+	if len(rest) > 0 {
+		// $$ x $$ on a single line, anything else is left to the paragraph
+		if !bytes.HasSuffix(rest, []byte("$$")) || len(rest) == 2 {
+			return nil, parser.NoChildren
+		}
+		start := segment.Start + pos + 2
+		node.Lines().Append(text.NewSegment(start, start+len(rest)-2))
+		node.closed = true
+	}
+	reader.Advance(segment.Len() - 1)
+	return node, parser.NoChildren
+}
+
+func (p mathBlockParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+	if node.(*mathBlock).closed {
+		return parser.Close
+	}
+	line, segment := reader.PeekLine()
+	if bytes.Equal(util.TrimRightSpace(util.TrimLeftSpace(line)), []byte("$$")) {
+		reader.Advance(segment.Len())
+		return parser.Close
+	}
+	node.Lines().Append(segment)
+	reader.Advance(segment.Len() - 1)
+	return parser.Continue | parser.NoChildren
+}
+
+func (p mathBlockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+func (p mathBlockParser) CanInterruptParagraph() bool { return true }
+
+func (p mathBlockParser) CanAcceptIndentedLine() bool { return false }
+
+type mathRenderer struct{}
+
+func (r mathRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(kindMathInline, r.renderInline)
+	reg.Register(kindMathBlock, r.renderBlock)
+}
+
+func (r mathRenderer) renderInline(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*mathInline)
+	if n.display {
+		_, _ = w.WriteString(`<span class="math display">\[`)
+		_, _ = w.Write(util.EscapeHTML(n.tex))
+		_, _ = w.WriteString(`\]</span>`)
+	} else {
+		_, _ = w.WriteString(`<span class="math inline">\(`)
+		_, _ = w.Write(util.EscapeHTML(n.tex))
+		_, _ = w.WriteString(`\)</span>`)
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+func (r mathRenderer) renderBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	_, _ = w.WriteString(`<div class="math display">\[`)
+	lines := node.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		_, _ = w.Write(util.EscapeHTML(segment.Value(source)))
+	}
+	_, _ = w.WriteString("\\]</div>\n")
+	return ast.WalkSkipChildren, nil
+}
+
+// mathExtension adds the math syntax to a goldmark instance
+type mathExtension struct{}
+
+func (e mathExtension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(util.Prioritized(mathBlockParser{}, 150)),
+		parser.WithInlineParsers(util.Prioritized(mathInlineParser{}, 150)),
+	)
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(mathRenderer{}, 150)))
+}
@@ -17,6 +17,9 @@ type contextKey string
 
 const UserIDKey contextKey = "userID"
 
+// UserRoleKey holds the role claim of an authenticated request
+const UserRoleKey contextKey = "userRole"
+
 // JSONResponse writes a JSON response
 func JSONResponse(w http.ResponseWriter, statusCode int, message string, success bool) {
 	w.Header().Set("Content-Type", "application/json")
@@ -27,6 +30,12 @@ func JSONResponse(w http.ResponseWriter, statusCode int, message string, success
 	})
 }
 
+// IsAdminRequest reports whether the request carries a valid admin token
+func IsAdminRequest(r *http.Request) bool {
+	role, _ := r.Context().Value(UserRoleKey).(string)
+	return role == "admin"
+}
+
 // JWT Middleware to validate user tokens
 func IsValidUser(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -56,6 +65,8 @@ func IsValidUser(next http.Handler) http.Handler {
 			}
 			// Set userId in the context using the correct key
 			ctx := context.WithValue(r.Context(), UserIDKey, userId)
+			role, _ := claims["role"].(string)
+			ctx = context.WithValue(ctx, UserRoleKey, role)
 			r = r.WithContext(ctx)
 			next.ServeHTTP(w, r)
 		} else {
@@ -106,6 +117,8 @@ func IsValidUserWithoutReturn(next http.Handler) http.Handler {
 			}
 			// Set userId in the context
 			ctx := context.WithValue(r.Context(), UserIDKey, userId)
+			role, _ := claims["role"].(string)
+			ctx = context.WithValue(ctx, UserRoleKey, role)
 			r = r.WithContext(ctx)
 			next.ServeHTTP(w, r)
 			return
@@ -147,6 +160,8 @@ func IsValidAdmin(next http.Handler) http.Handler {
 			}
 			// Set userId in the context using the correct key
 			ctx := context.WithValue(r.Context(), UserIDKey, userId)
+			role, _ := claims["role"].(string)
+			ctx = context.WithValue(ctx, UserRoleKey, role)
 			r = r.WithContext(ctx)
 			next.ServeHTTP(w, r)
 		} else {
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Kinds of attachment, images are shown inline in statements and files are downloaded
+const (
+	AttachmentImage = "image"
+	AttachmentFile  = "file"
+)
+
+// Size limits of an attachment in bytes
+const (
+	MaxImageSize = 2 << 20
+	MaxFileSize  = 20 << 20
+)
+
+// attachmentTypes maps the sniffed content types that can be uploaded to
+// their kind. SVG is left out, it can carry scripts.
+var attachmentTypes = map[string]string{
+	"image/png":                 AttachmentImage,
+	"image/jpeg":                AttachmentImage,
+	"image/gif":                 AttachmentImage,
+	"image/webp":                AttachmentImage,
+	"application/pdf":           AttachmentFile,
+	"application/zip":           AttachmentFile,
+	"application/x-gzip":        AttachmentFile,
+	"text/plain; charset=utf-8": AttachmentFile,
+}
+
+var unsafeNameCharacters = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+var attachmentLink = regexp.MustCompile(`/attachment\?id=([0-9A-Za-z]+)`)
+
+// Attachment is an image or file of a question, stored in the blob store
+type Attachment struct {
+	ID          string    `json:"_id" bson:"_id"`
+	QuestionID  string    `json:"questionId" bson:"questionId"`
+	Name        string    `json:"name" bson:"name"`
+	Kind        string    `json:"kind" bson:"kind"`
+	ContentType string    `json:"contentType" bson:"contentType"`
+	Size        int64     `json:"size" bson:"size"`
+	Blob        string    `json:"blob" bson:"blob"` // SHA-256 of the content in the blob store
+	UploadedBy  string    `json:"uploadedBy,omitempty" bson:"uploadedBy"`
+	CreatedAt   time.Time `json:"createdAt" bson:"createdAt"`
+	URL         string    `json:"url" bson:"-"` // Stable link to use in statements
+}
+
+// AttachmentKind checks the sniffed content type and size of an upload and
+// returns its kind
+func AttachmentKind(contentType string, size int64) (string, error) {
+	kind, ok := attachmentTypes[contentType]
+	if !ok {
+		return "", fmt.Errorf("content type %s cannot be attached, use PNG, JPEG, GIF, WebP, PDF, ZIP, gzip or plain text", contentType)
+	}
+	limit := int64(MaxFileSize)
+	if kind == AttachmentImage {
+		limit = MaxImageSize
+	}
+	if size > limit {
+		return "", fmt.Errorf("the %s is %d bytes, the limit is %d", kind, size, limit)
+	}
+	return kind, nil
+}
+
+// SanitizeAttachmentName keeps the base name of an upload with only safe characters
+func SanitizeAttachmentName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Trim(unsafeNameCharacters.ReplaceAllString(name, "-"), "-.")
+	if len(name) > 100 {
+		name = name[len(name)-100:]
+	}
+	if name == "" {
+		return "attachment"
+	}
+	return name
+}
+
+// AttachmentURL is the link statements use for an attachment
+func AttachmentURL(id string) string {
+	return "/attachment?id=" + id
+}
+
+// RelinkAttachments points the attachment links of a statement to the new
+// ids, links to other attachments are left as they are
+func RelinkAttachments(text string, ids map[string]string) string {
+	if len(ids) == 0 {
+		return text
+	}
+	return attachmentLink.ReplaceAllStringFunc(text, func(link string) string {
+		if id, ok := ids[attachmentLink.FindStringSubmatch(link)[1]]; ok {
+			return AttachmentURL(id)
+		}
+		return link
+	})
+}
@@ -0,0 +1,61 @@
+package models
+
+import "testing"
+
+func TestRelinkAttachments(t *testing.T) {
+	ids := map[string]string{"1": "40", "12": "41"}
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"no links", "no links"},
+		{"![graph](/attachment?id=1)", "![graph](/attachment?id=40)"},
+		{"[a](/attachment?id=12) and [b](/attachment?id=1).", "[a](/attachment?id=41) and [b](/attachment?id=40)."},
+		{"[other](/attachment?id=123)", "[other](/attachment?id=123)"},
+		{"/attachment?id=", "/attachment?id="},
+	}
+	for _, test := range tests {
+		if got := RelinkAttachments(test.text, ids); got != test.want {
+			t.Errorf("RelinkAttachments(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestAttachmentKind(t *testing.T) {
+	tests := []struct {
+		contentType string
+		size        int64
+		want        string
+		wantErr     bool
+	}{
+		{"image/png", MaxImageSize, AttachmentImage, false},
+		{"image/png", MaxImageSize + 1, "", true},
+		{"application/pdf", MaxFileSize, AttachmentFile, false},
+		{"application/pdf", MaxFileSize + 1, "", true},
+		{"text/html; charset=utf-8", 10, "", true},
+	}
+	for _, test := range tests {
+		got, err := AttachmentKind(test.contentType, test.size)
+		if got != test.want || (err != nil) != test.wantErr {
+			t.Errorf("AttachmentKind(%q, %d) = %q, %v", test.contentType, test.size, got, err)
+		}
+	}
+}
+
+func TestSanitizeAttachmentName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"graph.png", "graph.png"},
+		{"../../etc/passwd", "passwd"},
+		{`C:\Users\me\tree diagram.png`, "tree-diagram.png"},
+		{"..", "attachment"},
+		{"", "attachment"},
+	}
+	for _, test := range tests {
+		if got := SanitizeAttachmentName(test.name); got != test.want {
+			t.Errorf("SanitizeAttachmentName(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
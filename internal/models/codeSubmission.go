@@ -2,18 +2,60 @@ package models
 
 import (
 	commontypes "code-compiler/internal/commonTypes"
+	"fmt"
+	"strings"
 	"time"
 )
 
+// Verdicts recorded on a judged submission
+const (
+	VerdictAccepted          = "accepted"
+	VerdictWrongAnswer       = "wrong_answer"
+	VerdictTimeLimitExceeded = "time_limit_exceeded"
+	VerdictRuntimeError      = "runtime_error"
+	VerdictCompilationError  = "compilation_error"
+)
+
 type CodeSubmission struct {
-	UserId          string                  `json:"userId,omitempty" bson:"userId"`
-	Question        string                  `json:"question,omitempty" bson:"question"`
-	FailedCase      *commontypes.TestResult `json:"failedCase,omitempty" bson:"failedCase"`
-	PassedTestCases int                     `json:"passedTestCases,omitempty" bson:"passedTestCases"`
-	TotalTestCases  int                     `json:"totalTestCases,omitempty" bson:"totalTestCases"`
-	Err             string                  `json:"err,omitempty" bson:"err"`
-	Code            string                  `json:"code,omitempty" bson:"code"`
-	Language        string                  `json:"language,omitempty" bson:"language"`
-	CreatedAt       time.Time               `json:"createdAt,omitempty" bson:"createdAt"`
-	UpdatedAt       time.Time               `json:"updatedAt,omitempty" bson:"updatedAt"`
+	ID               string                    `json:"_id,omitempty" bson:"_id,omitempty"`
+	UserId           string                    `json:"userId,omitempty" bson:"userId"`
+	Question         string                    `json:"question,omitempty" bson:"question"`
+	QuestionRevision int                       `json:"questionRevision,omitempty" bson:"questionRevision,omitempty"` // Question revision the submission was judged against
+	FailedCase       *commontypes.TestResult   `json:"failedCase,omitempty" bson:"failedCase"`
+	PassedTestCases  int                       `json:"passedTestCases,omitempty" bson:"passedTestCases"`
+	TotalTestCases   int                       `json:"totalTestCases,omitempty" bson:"totalTestCases"`
+	Score            float64                   `json:"score" bson:"score"`
+	MaxScore         float64                   `json:"maxScore" bson:"maxScore"`
+	GroupResults     []commontypes.GroupResult `json:"groupResults,omitempty" bson:"groupResults,omitempty"`
+	Runtime          float64                   `json:"runtime,omitempty" bson:"runtime,omitempty"` // Slowest test case, CPU time in ms
+	Memory           float64                   `json:"memory,omitempty" bson:"memory,omitempty"`   // Largest peak memory of a test case in kb
+	Performance      *PerformanceRanking       `json:"performance,omitempty" bson:"-"`             // Ranking among the accepted submissions when judged, only in the judge response
+	Verdict          string                    `json:"verdict,omitempty" bson:"verdict"`
+	VerdictHistory   []VerdictChange           `json:"verdictHistory,omitempty" bson:"verdictHistory,omitempty"`
+	Err              string                    `json:"err,omitempty" bson:"err"`
+	Code             string                    `json:"code,omitempty" bson:"code"`
+	Language         string                    `json:"language,omitempty" bson:"language"`
+	CreatedAt        time.Time                 `json:"createdAt,omitempty" bson:"createdAt"`
+	UpdatedAt        time.Time                 `json:"updatedAt,omitempty" bson:"updatedAt"`
+}
+
+// VerdictChange keeps the result a submission had before it was rejudged
+type VerdictChange struct {
+	Verdict         string    `json:"verdict" bson:"verdict"`
+	PassedTestCases int       `json:"passedTestCases" bson:"passedTestCases"`
+	TotalTestCases  int       `json:"totalTestCases" bson:"totalTestCases"`
+	Score           float64   `json:"score" bson:"score"`
+	RejudgeJobID    string    `json:"rejudgeJobId" bson:"rejudgeJobId"`
+	ChangedAt       time.Time `json:"changedAt" bson:"changedAt"`
+}
+
+// Redact returns a copy of the submission that is safe to show its author.
+// A hidden failed test keeps its number but loses its data, and program
+// output in the error message is replaced by the verdict.
+func (s CodeSubmission) Redact() CodeSubmission {
+	s.FailedCase = s.FailedCase.Redact()
+	if s.Err != "" && s.FailedCase != nil && s.FailedCase.Redacted {
+		s.Err = fmt.Sprintf("%s on hidden test case %d", strings.ReplaceAll(s.Verdict, "_", " "), s.FailedCase.TestCaseNumber)
+	}
+	return s
 }
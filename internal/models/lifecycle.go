@@ -0,0 +1,62 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Lifecycle states of a question. Questions without a state were created
+// before lifecycles existed and count as published.
+const (
+	LifecycleDraft     = "draft"
+	LifecycleInReview  = "in_review"
+	LifecycleScheduled = "scheduled"
+	LifecyclePublished = "published"
+	LifecycleArchived  = "archived"
+)
+
+// lifecycleTransitions lists the states each state can move to
+var lifecycleTransitions = map[string][]string{
+	LifecycleDraft:     {LifecycleInReview, LifecycleArchived},
+	LifecycleInReview:  {LifecycleDraft, LifecycleScheduled, LifecyclePublished, LifecycleArchived},
+	LifecycleScheduled: {LifecycleDraft, LifecycleInReview, LifecyclePublished, LifecycleArchived},
+	LifecyclePublished: {LifecycleDraft, LifecycleArchived},
+	LifecycleArchived:  {LifecycleDraft, LifecyclePublished},
+}
+
+// CurrentLifecycle returns the state of the question, published when it has none
+func (q Question) CurrentLifecycle() string {
+	if q.Lifecycle == "" {
+		return LifecyclePublished
+	}
+	return q.Lifecycle
+}
+
+// IsPublished reports whether users other than admins can see and run the question
+func (q Question) IsPublished() bool {
+	return q.CurrentLifecycle() == LifecyclePublished
+}
+
+// ValidateLifecycleTransition checks that the question may move to the state.
+// Scheduling needs a publish time in the future.
+func (q Question) ValidateLifecycleTransition(state string, publishAt *time.Time, now time.Time) error {
+	current := q.CurrentLifecycle()
+	if _, ok := lifecycleTransitions[state]; !ok {
+		return fmt.Errorf("lifecycle must be %s, %s, %s, %s or %s", LifecycleDraft, LifecycleInReview, LifecycleScheduled, LifecyclePublished, LifecycleArchived)
+	}
+	if state == LifecycleScheduled && (publishAt == nil || !publishAt.After(now)) {
+		return fmt.Errorf("a %s question needs a publishAt in the future", LifecycleScheduled)
+	}
+	if state == current && state != LifecycleScheduled {
+		return fmt.Errorf("the question is already %s", state)
+	}
+	if state == current {
+		return nil // rescheduling
+	}
+	for _, allowed := range lifecycleTransitions[current] {
+		if allowed == state {
+			return nil
+		}
+	}
+	return fmt.Errorf("a %s question cannot move to %s", current, state)
+}
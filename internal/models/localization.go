@@ -0,0 +1,139 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale is the statement language of questions that do not name one
+const DefaultLocale = "en"
+
+// Translation is a localized variant of the statement. SourceHash records the
+// source statement it was translated from, so edits to the source make it stale.
+type Translation struct {
+	Title           string    `json:"title" bson:"title"`
+	Description     string    `json:"description" bson:"description"`
+	Editorial       string    `json:"editorial,omitempty" bson:"editorial,omitempty"`
+	DescriptionHTML string    `json:"descriptionHtml,omitempty" bson:"descriptionHtml,omitempty"`
+	EditorialHTML   string    `json:"editorialHtml,omitempty" bson:"editorialHtml,omitempty"`
+	SourceHash      string    `json:"sourceHash,omitempty" bson:"sourceHash"`
+	UpdatedBy       string    `json:"updatedBy,omitempty" bson:"updatedBy,omitempty"`
+	UpdatedAt       time.Time `json:"updatedAt,omitempty" bson:"updatedAt"`
+}
+
+// LocaleReport lists the required locales a question lacks or has outdated
+type LocaleReport struct {
+	QuestionID   string   `json:"questionId"`
+	Slug         string   `json:"slug"`
+	Title        string   `json:"title"`
+	SourceLocale string   `json:"sourceLocale"`
+	Missing      []string `json:"missing,omitempty"`
+	Stale        []string `json:"stale,omitempty"`
+}
+
+// NormalizeLocale parses a BCP 47 locale and returns its canonical form, e.g. "hi-IN"
+func NormalizeLocale(locale string) (string, error) {
+	tag, err := language.Parse(strings.TrimSpace(locale))
+	if err != nil || tag == language.Und {
+		return "", fmt.Errorf("invalid locale %q", locale)
+	}
+	return tag.String(), nil
+}
+
+// Validate checks the translated statement is complete
+func (t Translation) Validate() error {
+	if strings.TrimSpace(t.Title) == "" || strings.TrimSpace(t.Description) == "" {
+		return errors.New("a translation needs a title and a description")
+	}
+	return nil
+}
+
+// SourceLocale is the language the title and description are written in
+func (q Question) SourceLocale() string {
+	if q.Locale == "" {
+		return DefaultLocale
+	}
+	return q.Locale
+}
+
+// StatementHash identifies the current source statement
+func (q Question) StatementHash() string {
+	sum := sha256.Sum256([]byte(q.Title + "\x00" + q.Description + "\x00" + q.Editorial))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Locales lists the source locale first, then the translated ones
+func (q Question) Locales() []string {
+	translated := make([]string, 0, len(q.Translations))
+	for locale := range q.Translations {
+		translated = append(translated, locale)
+	}
+	sort.Strings(translated)
+	return append([]string{q.SourceLocale()}, translated...)
+}
+
+// MatchLocale picks the available locale closest to the preferred ones,
+// falling back to the source locale
+func (q Question) MatchLocale(preferred []language.Tag) string {
+	locales := q.Locales()
+	if len(preferred) == 0 || len(locales) == 1 {
+		return locales[0]
+	}
+	tags := make([]language.Tag, 0, len(locales))
+	for _, locale := range locales {
+		tags = append(tags, language.Make(locale))
+	}
+	_, index, confidence := language.NewMatcher(tags).Match(preferred...)
+	if confidence == language.No {
+		return locales[0]
+	}
+	return locales[index]
+}
+
+// Localize returns a copy of the question with the statement in the locale,
+// the source statement is kept when there is no translation for it
+func (q Question) Localize(locale string) Question {
+	q.AvailableLocales = q.Locales()
+	translation, ok := q.Translations[locale]
+	if !ok || locale == q.SourceLocale() {
+		q.Locale = q.SourceLocale()
+		return q
+	}
+	q.Locale = locale
+	q.Title = translation.Title
+	if translation.Description != "" {
+		q.Description = translation.Description
+		q.DescriptionHTML = translation.DescriptionHTML
+	}
+	if translation.Editorial != "" {
+		q.Editorial = translation.Editorial
+		q.EditorialHTML = translation.EditorialHTML
+	}
+	return q
+}
+
+// LocaleReport compares the translations with the required locales
+func (q Question) LocaleReport(required []string) LocaleReport {
+	report := LocaleReport{QuestionID: q.ID, Slug: q.Slug, Title: q.Title, SourceLocale: q.SourceLocale()}
+	hash := q.StatementHash()
+	for _, locale := range required {
+		if locale == report.SourceLocale {
+			continue
+		}
+		translation, ok := q.Translations[locale]
+		switch {
+		case !ok:
+			report.Missing = append(report.Missing, locale)
+		case translation.SourceHash != hash:
+			report.Stale = append(report.Stale, locale)
+		}
+	}
+	return report
+}
@@ -1,8 +1,12 @@
 package models
 
+// InputOutput is a single test. Large inputs and outputs are kept in the
+// blob store and referenced by hash instead of being stored inline.
 type InputOutput struct {
-	Input  string `json:"input"`
-	Output string `json:"output"`
+	Input      string `json:"input"`
+	Output     string `json:"output"`
+	InputBlob  string `json:"inputBlob,omitempty" bson:"inputBlob,omitempty"`
+	OutputBlob string `json:"outputBlob,omitempty" bson:"outputBlob,omitempty"`
 }
 
 type Response struct {
@@ -0,0 +1,251 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Difficulty levels of a question
+const (
+	DifficultyEasy   = "easy"
+	DifficultyMedium = "medium"
+	DifficultyHard   = "hard"
+)
+
+// SupportedLanguages are the languages the judge can compile and run
+var SupportedLanguages = []string{"c", "cpp", "java", "go", "py", "js"}
+
+// IsSupportedLanguage reports whether code in the language can be judged
+func IsSupportedLanguage(language string) bool {
+	for _, supported := range SupportedLanguages {
+		if language == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateDifficulty accepts easy, medium or hard
+func ValidateDifficulty(difficulty string) error {
+	switch difficulty {
+	case DifficultyEasy, DifficultyMedium, DifficultyHard:
+		return nil
+	}
+	return fmt.Errorf("difficulty must be %s, %s or %s", DifficultyEasy, DifficultyMedium, DifficultyHard)
+}
+
+// ValidateCodeTemplates checks that every template is keyed by a supported language
+func ValidateCodeTemplates(templates map[string]CodeTemplate) error {
+	for language := range templates {
+		if !IsSupportedLanguage(language) {
+			return fmt.Errorf("code template for unsupported language %q, use one of %s", language, strings.Join(SupportedLanguages, ", "))
+		}
+	}
+	return nil
+}
+
+// NormalizeTags lowercases and trims the tags, collapses inner whitespace and
+// drops empty and repeated tags
+func NormalizeTags(tags []string) []string {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// QuestionPatch is a partial update of a question. Only the fields sent in the
+// request are changed; identity, statistics and user progress cannot be patched.
+// Who can see the question follows its lifecycle, which has its own endpoint.
+type QuestionPatch struct {
+	Title                 *string                  `json:"title"`
+	Slug                  *string                  `json:"slug"` // Custom slug, otherwise it follows the title
+	Description           *string                  `json:"description"`
+	Locale                *string                  `json:"locale"` // Language of the title and description
+	Difficulty            *string                  `json:"difficulty"`
+	Tags                  *[]string                `json:"tags"`
+	SampleTestCases       *[]InputOutput           `json:"sampleTestCases"`
+	TestCaseVariableNames *string                  `json:"testCaseVariableNames"`
+	CodeTemplates         *map[string]CodeTemplate `json:"codeTemplates"`
+	Signature             *FunctionSignature       `json:"signature"` // Regenerates the code templates
+	TestGroups            *[]TestGroup             `json:"testGroups"`
+	Solution              *string                  `json:"solution"`
+	SolutionLanguage      *string                  `json:"solutionLanguage"`
+	Editorial             *string                  `json:"editorial"`
+	SolutionPolicy        *string                  `json:"solutionPolicy"`
+	UnlockAt              *time.Time               `json:"unlockAt"`
+	TimeLimit             *float64                 `json:"timeLimit"`
+	MemoryLimit           *float64                 `json:"memoryLimit"`
+}
+
+// Validate checks the sent fields and normalizes the difficulty and tags
+func (p *QuestionPatch) Validate() error {
+	if p.Title != nil && strings.TrimSpace(*p.Title) == "" {
+		return errors.New("title cannot be empty")
+	}
+	if p.Description != nil && strings.TrimSpace(*p.Description) == "" {
+		return errors.New("description cannot be empty")
+	}
+	if p.Locale != nil {
+		locale, err := NormalizeLocale(*p.Locale)
+		if err != nil {
+			return err
+		}
+		p.Locale = &locale
+	}
+	if p.Difficulty != nil {
+		difficulty := strings.ToLower(strings.TrimSpace(*p.Difficulty))
+		if err := ValidateDifficulty(difficulty); err != nil {
+			return err
+		}
+		p.Difficulty = &difficulty
+	}
+	if p.Tags != nil {
+		tags := NormalizeTags(*p.Tags)
+		if len(tags) == 0 {
+			return errors.New("a question needs at least one tag")
+		}
+		p.Tags = &tags
+	}
+	if p.SampleTestCases != nil && len(*p.SampleTestCases) == 0 {
+		return errors.New("a question needs at least one sample test case")
+	}
+	if p.CodeTemplates != nil {
+		if len(*p.CodeTemplates) == 0 {
+			return errors.New("a question needs at least one code template")
+		}
+		if err := ValidateCodeTemplates(*p.CodeTemplates); err != nil {
+			return err
+		}
+	}
+	if p.Signature != nil {
+		if err := p.Signature.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.Solution != nil && strings.TrimSpace(*p.Solution) == "" {
+		return errors.New("solution cannot be empty")
+	}
+	if p.SolutionLanguage != nil && *p.SolutionLanguage != "" && !IsSupportedLanguage(*p.SolutionLanguage) {
+		return fmt.Errorf("unsupported solution language %q", *p.SolutionLanguage)
+	}
+	if p.SolutionPolicy != nil {
+		if err := ValidateUnlockPolicy(*p.SolutionPolicy, p.UnlockAt); err != nil {
+			return err
+		}
+	}
+	if p.TimeLimit != nil && *p.TimeLimit <= 0 {
+		return errors.New("timeLimit must be positive")
+	}
+	if p.MemoryLimit != nil && *p.MemoryLimit <= 0 {
+		return errors.New("memoryLimit must be positive")
+	}
+	return nil
+}
+
+// Changes maps the sent fields to their stored field names
+func (p *QuestionPatch) Changes() map[string]interface{} {
+	changes := map[string]interface{}{}
+	if p.Title != nil {
+		changes["title"] = *p.Title
+	}
+	if p.Slug != nil {
+		changes["slug"] = *p.Slug
+	}
+	if p.Description != nil {
+		changes["description"] = *p.Description
+	}
+	if p.Locale != nil {
+		changes["locale"] = *p.Locale
+	}
+	if p.Difficulty != nil {
+		changes["difficulty"] = *p.Difficulty
+	}
+	if p.Tags != nil {
+		changes["tags"] = *p.Tags
+	}
+	if p.SampleTestCases != nil {
+		changes["sampleTestCases"] = *p.SampleTestCases
+	}
+	if p.TestCaseVariableNames != nil {
+		changes["testcasevariablenames"] = *p.TestCaseVariableNames
+	}
+	if p.CodeTemplates != nil {
+		changes["codeTemplates"] = *p.CodeTemplates
+	}
+	if p.Signature != nil {
+		changes["signature"] = *p.Signature
+	}
+	if p.TestGroups != nil {
+		changes["testGroups"] = *p.TestGroups
+	}
+	if p.Solution != nil {
+		changes["solution"] = *p.Solution
+	}
+	if p.SolutionLanguage != nil {
+		changes["solutionLanguage"] = *p.SolutionLanguage
+	}
+	if p.Editorial != nil {
+		changes["editorial"] = *p.Editorial
+	}
+	if p.SolutionPolicy != nil {
+		changes["solutionPolicy"] = *p.SolutionPolicy
+	}
+	if p.UnlockAt != nil {
+		changes["unlockAt"] = *p.UnlockAt
+	}
+	if p.TimeLimit != nil {
+		changes["timeLimit"] = *p.TimeLimit
+	}
+	if p.MemoryLimit != nil {
+		changes["memoryLimit"] = *p.MemoryLimit
+	}
+	return changes
+}
+
+// TestCasePatch is a partial update of a test case batch. Approval is left
+// out, it only changes through a review.
+type TestCasePatch struct {
+	IOPairs    *[]InputOutput `json:"ioPairs"`
+	Group      *string        `json:"group"`
+	Visibility *string        `json:"visibility"`
+}
+
+// Validate checks the sent fields
+func (p *TestCasePatch) Validate() error {
+	if p.IOPairs != nil && len(*p.IOPairs) == 0 {
+		return errors.New("a test case batch needs at least one input and output pair")
+	}
+	if p.Group != nil {
+		if err := ValidateGroupName(*p.Group); err != nil {
+			return err
+		}
+	}
+	if p.Visibility != nil {
+		return ValidateVisibility(*p.Visibility)
+	}
+	return nil
+}
+
+// Changes maps the sent fields to their stored field names
+func (p *TestCasePatch) Changes() map[string]interface{} {
+	changes := map[string]interface{}{}
+	if p.IOPairs != nil {
+		changes["ioPairs"] = *p.IOPairs
+	}
+	if p.Group != nil {
+		changes["group"] = *p.Group
+	}
+	if p.Visibility != nil {
+		changes["visibility"] = *p.Visibility
+	}
+	return changes
+}
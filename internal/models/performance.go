@@ -0,0 +1,129 @@
+package models
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	// bucketGrowth is the width ratio of neighbouring histogram buckets, so a
+	// rank is exact to about 5% of the measured value
+	bucketGrowth = 1.05
+	// HistogramBins is the number of bins shown to the user
+	HistogramBins = 20
+)
+
+// Distribution is a log-scale histogram of one metric. Buckets are keyed by
+// their index, as Mongo field names, so they can be incremented in place.
+type Distribution struct {
+	Buckets map[string]int64 `json:"buckets" bson:"buckets"`
+	Count   int64            `json:"count" bson:"count"`
+}
+
+// PerformanceDistribution holds the runtime and memory of the accepted
+// submissions of one question in one language
+type PerformanceDistribution struct {
+	ID         string       `json:"_id" bson:"_id"` // questionId:language
+	QuestionID string       `json:"questionId" bson:"questionId"`
+	Language   string       `json:"language" bson:"language"`
+	Runtime    Distribution `json:"runtime" bson:"runtime"` // CPU time in ms
+	Memory     Distribution `json:"memory" bson:"memory"`   // Peak resident memory in kb
+	UpdatedAt  time.Time    `json:"updatedAt" bson:"updatedAt"`
+}
+
+// HistogramBin is a range of values with the number of submissions in it
+type HistogramBin struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int64   `json:"count"`
+	Yours bool    `json:"yours,omitempty"`
+}
+
+// MetricRanking places a value among the other accepted submissions. Beats is
+// the percentage of them using more, ties count half.
+type MetricRanking struct {
+	Value     float64        `json:"value"`
+	Beats     float64        `json:"beats"`
+	Histogram []HistogramBin `json:"histogram"`
+}
+
+// PerformanceRanking is shown with an accepted submission
+type PerformanceRanking struct {
+	Language    string         `json:"language"`
+	Submissions int64          `json:"submissions"`
+	Runtime     *MetricRanking `json:"runtime,omitempty"`
+	Memory      *MetricRanking `json:"memory,omitempty"`
+}
+
+// PerformanceID is the ID of the distribution of a question in a language
+func PerformanceID(questionID string, language string) string {
+	return questionID + ":" + language
+}
+
+// BucketOf returns the histogram bucket of a value
+func BucketOf(value float64) int {
+	if value <= 0 {
+		return 0
+	}
+	return int(math.Log1p(value) / math.Log(bucketGrowth))
+}
+
+// BucketKey is the field name of the bucket of a value
+func BucketKey(value float64) string {
+	return strconv.Itoa(BucketOf(value))
+}
+
+// bucketStart is the smallest value falling in the bucket
+func bucketStart(bucket int) float64 {
+	return math.Expm1(float64(bucket) * math.Log(bucketGrowth))
+}
+
+// Add counts a value, for distributions built in memory
+func (d *Distribution) Add(value float64) {
+	if d.Buckets == nil {
+		d.Buckets = map[string]int64{}
+	}
+	d.Buckets[BucketKey(value)]++
+	d.Count++
+}
+
+// Rank places a value already counted in the distribution among the others
+func (d Distribution) Rank(value float64) *MetricRanking {
+	own := BucketOf(value)
+	counts := map[int]int64{}
+	low, high := own, own
+	var worse, same int64
+	for key, count := range d.Buckets {
+		bucket, err := strconv.Atoi(key)
+		if err != nil || count <= 0 {
+			continue
+		}
+		counts[bucket] += count
+		low, high = min(low, bucket), max(high, bucket)
+		switch {
+		case bucket > own:
+			worse += count
+		case bucket == own:
+			same += count
+		}
+	}
+	ranking := &MetricRanking{Value: value, Beats: 100}
+	if others := d.Count - 1; others > 0 {
+		// The submission itself is one of the same bucket
+		ties := float64(max(same-1, 0))
+		ranking.Beats = math.Round((float64(worse)+ties/2)/float64(others)*10000) / 100
+	}
+
+	// Group the buckets into bins of equal width on the log scale
+	width := (high - low + HistogramBins) / HistogramBins
+	for start := low; start <= high; start += width {
+		bin := HistogramBin{From: bucketStart(start), To: bucketStart(start + width)}
+		for bucket := start; bucket < start+width; bucket++ {
+			bin.Count += counts[bucket]
+		}
+		bin.Yours = own >= start && own < start+width
+		ranking.Histogram = append(ranking.Histogram, bin)
+	}
+	return ranking
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBucketOf(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  int
+	}{
+		{-5, 0},
+		{0, 0},
+		{1, 14},
+		{10, 49},
+		{20, 62},
+		{100, 94},
+	}
+	for _, test := range tests {
+		if got := BucketOf(test.value); got != test.want {
+			t.Errorf("BucketOf(%v) = %d, want %d", test.value, got, test.want)
+		}
+	}
+	// Every value falls between the start of its bucket and the next one
+	for _, value := range []float64{0.5, 1, 3, 17, 250, 4096, 1e6} {
+		bucket := BucketOf(value)
+		if start, next := bucketStart(bucket), bucketStart(bucket+1); value < start || value >= next {
+			t.Errorf("%v is outside its bucket %d [%v, %v)", value, bucket, start, next)
+		}
+	}
+}
+
+func TestDistributionRank(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		value  float64
+		beats  float64
+		bins   int
+		yours  int // index of the bin holding value
+	}{
+		{"single submission", []float64{50}, 50, 100, 1, 0},
+		{"all tied", []float64{10, 10, 10}, 10, 50, 1, 0},
+		{"fastest", []float64{10, 20, 30}, 10, 100, 11, 0},
+		{"middle", []float64{10, 20, 30}, 20, 50, 11, 6},
+		{"slowest", []float64{10, 20, 30}, 30, 0, 11, 10},
+		{"tie with one of two others", []float64{10, 10, 30}, 10, 75, 11, 0},
+		{"zero values", []float64{0, 0}, 0, 50, 1, 0},
+		{"wide spread", []float64{1, 1e6}, 1, 100, HistogramBins, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var distribution Distribution
+			for _, value := range test.values {
+				distribution.Add(value)
+			}
+			ranking := distribution.Rank(test.value)
+			if ranking.Value != test.value || ranking.Beats != test.beats {
+				t.Errorf("ranking = %v beats %v, want %v beats %v", ranking.Value, ranking.Beats, test.value, test.beats)
+			}
+			if len(ranking.Histogram) != test.bins || len(ranking.Histogram) > HistogramBins {
+				t.Fatalf("got %d bins, want %d", len(ranking.Histogram), test.bins)
+			}
+			var total int64
+			for i, bin := range ranking.Histogram {
+				total += bin.Count
+				if bin.Yours != (i == test.yours) {
+					t.Errorf("bin %d yours = %v, want the value in bin %d", i, bin.Yours, test.yours)
+				}
+				if !(bin.From < bin.To) || (i > 0 && math.Abs(bin.From-ranking.Histogram[i-1].To) > 1e-9) {
+					t.Errorf("bin %d [%v, %v) does not follow the previous bin", i, bin.From, bin.To)
+				}
+			}
+			if total != distribution.Count {
+				t.Errorf("histogram counts %d submissions, want %d", total, distribution.Count)
+			}
+		})
+	}
+}
+
+func TestDistributionRankSkipsInvalidBuckets(t *testing.T) {
+	distribution := Distribution{Buckets: map[string]int64{"49": 1, "x": 5, "62": 0}, Count: 1}
+	ranking := distribution.Rank(10)
+	if ranking.Beats != 100 || len(ranking.Histogram) != 1 || ranking.Histogram[0].Count != 1 {
+		t.Errorf("ranking = %+v, want only the valid bucket", ranking)
+	}
+}
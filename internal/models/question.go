@@ -8,13 +8,20 @@ type Question struct {
 	ID                    string                  `json:"_id,omitempty" bson:"_id"`
 	Title                 string                  `json:"title,omitempty" bson:"title"`
 	Slug                  string                  `json:"slug,omitempty" bson:"slug"`
+	PreviousSlugs         []string                `json:"previousSlugs,omitempty" bson:"previousSlugs,omitempty"` // Old slugs that redirect to this question
 	Description           string                  `json:"description,omitempty" bson:"description"` // Difficulty level (easy, medium, hard)
 	Difficulty            string                  `json:"difficulty,omitempty" bson:"difficulty"`   // Tags for categorization (e.g., array, dynamic programming)
 	Tags                  []string                `json:"tags,omitempty" bson:"tags"`               // Reference to user who created the question
 	SampleTestCases       []InputOutput           `json:"sampleTestCases,omitempty" bson:"sampleTestCases"`
 	TestCaseVariableNames string                  `json:"testCaseVariableNames"`
 	CodeTemplates         map[string]CodeTemplate `json:"codeTemplates,omitempty" bson:"codeTemplates"`
+	Signature             *FunctionSignature      `json:"signature,omitempty" bson:"signature,omitempty"`
+	TestGroups            []TestGroup             `json:"testGroups,omitempty" bson:"testGroups,omitempty"`
 	Solution              string                  `json:"solution,omitempty" bson:"solution"`
+	SolutionLanguage      string                  `json:"solutionLanguage,omitempty" bson:"solutionLanguage,omitempty"` // Language of the reference solution, go when empty
+	Editorial             string                  `json:"editorial,omitempty" bson:"editorial,omitempty"`
+	SolutionPolicy        string                  `json:"solutionPolicy,omitempty" bson:"solutionPolicy,omitempty"` // When the solution and editorial unlock, after_solve when empty
+	UnlockAt              *time.Time              `json:"unlockAt,omitempty" bson:"unlockAt,omitempty"`
 	CreatedBy             string                  `json:"createdBy,omitempty" bson:"createdBy"`             // Problem constraints (e.g., time complexity)
 	TimeLimit             float64                 `json:"timeLimit,omitempty" bson:"timeLimit"`             // Memory limit per test case execution (in kb)
 	MemoryLimit           float64                 `json:"memoryLimit,omitempty" bson:"memoryLimit"`         // Whether the question is public or private
@@ -23,6 +30,19 @@ type Question struct {
 	SuccessRate           float64                 `json:"successRate,omitempty" bson:"successRate"`
 	Users                 map[string]string       `json:"users,omitempty" bson:"users"`
 	UserStatus            string                  `json:"userStatus,omitempty" bson:"userStatus"`
+	Stats                 *QuestionStats          `json:"stats,omitempty" bson:"stats,omitempty"`
+	Locale                string                  `json:"locale,omitempty" bson:"locale,omitempty"`
+	Translations          map[string]Translation  `json:"translations,omitempty" bson:"translations,omitempty"`
+	AvailableLocales      []string                `json:"availableLocales,omitempty" bson:"-"`
+	DescriptionHTML       string                  `json:"descriptionHtml,omitempty" bson:"descriptionHtml,omitempty"`
+	EditorialHTML         string                  `json:"editorialHtml,omitempty" bson:"editorialHtml,omitempty"`
+	Snippet               string                  `json:"snippet,omitempty" bson:"-"`
+	Lifecycle             string                  `json:"lifecycle,omitempty" bson:"lifecycle,omitempty"`       // Published when empty
+	PublishAt             *time.Time              `json:"publishAt,omitempty" bson:"publishAt,omitempty"`       // When a scheduled question goes live
+	ArchivedFrom          string                  `json:"archivedFrom,omitempty" bson:"archivedFrom,omitempty"` // Lifecycle before archiving, used by restore
+	ArchivedAt            *time.Time              `json:"archivedAt,omitempty" bson:"archivedAt,omitempty"`
+	ClonedFrom            string                  `json:"clonedFrom,omitempty" bson:"clonedFrom,omitempty"` // Question this one was cloned from
+	Revision              int                     `json:"revision,omitempty" bson:"revision"`
 	CreatedAt             time.Time               `json:"createdAt,omitempty" bson:"createdAt"`
 	UpdatedAt             time.Time               `json:"updatedAt,omitempty" bson:"updatedAt"`
 }
@@ -32,3 +52,25 @@ type CodeTemplate struct {
 	Template string `json:"template"`
 	Postcode string `json:"postcode"`
 }
+
+// Scoring policies of a test group
+const (
+	ScoringAllOrNothing = "all_or_nothing"
+	ScoringProportional = "proportional"
+)
+
+// TestGroup is a named subtask of hidden test cases worth Points. A group is
+// only judged once every group in Dependencies earned its full points.
+type TestGroup struct {
+	Name         string   `json:"name" bson:"name"`
+	Points       float64  `json:"points" bson:"points"`
+	Dependencies []string `json:"dependencies,omitempty" bson:"dependencies,omitempty"`
+	Scoring      string   `json:"scoring,omitempty" bson:"scoring,omitempty"`
+}
+
+// CloneOptions controls how a question is cloned
+type CloneOptions struct {
+	Title            string `json:"title"`            // Defaults to the original title marked as a copy
+	IncludeTestCases bool   `json:"includeTestCases"` // Copy the test case batches
+	LinkTestCases    bool   `json:"linkTestCases"`    // Keep the copied batches linked to the originals until they are edited
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sort orders of the question catalog
+const (
+	SortByID          = "id"
+	SortByTitle       = "title"
+	SortByDifficulty  = "difficulty"
+	SortByAcceptance  = "acceptance"
+	SortBySubmissions = "submissions"
+	SortByNewest      = "newest"
+	SortByRelevance   = "relevance" // Only with a search query
+)
+
+// MaxPageLimit bounds the page size a client can ask for
+const MaxPageLimit = 100
+
+var sortOrders = map[string]bool{
+	SortByID: true, SortByTitle: true, SortByDifficulty: true, SortByAcceptance: true,
+	SortBySubmissions: true, SortByNewest: true, SortByRelevance: true,
+}
+
+// QuestionQuery selects questions for the catalog listing
+type QuestionQuery struct {
+	UserID             string
+	Search             string   // Full-text query, results are ranked by relevance
+	Difficulties       []string // Any of them
+	Tags               []string // Every tag has to be present
+	Status             string   // solved, attempted or todo for the user
+	Lifecycle          string   // Only used together with IncludeUnpublished
+	IncludeUnpublished bool
+	Sort               string // id when empty, relevance when searching
+	Descending         bool
+	Page               int // Starts at 1
+	Limit              int
+}
+
+// Validate checks the filters, sort order and page bounds
+func (q QuestionQuery) Validate() error {
+	for _, difficulty := range q.Difficulties {
+		if err := ValidateDifficulty(difficulty); err != nil {
+			return err
+		}
+	}
+	switch q.Status {
+	case "", "solved", "attempted", "todo":
+	default:
+		return fmt.Errorf("invalid status %q, expected solved, attempted or todo", q.Status)
+	}
+	if q.Sort != "" && !sortOrders[q.Sort] {
+		return fmt.Errorf("invalid sort %q", q.Sort)
+	}
+	if q.Sort == SortByRelevance && q.Search == "" {
+		return errors.New("sorting by relevance needs a search query")
+	}
+	if q.Page < 1 {
+		return errors.New("page starts at 1")
+	}
+	if q.Limit < 1 || q.Limit > MaxPageLimit {
+		return fmt.Errorf("limit must be between 1 and %d", MaxPageLimit)
+	}
+	return nil
+}
+
+// SortOrder returns the effective sort, relevance for searches and id otherwise
+func (q QuestionQuery) SortOrder() string {
+	if q.Sort != "" {
+		return q.Sort
+	}
+	if q.Search != "" {
+		return SortByRelevance
+	}
+	return SortByID
+}
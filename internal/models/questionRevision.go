@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// QuestionRevision is an immutable snapshot of a question taken after every edit
+type QuestionRevision struct {
+	ID         string    `json:"_id,omitempty" bson:"_id"`
+	QuestionID string    `json:"questionId" bson:"questionId"`
+	Revision   int       `json:"revision" bson:"revision"`
+	Author     string    `json:"author,omitempty" bson:"author"`
+	Message    string    `json:"message,omitempty" bson:"message,omitempty"`
+	Snapshot   *Question `json:"snapshot,omitempty" bson:"snapshot,omitempty"`
+	CreatedAt  time.Time `json:"createdAt" bson:"createdAt"`
+}
+
+// FieldChange is one differing field between two question revisions
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
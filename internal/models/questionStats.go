@@ -0,0 +1,25 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// QuestionStats are the judged submission counters of a question. They are
+// incremented on every submission and recomputed by the stats backfill.
+type QuestionStats struct {
+	Submissions int64            `json:"submissions" bson:"submissions"`
+	Accepted    int64            `json:"accepted" bson:"accepted"`
+	Solvers     int64            `json:"solvers" bson:"solvers"` // Users with at least one accepted submission
+	Languages   map[string]int64 `json:"languages,omitempty" bson:"languages,omitempty"`
+	Verdicts    map[string]int64 `json:"verdicts,omitempty" bson:"verdicts,omitempty"`
+	UpdatedAt   time.Time        `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
+}
+
+// SuccessRate is the accepted share of the submissions in percent, rounded to two decimals
+func (s QuestionStats) SuccessRate() float64 {
+	if s.Submissions == 0 {
+		return 0
+	}
+	return math.Round(float64(s.Accepted)/float64(s.Submissions)*10000) / 100
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Views of a question, from the least to the most revealing
+const (
+	ViewPublic = "public" // anonymous users and users who have not solved it
+	ViewSolver = "solver" // users who solved it
+	ViewAdmin  = "admin"
+)
+
+// Policies deciding when the solution and editorial of a question unlock
+const (
+	UnlockAfterSolve = "after_solve" // default, for users who solved the question
+	UnlockAfterTime  = "after_time"  // for everyone once UnlockAt has passed, solvers right away
+	UnlockAlways     = "always"
+	UnlockNever      = "never" // only admins see them
+)
+
+// ValidateUnlockPolicy accepts the solution unlock policies, empty meaning after_solve
+func ValidateUnlockPolicy(policy string, unlockAt *time.Time) error {
+	switch policy {
+	case "", UnlockAfterSolve, UnlockAlways, UnlockNever:
+		return nil
+	case UnlockAfterTime:
+		if unlockAt == nil {
+			return fmt.Errorf("the %s policy needs unlockAt", UnlockAfterTime)
+		}
+		return nil
+	}
+	return fmt.Errorf("solutionPolicy must be %s, %s, %s or %s", UnlockAfterSolve, UnlockAfterTime, UnlockAlways, UnlockNever)
+}
+
+// QuestionViewFor picks the view of a question for the requesting user
+func QuestionViewFor(question *Question, isAdmin bool) string {
+	if isAdmin {
+		return ViewAdmin
+	}
+	if question.UserStatus == "solved" {
+		return ViewSolver
+	}
+	return ViewPublic
+}
+
+// SolutionUnlocked reports whether the solution and editorial can be shown in the view
+func (q Question) SolutionUnlocked(view string, now time.Time) bool {
+	if view == ViewAdmin {
+		return true
+	}
+	switch q.SolutionPolicy {
+	case UnlockAlways:
+		return true
+	case UnlockNever:
+		return false
+	case UnlockAfterTime:
+		return view == ViewSolver || (q.UnlockAt != nil && !now.Before(*q.UnlockAt))
+	}
+	return view == ViewSolver
+}
+
+// ForView returns a copy of the question with the fields the view may not see
+// removed. Harness code around the templates, the locked solution and
+// editorial, and the progress of other users are admin only.
+func (q Question) ForView(view string) Question {
+	if view == ViewAdmin {
+		return q
+	}
+	if !q.SolutionUnlocked(view, time.Now()) {
+		q.Solution = ""
+		q.SolutionLanguage = ""
+		q.Editorial = ""
+		q.EditorialHTML = ""
+	}
+	if q.CodeTemplates != nil {
+		templates := make(map[string]CodeTemplate, len(q.CodeTemplates))
+		for language, template := range q.CodeTemplates {
+			templates[language] = CodeTemplate{Template: template.Template}
+		}
+		q.CodeTemplates = templates
+	}
+	q.Users = nil
+	q.Translations = nil // the statement is localized before the view is taken
+	q.CreatedBy = ""
+	q.Revision = 0
+	q.SolutionPolicy = ""
+	return q
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSolutionUnlocked(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	tests := []struct {
+		name     string
+		policy   string
+		unlockAt *time.Time
+		view     string
+		want     bool
+	}{
+		{"admin always", UnlockNever, nil, ViewAdmin, true},
+		{"default locked for public", "", nil, ViewPublic, false},
+		{"default open for solvers", "", nil, ViewSolver, true},
+		{"always", UnlockAlways, nil, ViewPublic, true},
+		{"never for solvers", UnlockNever, nil, ViewSolver, false},
+		{"after time, before it", UnlockAfterTime, &future, ViewPublic, false},
+		{"after time, once passed", UnlockAfterTime, &past, ViewPublic, true},
+		{"after time, solvers early", UnlockAfterTime, &future, ViewSolver, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			question := Question{SolutionPolicy: test.policy, UnlockAt: test.unlockAt}
+			if got := question.SolutionUnlocked(test.view, now); got != test.want {
+				t.Errorf("SolutionUnlocked(%s) = %v, want %v", test.view, got, test.want)
+			}
+		})
+	}
+}
+
+func TestForView(t *testing.T) {
+	question := Question{
+		Solution:  "secret solution",
+		Editorial: "secret editorial",
+		CodeTemplates: map[string]CodeTemplate{
+			"go": {Precode: "package main", Template: "func f() {}", Postcode: "func main() {}"},
+		},
+		Users:     map[string]string{"u1": "solved"},
+		CreatedBy: "admin",
+	}
+	public := question.ForView(ViewPublic)
+	if public.Solution != "" || public.Editorial != "" {
+		t.Error("the public view shows the locked solution or editorial")
+	}
+	if template := public.CodeTemplates["go"]; template.Precode != "" || template.Postcode != "" || template.Template != "func f() {}" {
+		t.Errorf("public template = %#v, want only the stub", template)
+	}
+	if public.Users != nil || public.CreatedBy != "" {
+		t.Error("the public view shows other users or the author")
+	}
+	if question.CodeTemplates["go"].Precode == "" {
+		t.Error("ForView changed the original question's templates")
+	}
+	if admin := question.ForView(ViewAdmin); admin.Solution == "" || admin.CodeTemplates["go"].Postcode == "" {
+		t.Error("the admin view hides fields")
+	}
+	// The response keeps its shape, redacted harness code is sent empty
+	encoded, err := json.Marshal(public.CodeTemplates["go"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(encoded), `"precode":""`) || !strings.Contains(string(encoded), `"postcode":""`) {
+		t.Errorf("template encodes as %s, want precode and postcode keys", encoded)
+	}
+}
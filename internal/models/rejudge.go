@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+)
+
+// Rejudge job states
+const (
+	RejudgeQueued    = "queued"
+	RejudgeRunning   = "running"
+	RejudgeCompleted = "completed"
+	RejudgeFailed    = "failed"
+)
+
+// RejudgeRequest selects the stored submissions to run through the judge again.
+// Every criterion that is set must match.
+type RejudgeRequest struct {
+	QuestionID    string     `json:"questionId,omitempty" bson:"questionId,omitempty"`
+	From          *time.Time `json:"from,omitempty" bson:"from,omitempty"`
+	To            *time.Time `json:"to,omitempty" bson:"to,omitempty"`
+	SubmissionIDs []string   `json:"submissionIds,omitempty" bson:"submissionIds,omitempty"`
+}
+
+// RejudgeJob tracks a background rejudge and the verdicts it changed.
+type RejudgeJob struct {
+	ID          string          `json:"_id,omitempty" bson:"_id"`
+	Request     RejudgeRequest  `json:"request" bson:"request"`
+	Status      string          `json:"status" bson:"status"`
+	RequestedBy string          `json:"requestedBy,omitempty" bson:"requestedBy"`
+	Total       int             `json:"total" bson:"total"`
+	Processed   int             `json:"processed" bson:"processed"`
+	Changes     []RejudgeChange `json:"changes,omitempty" bson:"changes"`
+	Err         string          `json:"err,omitempty" bson:"err"`
+	CreatedAt   time.Time       `json:"createdAt,omitempty" bson:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt,omitempty" bson:"updatedAt"`
+	FinishedAt  *time.Time      `json:"finishedAt,omitempty" bson:"finishedAt,omitempty"`
+}
+
+type RejudgeChange struct {
+	SubmissionID string `json:"submissionId" bson:"submissionId"`
+	UserId       string `json:"userId" bson:"userId"`
+	Question     string `json:"question" bson:"question"`
+	OldVerdict   string `json:"oldVerdict" bson:"oldVerdict"`
+	NewVerdict   string `json:"newVerdict" bson:"newVerdict"`
+	OldPassed    int    `json:"oldPassed" bson:"oldPassed"`
+	NewPassed    int    `json:"newPassed" bson:"newPassed"`
+}
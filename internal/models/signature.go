@@ -0,0 +1,207 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Scalar value types of a function signature, arrays add [] as in int[][]
+const (
+	TypeInt    = "int"
+	TypeLong   = "long"
+	TypeDouble = "double"
+	TypeBool   = "bool"
+	TypeString = "string"
+)
+
+// Data structure types of a function signature, holding int values. Their
+// input forms follow LeetCode's.
+const (
+	TypeList     = "list"     // Singly linked list, [1,2,3]
+	TypeTree     = "tree"     // Binary tree in level order, [1,null,2,3]
+	TypeNaryTree = "narytree" // N-ary tree in level order with each child group ended by null, [1,null,3,2,4,null,5,6]
+	TypeGraph    = "graph"    // Undirected graph of n nodes as [n,[[u,v],...]], passed as adjacency lists
+	TypeMatrix   = "matrix"   // Rectangular grid of ints, [[1,2],[3,4]]
+)
+
+// MaxArrayDepth is the deepest array nesting of a signature type
+const MaxArrayDepth = 2
+
+// ScalarTypes are the element types arrays are built from
+var ScalarTypes = []string{TypeInt, TypeLong, TypeDouble, TypeBool, TypeString}
+
+// StructureTypes are the data structures with a built-in codec. Lists and
+// trees can be put in arrays, the others stand alone.
+var StructureTypes = []string{TypeList, TypeTree, TypeNaryTree, TypeGraph, TypeMatrix}
+
+// maxStructureDepth is the array nesting allowed for each data structure
+var maxStructureDepth = map[string]int{TypeList: 1, TypeTree: 1}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// reservedNames are keywords and predeclared names of the template languages
+// and the names the generated harnesses use for their own variables
+var reservedNames = map[string]bool{}
+
+func init() {
+	for _, name := range strings.Fields(`
+		auto break case char const continue default do double else enum extern float for goto if inline
+		int long register restrict return short signed sizeof static struct switch typedef union unsigned
+		void volatile while bool true false class public private protected new delete this template
+		typename namespace using virtual operator friend try catch throw nullptr NULL string vector std
+		abstract assert boolean byte extends final finally implements import instanceof interface native
+		package super synchronized throws transient var null String Object List
+		chan defer fallthrough func go map range select type int64 float64 rune error len cap append
+		make nil copy panic recover
+		and as del elif except from global in is lambda nonlocal not or pass raise with yield None True
+		False self sys json next print
+		let function arguments await async typeof undefined require process console JSON Math
+		ListNode TreeNode Node
+		main args Solution solution result reader writer values returnSize returnColumnSizes`) {
+		reservedNames[name] = true
+	}
+}
+
+// ValueType is a parsed signature type, a scalar or data structure nested in Depth arrays
+type ValueType struct {
+	Base  string
+	Depth int
+}
+
+// ParseValueType reads a type such as int, string[] or double[][]
+func ParseValueType(name string) (ValueType, error) {
+	t := ValueType{Base: strings.Join(strings.Fields(name), "")}
+	for strings.HasSuffix(t.Base, "[]") {
+		t.Base = strings.TrimSuffix(t.Base, "[]")
+		t.Depth++
+	}
+	if t.IsStructure() {
+		if t.Depth > maxStructureDepth[t.Base] {
+			return t, fmt.Errorf("type %q cannot be put in arrays this deep", name)
+		}
+		return t, nil
+	}
+	known := false
+	for _, scalar := range ScalarTypes {
+		known = known || t.Base == scalar
+	}
+	if !known {
+		return t, fmt.Errorf("unknown type %q, use %s with [] for arrays or %s", name, strings.Join(ScalarTypes, ", "), strings.Join(StructureTypes, ", "))
+	}
+	if t.Depth > MaxArrayDepth {
+		return t, fmt.Errorf("type %q nests arrays deeper than %d levels", name, MaxArrayDepth)
+	}
+	return t, nil
+}
+
+func (t ValueType) String() string {
+	return t.Base + strings.Repeat("[]", t.Depth)
+}
+
+// IsStructure reports whether the base of the type is a data structure
+func (t ValueType) IsStructure() bool {
+	for _, structure := range StructureTypes {
+		if t.Base == structure {
+			return true
+		}
+	}
+	return false
+}
+
+// IsGrid reports whether the type is passed as rows of ints, like int[][]
+func (t ValueType) IsGrid() bool {
+	return t.Depth == 0 && (t.Base == TypeGraph || t.Base == TypeMatrix)
+}
+
+// Elem is the type of the elements of an array type
+func (t ValueType) Elem() ValueType {
+	return ValueType{Base: t.Base, Depth: t.Depth - 1}
+}
+
+// Parameter is a named argument of a function signature
+type Parameter struct {
+	Name string `json:"name" bson:"name"`
+	Type string `json:"type" bson:"type"`
+}
+
+// FunctionSignature is the function users implement. The code templates of
+// every language are generated from it.
+type FunctionSignature struct {
+	FunctionName string      `json:"functionName" bson:"functionName"`
+	Parameters   []Parameter `json:"parameters" bson:"parameters"`
+	ReturnType   string      `json:"returnType" bson:"returnType"`
+}
+
+func validateIdentifier(name string) error {
+	if !identifierPattern.MatchString(name) {
+		return fmt.Errorf("%q is not a valid name, use letters, digits and underscores starting with a letter", name)
+	}
+	if reservedNames[name] || strings.HasPrefix(strings.ToLower(name), "judge") {
+		return fmt.Errorf("%q is reserved in one of the template languages", name)
+	}
+	return nil
+}
+
+// Validate checks the names and types of the signature and writes the types
+// in their canonical form
+func (s *FunctionSignature) Validate() error {
+	s.FunctionName = strings.TrimSpace(s.FunctionName)
+	if s.FunctionName == "" {
+		return errors.New("signature: functionName is required")
+	}
+	if err := validateIdentifier(s.FunctionName); err != nil {
+		return fmt.Errorf("signature: %v", err)
+	}
+	if len(s.Parameters) == 0 {
+		return errors.New("signature: the function needs at least one parameter")
+	}
+	names := map[string]bool{s.FunctionName: true}
+	derived := map[string]string{} // names the C template adds for array sizes
+	for i := range s.Parameters {
+		parameter := &s.Parameters[i]
+		parameter.Name = strings.TrimSpace(parameter.Name)
+		if err := validateIdentifier(parameter.Name); err != nil {
+			return fmt.Errorf("signature: parameter %d: %v", i+1, err)
+		}
+		if names[parameter.Name] {
+			return fmt.Errorf("signature: the name %q is used twice", parameter.Name)
+		}
+		names[parameter.Name] = true
+		t, err := ParseValueType(parameter.Type)
+		if err != nil {
+			return fmt.Errorf("signature: parameter %s: %v", parameter.Name, err)
+		}
+		parameter.Type = t.String()
+		if t.Depth > 0 || t.IsGrid() {
+			derived[parameter.Name+"Size"] = parameter.Name
+		}
+		if t.Depth > 1 || t.IsGrid() {
+			derived[parameter.Name+"ColSize"] = parameter.Name
+		}
+	}
+	for name, array := range derived {
+		if names[name] {
+			return fmt.Errorf("signature: the name %q clashes with the size of the array %s", name, array)
+		}
+	}
+	t, err := ParseValueType(s.ReturnType)
+	if err != nil {
+		return fmt.Errorf("signature: return type: %v", err)
+	}
+	if t.Base == TypeGraph {
+		return errors.New("signature: a graph can only be a parameter, return its adjacency lists as int[][]")
+	}
+	s.ReturnType = t.String()
+	return nil
+}
+
+// VariableNames lists the parameter names, the input of a test case in order
+func (s FunctionSignature) VariableNames() string {
+	names := make([]string, len(s.Parameters))
+	for i, parameter := range s.Parameters {
+		names[i] = parameter.Name
+	}
+	return strings.Join(names, ", ")
+}
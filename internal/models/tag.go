@@ -0,0 +1,65 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Tag is a managed entry of the tag taxonomy. Its ID is the canonical name
+// stored on questions, aliases resolve to it.
+type Tag struct {
+	ID            string    `json:"name" bson:"_id"`
+	Description   string    `json:"description,omitempty" bson:"description,omitempty"`
+	Aliases       []string  `json:"aliases,omitempty" bson:"aliases,omitempty"`
+	Parent        string    `json:"parent,omitempty" bson:"parent,omitempty"`
+	QuestionCount int64     `json:"questionCount" bson:"-"`
+	Unmanaged     bool      `json:"unmanaged,omitempty" bson:"-"` // Used on questions but missing from the taxonomy
+	Children      []*Tag    `json:"children,omitempty" bson:"-"`
+	CreatedAt     time.Time `json:"createdAt,omitempty" bson:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt,omitempty" bson:"updatedAt"`
+}
+
+// TagPatch is a partial update of a tag, renames go through RenameTag
+type TagPatch struct {
+	Description *string   `json:"description"`
+	Aliases     *[]string `json:"aliases"`
+	Parent      *string   `json:"parent"` // Empty moves the tag to the top level
+}
+
+// TagRename renames a tag on every question, the old name becomes an alias
+type TagRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// TagMerge folds the source tags into the target on every question
+type TagMerge struct {
+	Sources []string `json:"sources"`
+	Target  string   `json:"target"`
+}
+
+// NormalizeTag lowercases and trims a tag and collapses inner whitespace
+func NormalizeTag(tag string) string {
+	return strings.Join(strings.Fields(strings.ToLower(tag)), " ")
+}
+
+// Normalize cleans the name, aliases and parent and checks they are usable
+func (t *Tag) Normalize() error {
+	t.ID = NormalizeTag(t.ID)
+	if t.ID == "" {
+		return errors.New("a tag needs a name")
+	}
+	t.Parent = NormalizeTag(t.Parent)
+	if t.Parent == t.ID {
+		return errors.New("a tag cannot be its own parent")
+	}
+	aliases := []string{}
+	for _, alias := range NormalizeTags(t.Aliases) {
+		if alias != t.ID {
+			aliases = append(aliases, alias)
+		}
+	}
+	t.Aliases = aliases
+	return nil
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TemplateCheckRequest asks for the code templates of a question to be
+// checked. Templates sent along are checked in place of the saved ones of
+// their language, and Solutions holds the reference solution ported to other
+// languages, keyed by language.
+type TemplateCheckRequest struct {
+	QuestionID    string                  `json:"questionId"`
+	CodeTemplates map[string]CodeTemplate `json:"codeTemplates,omitempty"`
+	Solutions     map[string]string       `json:"solutions,omitempty"`
+}
+
+// Validate checks that templates and solutions are keyed by supported languages
+func (c *TemplateCheckRequest) Validate() error {
+	if c.QuestionID == "" {
+		return errors.New("questionId is required")
+	}
+	if err := ValidateCodeTemplates(c.CodeTemplates); err != nil {
+		return err
+	}
+	for language := range c.Solutions {
+		if !IsSupportedLanguage(language) {
+			return fmt.Errorf("solution in unsupported language %q", language)
+		}
+	}
+	return nil
+}
+
+// TemplateCheck is the outcome of checking the template of one language. The
+// template compiles when its stub, between the precode and postcode, does.
+// Solution is the run of the reference solution on the sample test cases,
+// when one was provided in the language. A saved solution in a language
+// without a template is reported as not run, with nothing compiled.
+type TemplateCheck struct {
+	Language     string  `json:"language"`
+	Compiled     bool    `json:"compiled"`
+	CompileError string  `json:"compileError,omitempty"`
+	Solution     *DryRun `json:"solution,omitempty"`
+}
+
+// Ok reports whether the template compiles and the solution, if any, passes
+func (c TemplateCheck) Ok() bool {
+	if !c.Compiled {
+		return false
+	}
+	return c.Solution == nil || c.Solution.Err == "" && c.Solution.Passed == c.Solution.Total
+}
@@ -0,0 +1,51 @@
+package models
+
+import "testing"
+
+func TestTemplateCheckOk(t *testing.T) {
+	tests := []struct {
+		name  string
+		check TemplateCheck
+		want  bool
+	}{
+		{"compiled without solution", TemplateCheck{Compiled: true}, true},
+		{"compile error", TemplateCheck{CompileError: "syntax error"}, false},
+		{"solution passes", TemplateCheck{Compiled: true, Solution: &DryRun{Passed: 2, Total: 2}}, true},
+		{"solution fails a case", TemplateCheck{Compiled: true, Solution: &DryRun{Passed: 1, Total: 2}}, false},
+		{"solution errors", TemplateCheck{Compiled: true, Solution: &DryRun{Passed: 0, Total: 0, Err: "runtime error"}}, false},
+		{"no samples", TemplateCheck{Compiled: true, Solution: &DryRun{}}, true},
+		{"solution not run", TemplateCheck{Solution: &DryRun{Err: "solution not run"}}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.check.Ok(); got != test.want {
+				t.Errorf("Ok() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTemplateCheckRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request TemplateCheckRequest
+		wantErr bool
+	}{
+		{"question only", TemplateCheckRequest{QuestionID: "1"}, false},
+		{"no question", TemplateCheckRequest{Solutions: map[string]string{"py": "pass"}}, true},
+		{
+			"templates and solutions",
+			TemplateCheckRequest{QuestionID: "1", CodeTemplates: map[string]CodeTemplate{"go": {}}, Solutions: map[string]string{"cpp": "int main() {}"}},
+			false,
+		},
+		{"unsupported template", TemplateCheckRequest{QuestionID: "1", CodeTemplates: map[string]CodeTemplate{"rust": {}}}, true},
+		{"unsupported solution", TemplateCheckRequest{QuestionID: "1", Solutions: map[string]string{"python": "pass"}}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.request.Validate(); (err != nil) != test.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -1,15 +1,86 @@
 package models
 
 import (
+	commontypes "code-compiler/internal/commonTypes"
+	"fmt"
+	"regexp"
 	"time"
 )
 
+// Visibility of a test case's data in submission results
+const (
+	VisibilityPublic     = "public"
+	VisibilityShowOnFail = "show_on_fail"
+	VisibilityHidden     = "hidden"
+)
+
+// ValidateVisibility accepts the test case visibility flags, empty meaning hidden
+func ValidateVisibility(visibility string) error {
+	switch visibility {
+	case "", VisibilityPublic, VisibilityShowOnFail, VisibilityHidden:
+		return nil
+	}
+	return fmt.Errorf("visibility must be %s, %s or %s", VisibilityPublic, VisibilityShowOnFail, VisibilityHidden)
+}
+
+// groupNamePattern keeps group names usable as package directories and Mongo keys
+var groupNamePattern = regexp.MustCompile("^[a-z0-9][a-z0-9_-]*$")
+
+// ValidateGroupName accepts test group names made of lowercase letters, digits,
+// dashes and underscores, empty meaning no group
+func ValidateGroupName(name string) error {
+	if name == "" || groupNamePattern.MatchString(name) {
+		return nil
+	}
+	return fmt.Errorf("group %q must start with a lowercase letter or digit and contain only lowercase letters, digits, - and _", name)
+}
+
+// Review states of a test case batch
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
 // TestCase struct represents a single test case for a coding problem.
 type TestCase struct {
-	ID         string        `json:"_id,omitempty" bson:"_id"`
-	QuestionID string        `json:"questionId,omitempty" bson:"questionId"`
-	IOPairs    []InputOutput `json:"ioPairs,omitempty" bson:"ioPairs"`
-	Approved   bool          `json:"approved,omitempty" bson:"approved"`
-	CreatedAt  time.Time     `json:"createdAt,omitempty" bson:"createdAt"`
-	UpdatedAt  time.Time     `json:"updatedAt,omitempty" bson:"updatedAt"`
+	ID           string           `json:"_id,omitempty" bson:"_id"`
+	QuestionID   string           `json:"questionId,omitempty" bson:"questionId"`
+	IOPairs      []InputOutput    `json:"ioPairs,omitempty" bson:"ioPairs"`
+	Group        string           `json:"group,omitempty" bson:"group,omitempty"`
+	Visibility   string           `json:"visibility,omitempty" bson:"visibility,omitempty"` // Empty means hidden
+	Approved     bool             `json:"approved,omitempty" bson:"approved"`
+	ReviewStatus string           `json:"reviewStatus,omitempty" bson:"reviewStatus,omitempty"`
+	Reviews      []TestCaseReview `json:"reviews,omitempty" bson:"reviews,omitempty"` // Audit trail of review changes
+	CreatedBy    string           `json:"createdBy,omitempty" bson:"createdBy,omitempty"`
+	LinkedFrom   string           `json:"linkedFrom,omitempty" bson:"linkedFrom,omitempty"` // Batch whose pairs this clone uses until it is edited
+	ArchivedAt   *time.Time       `json:"archivedAt,omitempty" bson:"archivedAt,omitempty"` // Soft deleted, ignored by the judge
+	ArchivedBy   string           `json:"archivedBy,omitempty" bson:"archivedBy,omitempty"`
+	CreatedAt    time.Time        `json:"createdAt,omitempty" bson:"createdAt"`
+	UpdatedAt    time.Time        `json:"updatedAt,omitempty" bson:"updatedAt"`
+}
+
+// TestCaseReview is one entry in the audit trail of a test case batch
+type TestCaseReview struct {
+	Status     string    `json:"status" bson:"status"`
+	ReviewerID string    `json:"reviewerId" bson:"reviewerId"`
+	Comment    string    `json:"comment,omitempty" bson:"comment,omitempty"`
+	CreatedAt  time.Time `json:"createdAt" bson:"createdAt"`
+}
+
+// DryRun is the reference solution's result on a test case batch, shown to reviewers
+type DryRun struct {
+	Language string                   `json:"language"`
+	Passed   int                      `json:"passed"`
+	Total    int                      `json:"total"`
+	Results  []commontypes.TestResult `json:"results,omitempty"`
+	Err      string                   `json:"err,omitempty"`
+}
+
+// TestDataFileReport is the outcome for one file of a bulk test case upload
+type TestDataFileReport struct {
+	File       string `json:"file"`
+	Status     string `json:"status"` // created, skipped or rejected
+	Message    string `json:"message,omitempty"`
+	TestCaseID string `json:"testCaseId,omitempty"`
 }
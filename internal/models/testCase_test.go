@@ -0,0 +1,52 @@
+package models
+
+import "testing"
+
+func TestValidateGroupName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"", false},
+		{"small", false},
+		{"group-1", false},
+		{"9_large", false},
+		{"../../x", true},
+		{"a/b", true},
+		{"a.b", true},
+		{"$a", true},
+		{"Small", true},
+		{"-a", true},
+		{"_a", true},
+		{"a b", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := ValidateGroupName(test.name); (err != nil) != test.wantErr {
+				t.Errorf("ValidateGroupName(%q) = %v, want error %v", test.name, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestTestCasePatchValidate(t *testing.T) {
+	group := func(name string) *string { return &name }
+	tests := []struct {
+		name    string
+		patch   TestCasePatch
+		wantErr bool
+	}{
+		{"empty", TestCasePatch{}, false},
+		{"group", TestCasePatch{Group: group("large")}, false},
+		{"clear group", TestCasePatch{Group: group("")}, false},
+		{"path group", TestCasePatch{Group: group("../x")}, true},
+		{"no pairs", TestCasePatch{IOPairs: &[]InputOutput{}}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.patch.Validate(); (err != nil) != test.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,627 @@
+package problempackage
+
+import (
+	"archive/zip"
+	"code-compiler/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Package is a complete problem laid out in the Kattis problem package format:
+//
+//	problem.yaml                    name, keywords and limits
+//	problem_statement/problem.en.md statement
+//	data/sample/*.in, *.ans         sample tests
+//	data/secret/[group/]*.in, *.ans hidden tests, one directory per test group
+//	submissions/accepted/solution.* reference solution
+//	attachments/*                   images and files the statement links to
+//	code_compiler/question.json     fields the format has no place for
+type Package struct {
+	Question    models.Question
+	TestCases   []models.TestCase
+	Attachments []Attachment
+}
+
+// Attachment is a file of the statement. ID is what the statement links to,
+// it is empty for packages written by other tools.
+type Attachment struct {
+	ID   string
+	Name string
+	Size int64
+	Blob string                        // content of an exported attachment
+	Open func() (io.ReadCloser, error) // content of an imported attachment
+}
+
+// Limits of packages whose problem.yaml sets none. The legacy format derives
+// the time limit from the judge solutions' running time with time_multiplier,
+// which is not known on import, and leaves memory to the judging system.
+const (
+	DefaultTimeLimit   = 2    // Seconds
+	DefaultMemoryLimit = 2048 // MiB, the format's default
+)
+
+// Files the format has no place for are kept in extensionFile, tools that
+// follow the format ignore the unknown directory.
+const extensionFile = "code_compiler/question.json"
+
+type problemYAML struct {
+	ProblemFormatVersion string      `yaml:"problem_format_version,omitempty"`
+	Name                 interface{} `yaml:"name"`
+	Type                 string      `yaml:"type,omitempty"`
+	Keywords             interface{} `yaml:"keywords,omitempty"`
+	Limits               limitsYAML  `yaml:"limits,omitempty"`
+	Validation           string      `yaml:"validation,omitempty"`
+}
+
+type limitsYAML struct {
+	TimeLimit float64 `yaml:"time_limit,omitempty"` // Seconds
+	Memory    float64 `yaml:"memory,omitempty"`     // MiB
+}
+
+type testDataYAML struct {
+	AcceptScore *float64 `yaml:"accept_score,omitempty"`
+	OnReject    string   `yaml:"on_reject,omitempty"`
+}
+
+// extension holds everything a Kattis package cannot express
+type extension struct {
+	Difficulty            string                         `json:"difficulty,omitempty"`
+	Tags                  []string                       `json:"tags,omitempty"`
+	TestCaseVariableNames string                         `json:"testCaseVariableNames,omitempty"`
+	CodeTemplates         map[string]models.CodeTemplate `json:"codeTemplates,omitempty"`
+	Signature             *models.FunctionSignature      `json:"signature,omitempty"`
+	SolutionLanguage      string                         `json:"solutionLanguage,omitempty"`
+	Editorial             string                         `json:"editorial,omitempty"`
+	SolutionPolicy        string                         `json:"solutionPolicy,omitempty"`
+	UnlockAt              *time.Time                     `json:"unlockAt,omitempty"`
+	TimeLimit             float64                        `json:"timeLimit,omitempty"`
+	MemoryLimit           float64                        `json:"memoryLimit,omitempty"`
+	TestGroups            []models.TestGroup             `json:"testGroups,omitempty"`
+	TestCases             []extensionBatch               `json:"testCases,omitempty"`
+	Locale                string                         `json:"locale,omitempty"`
+	Translations          map[string]models.Translation  `json:"translations,omitempty"`
+	Attachments           []extensionAttachment          `json:"attachments,omitempty"`
+}
+
+// extensionAttachment maps an attachment to its file
+type extensionAttachment struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	File string `json:"file"`
+}
+
+// extensionBatch maps a test case batch to the data files of its pairs
+type extensionBatch struct {
+	Group      string   `json:"group,omitempty"`
+	Visibility string   `json:"visibility,omitempty"`
+	Files      []string `json:"files"`
+}
+
+// OpenBlob returns the content of a blob referenced by a test case
+type OpenBlob func(hash string) (io.ReadCloser, error)
+
+// LoadData turns a test data file into inline text or a blob hash
+type LoadData func(content io.Reader, size int64) (inline string, blob string, err error)
+
+var languageExtensions = map[string]string{
+	"c":    ".c",
+	"cpp":  ".cpp",
+	"go":   ".go",
+	"java": ".java",
+	"js":   ".js",
+	"py":   ".py",
+}
+
+// Write stores the package as a zip archive under a directory named after the slug
+func Write(w io.Writer, pkg *Package, openBlob OpenBlob) error {
+	question := pkg.Question
+	root := question.Slug
+	if root == "" {
+		root = "problem"
+	}
+	// Group names become directories, names stored before they were checked must not leave data/secret
+	for _, group := range question.TestGroups {
+		if err := models.ValidateGroupName(group.Name); err != nil {
+			return err
+		}
+	}
+	for _, testCase := range pkg.TestCases {
+		if err := models.ValidateGroupName(testCase.Group); err != nil {
+			return err
+		}
+	}
+	archive := zip.NewWriter(w)
+	writeFile := func(name string, content io.Reader) error {
+		file, err := archive.Create(path.Join(root, name))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(file, content)
+		return err
+	}
+	writeData := func(name string, inline string, blob string) error {
+		if blob == "" {
+			return writeFile(name, strings.NewReader(inline))
+		}
+		content, err := openBlob(blob)
+		if err != nil {
+			return err
+		}
+		defer content.Close()
+		return writeFile(name, content)
+	}
+
+	problem := problemYAML{
+		ProblemFormatVersion: "2023-07-draft",
+		Name:                 question.Title,
+		Type:                 "pass-fail",
+		Keywords:             question.Tags,
+		Limits: limitsYAML{
+			TimeLimit: question.TimeLimit,
+			Memory:    question.MemoryLimit / 1024,
+		},
+	}
+	if len(question.TestGroups) > 0 {
+		problem.Type = "scoring"
+	}
+	problemFile, err := yaml.Marshal(problem)
+	if err != nil {
+		return err
+	}
+	if err := writeFile("problem.yaml", strings.NewReader(string(problemFile))); err != nil {
+		return err
+	}
+	if err := writeFile("problem_statement/problem.en.md", strings.NewReader(question.Description)); err != nil {
+		return err
+	}
+	for i, sample := range question.SampleTestCases {
+		name := fmt.Sprintf("data/sample/%03d", i+1)
+		if err := writeData(name+".in", sample.Input, sample.InputBlob); err != nil {
+			return err
+		}
+		if err := writeData(name+".ans", sample.Output, sample.OutputBlob); err != nil {
+			return err
+		}
+	}
+
+	for _, group := range question.TestGroups {
+		onReject := "break"
+		if group.Scoring == models.ScoringProportional {
+			onReject = "continue"
+		}
+		points := group.Points
+		testData, err := yaml.Marshal(testDataYAML{AcceptScore: &points, OnReject: onReject})
+		if err != nil {
+			return err
+		}
+		if err := writeFile(path.Join("data/secret", group.Name, "testdata.yaml"), strings.NewReader(string(testData))); err != nil {
+			return err
+		}
+	}
+	ext := extension{
+		Difficulty:            question.Difficulty,
+		Tags:                  question.Tags,
+		TestCaseVariableNames: question.TestCaseVariableNames,
+		CodeTemplates:         question.CodeTemplates,
+		Signature:             question.Signature,
+		SolutionLanguage:      question.SolutionLanguage,
+		Editorial:             question.Editorial,
+		SolutionPolicy:        question.SolutionPolicy,
+		UnlockAt:              question.UnlockAt,
+		TimeLimit:             question.TimeLimit,
+		MemoryLimit:           question.MemoryLimit,
+		TestGroups:            question.TestGroups,
+		Locale:                question.Locale,
+		Translations:          portableTranslations(question.Translations),
+	}
+	counters := map[string]int{}
+	for _, testCase := range pkg.TestCases {
+		batch := extensionBatch{Group: testCase.Group, Visibility: testCase.Visibility, Files: []string{}}
+		for _, pair := range testCase.IOPairs {
+			counters[testCase.Group]++
+			name := path.Join("data/secret", testCase.Group, fmt.Sprintf("%03d", counters[testCase.Group]))
+			if err := writeData(name+".in", pair.Input, pair.InputBlob); err != nil {
+				return err
+			}
+			if err := writeData(name+".ans", pair.Output, pair.OutputBlob); err != nil {
+				return err
+			}
+			batch.Files = append(batch.Files, name)
+		}
+		ext.TestCases = append(ext.TestCases, batch)
+	}
+
+	files := map[string]bool{}
+	for i, attachment := range pkg.Attachments {
+		if attachment.Blob == "" {
+			return fmt.Errorf("attachment %s has no content", attachment.Name)
+		}
+		// Attachments may share a name, the file names have to differ
+		name := path.Join("attachments", attachment.Name)
+		if files[name] {
+			name = path.Join("attachments", fmt.Sprintf("%d-%s", i+1, attachment.Name))
+		}
+		files[name] = true
+		if err := writeData(name, "", attachment.Blob); err != nil {
+			return err
+		}
+		ext.Attachments = append(ext.Attachments, extensionAttachment{ID: attachment.ID, Name: attachment.Name, File: name})
+	}
+
+	if question.Solution != "" {
+		language := question.SolutionLanguage
+		if language == "" {
+			language = "go"
+		}
+		if err := writeFile("submissions/accepted/solution"+languageExtensions[language], strings.NewReader(question.Solution)); err != nil {
+			return err
+		}
+	}
+	extFile, err := json.MarshalIndent(ext, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFile(extensionFile, strings.NewReader(string(extFile))); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// portableTranslations keeps the translated text, the rendered HTML and the
+// bookkeeping are redone by the importing instance
+func portableTranslations(translations map[string]models.Translation) map[string]models.Translation {
+	if len(translations) == 0 {
+		return nil
+	}
+	portable := make(map[string]models.Translation, len(translations))
+	for locale, translation := range translations {
+		portable[locale] = models.Translation{
+			Title:       translation.Title,
+			Description: translation.Description,
+			Editorial:   translation.Editorial,
+		}
+	}
+	return portable
+}
+
+// Read loads a package from a directory or an opened zip archive. The package
+// may sit at the root or inside a single top-level directory.
+func Read(fsys fs.FS, loadData LoadData) (*Package, error) {
+	root, err := packageRoot(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if root != "." {
+		if fsys, err = fs.Sub(fsys, root); err != nil {
+			return nil, err
+		}
+	}
+	if validators, err := fs.ReadDir(fsys, "output_validators"); err == nil && len(validators) > 0 {
+		return nil, errors.New("custom output validators are not supported, only default validation")
+	}
+
+	var problem problemYAML
+	problemFile, err := fs.ReadFile(fsys, "problem.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("problem.yaml is missing: %v", err)
+	}
+	if err := yaml.Unmarshal(problemFile, &problem); err != nil {
+		return nil, fmt.Errorf("problem.yaml is invalid: %v", err)
+	}
+	var ext extension
+	if extFile, err := fs.ReadFile(fsys, extensionFile); err == nil {
+		if err := json.Unmarshal(extFile, &ext); err != nil {
+			return nil, fmt.Errorf("%s is invalid: %v", extensionFile, err)
+		}
+	}
+
+	question := models.Question{
+		Title:                 localized(problem.Name),
+		Tags:                  keywords(problem.Keywords),
+		TimeLimit:             problem.Limits.TimeLimit,
+		MemoryLimit:           problem.Limits.Memory * 1024,
+		Difficulty:            ext.Difficulty,
+		TestCaseVariableNames: ext.TestCaseVariableNames,
+		CodeTemplates:         ext.CodeTemplates,
+		Signature:             ext.Signature,
+		TestGroups:            ext.TestGroups,
+		Editorial:             ext.Editorial,
+		SolutionPolicy:        ext.SolutionPolicy,
+		UnlockAt:              ext.UnlockAt,
+		Locale:                ext.Locale,
+		Translations:          ext.Translations,
+	}
+	if ext.Tags != nil {
+		question.Tags = ext.Tags
+	}
+	if ext.TimeLimit != 0 {
+		question.TimeLimit = ext.TimeLimit
+	}
+	if ext.MemoryLimit != 0 {
+		question.MemoryLimit = ext.MemoryLimit
+	}
+	if question.TimeLimit == 0 {
+		question.TimeLimit = DefaultTimeLimit
+	}
+	if question.MemoryLimit == 0 {
+		question.MemoryLimit = DefaultMemoryLimit * 1024
+	}
+	if question.CodeTemplates == nil {
+		question.CodeTemplates = map[string]models.CodeTemplate{}
+	}
+	if question.Description, err = readStatement(fsys); err != nil {
+		return nil, err
+	}
+	if question.Solution, question.SolutionLanguage, err = readSolution(fsys); err != nil {
+		return nil, err
+	}
+	if ext.SolutionLanguage != "" {
+		question.SolutionLanguage = ext.SolutionLanguage
+	}
+
+	samples, err := readTestFiles(fsys, "data/sample", false)
+	if err != nil {
+		return nil, err
+	}
+	question.SampleTestCases = []models.InputOutput{}
+	for _, stem := range samples {
+		pair, err := readPair(fsys, stem, loadData)
+		if err != nil {
+			return nil, err
+		}
+		question.SampleTestCases = append(question.SampleTestCases, pair)
+	}
+
+	secret, err := readTestFiles(fsys, "data/secret", true)
+	if err != nil {
+		return nil, err
+	}
+	pkg := &Package{Question: question}
+	if pkg.Attachments, err = readAttachments(fsys, ext.Attachments); err != nil {
+		return nil, err
+	}
+	if len(ext.TestCases) > 0 {
+		for _, batch := range ext.TestCases {
+			testCase := models.TestCase{Group: batch.Group, Visibility: batch.Visibility}
+			for _, stem := range batch.Files {
+				pair, err := readPair(fsys, stem, loadData)
+				if err != nil {
+					return nil, err
+				}
+				testCase.IOPairs = append(testCase.IOPairs, pair)
+			}
+			pkg.TestCases = append(pkg.TestCases, testCase)
+		}
+		return pkg, nil
+	}
+
+	// Without our extension file every secret directory becomes a test group
+	batches := map[string]*models.TestCase{}
+	var groups []string
+	for _, stem := range secret {
+		group := strings.TrimPrefix(path.Dir(stem), "data/secret")
+		group = strings.TrimPrefix(group, "/")
+		if batches[group] == nil {
+			batches[group] = &models.TestCase{Group: group}
+			groups = append(groups, group)
+		}
+		pair, err := readPair(fsys, stem, loadData)
+		if err != nil {
+			return nil, err
+		}
+		batches[group].IOPairs = append(batches[group].IOPairs, pair)
+	}
+	for _, group := range groups {
+		pkg.TestCases = append(pkg.TestCases, *batches[group])
+		if group == "" || len(ext.TestGroups) > 0 {
+			continue
+		}
+		testGroup := models.TestGroup{Name: group, Scoring: models.ScoringAllOrNothing}
+		if testDataFile, err := fs.ReadFile(fsys, path.Join("data/secret", group, "testdata.yaml")); err == nil {
+			var testData testDataYAML
+			if err := yaml.Unmarshal(testDataFile, &testData); err != nil {
+				return nil, fmt.Errorf("testdata.yaml of group %s is invalid: %v", group, err)
+			}
+			if testData.AcceptScore != nil {
+				testGroup.Points = *testData.AcceptScore
+			}
+			if testData.OnReject == "continue" {
+				testGroup.Scoring = models.ScoringProportional
+			}
+		}
+		pkg.Question.TestGroups = append(pkg.Question.TestGroups, testGroup)
+	}
+	return pkg, nil
+}
+
+// readAttachments finds the attachment files, listed in our extension file or
+// else every file in attachments/. Their content is read on Open.
+func readAttachments(fsys fs.FS, listed []extensionAttachment) ([]Attachment, error) {
+	if listed == nil {
+		entries, err := fs.ReadDir(fsys, "attachments")
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				listed = append(listed, extensionAttachment{Name: entry.Name(), File: path.Join("attachments", entry.Name())})
+			}
+		}
+	}
+	var attachments []Attachment
+	for _, attachment := range listed {
+		info, err := fs.Stat(fsys, attachment.File)
+		if err != nil {
+			return nil, fmt.Errorf("attachment %s: %v", attachment.Name, err)
+		}
+		file := attachment.File
+		attachments = append(attachments, Attachment{
+			ID:   attachment.ID,
+			Name: attachment.Name,
+			Size: info.Size(),
+			Open: func() (io.ReadCloser, error) { return fsys.Open(file) },
+		})
+	}
+	return attachments, nil
+}
+
+// packageRoot finds the directory that holds problem.yaml
+func packageRoot(fsys fs.FS) (string, error) {
+	if _, err := fs.Stat(fsys, "problem.yaml"); err == nil {
+		return ".", nil
+	}
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			if _, err := fs.Stat(fsys, path.Join(entry.Name(), "problem.yaml")); err == nil {
+				return entry.Name(), nil
+			}
+		}
+	}
+	return "", errors.New("problem.yaml is missing")
+}
+
+// localized picks the English name when the name is given per language
+func localized(name interface{}) string {
+	switch name := name.(type) {
+	case string:
+		return name
+	case map[string]interface{}:
+		if english, ok := name["en"].(string); ok {
+			return english
+		}
+		for _, value := range name {
+			if text, ok := value.(string); ok {
+				return text
+			}
+		}
+	}
+	return ""
+}
+
+// keywords accepts both the list form and the older space separated string
+func keywords(value interface{}) []string {
+	tags := []string{}
+	switch value := value.(type) {
+	case string:
+		tags = append(tags, strings.Fields(value)...)
+	case []interface{}:
+		for _, keyword := range value {
+			if text, ok := keyword.(string); ok {
+				tags = append(tags, text)
+			}
+		}
+	}
+	return tags
+}
+
+func readStatement(fsys fs.FS) (string, error) {
+	for _, name := range []string{"problem.en.md", "problem.md", "problem.en.tex", "problem.tex"} {
+		for _, dir := range []string{"problem_statement", "statement"} {
+			if content, err := fs.ReadFile(fsys, path.Join(dir, name)); err == nil {
+				return string(content), nil
+			}
+		}
+	}
+	for _, pattern := range []string{"problem_statement/problem.*.md", "statement/problem.*.md"} {
+		matches, _ := fs.Glob(fsys, pattern)
+		if len(matches) > 0 {
+			content, err := fs.ReadFile(fsys, matches[0])
+			return string(content), err
+		}
+	}
+	return "", errors.New("problem statement is missing")
+}
+
+func readSolution(fsys fs.FS) (string, string, error) {
+	entries, err := fs.ReadDir(fsys, "submissions/accepted")
+	if err != nil {
+		return "", "", nil
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		extension := path.Ext(entry.Name())
+		for language, languageExtension := range languageExtensions {
+			if extension == languageExtension || (language == "cpp" && (extension == ".cc" || extension == ".cxx")) {
+				content, err := fs.ReadFile(fsys, path.Join("submissions/accepted", entry.Name()))
+				return string(content), language, err
+			}
+		}
+	}
+	return "", "", nil
+}
+
+// readTestFiles returns the sorted stems of the .in files in dir
+func readTestFiles(fsys fs.FS, dir string, recursive bool) ([]string, error) {
+	var stems []string
+	err := fs.WalkDir(fsys, dir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && name == dir {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() {
+			if name != dir && !recursive {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(name, ".in") {
+			stems = append(stems, strings.TrimSuffix(name, ".in"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(stems)
+	return stems, nil
+}
+
+// readPair loads stem.in with stem.ans, accepting stem.out for the answer.
+// Answers end with a newline by convention while the judge compares trimmed
+// output, so inline answers are trimmed the same way.
+func readPair(fsys fs.FS, stem string, loadData LoadData) (models.InputOutput, error) {
+	var pair models.InputOutput
+	var err error
+	if pair.Input, pair.InputBlob, err = readData(fsys, stem+".in", loadData); err != nil {
+		return pair, err
+	}
+	answer := stem + ".ans"
+	if _, err := fs.Stat(fsys, answer); err != nil {
+		answer = stem + ".out"
+	}
+	if pair.Output, pair.OutputBlob, err = readData(fsys, answer, loadData); err != nil {
+		return pair, fmt.Errorf("%s has no answer file: %v", stem+".in", err)
+	}
+	pair.Output = strings.TrimSpace(pair.Output)
+	return pair, nil
+}
+
+func readData(fsys fs.FS, name string, loadData LoadData) (string, string, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return "", "", err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return "", "", err
+	}
+	return loadData(file, info.Size())
+}
@@ -0,0 +1,397 @@
+package problempackage
+
+import (
+	"archive/zip"
+	"bytes"
+	"code-compiler/internal/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// memoryBlobs stands in for the blob store, files larger than limit go to it
+type memoryBlobs struct {
+	limit int64
+	blobs map[string]string
+}
+
+func (m *memoryBlobs) load(content io.Reader, size int64) (string, string, error) {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return "", "", err
+	}
+	if size <= m.limit {
+		return string(data), "", nil
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	m.blobs[hash] = string(data)
+	return "", hash, nil
+}
+
+func (m *memoryBlobs) open(hash string) (io.ReadCloser, error) {
+	data, ok := m.blobs[hash]
+	if !ok {
+		return nil, fmt.Errorf("no blob %s", hash)
+	}
+	return io.NopCloser(bytes.NewReader([]byte(data))), nil
+}
+
+func writePackage(t *testing.T, pkg *Package, blobs *memoryBlobs) []byte {
+	t.Helper()
+	var archive bytes.Buffer
+	if err := Write(&archive, pkg, blobs.open); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	return archive.Bytes()
+}
+
+func readPackage(t *testing.T, archive []byte, blobs *memoryBlobs) *Package {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := Read(reader, blobs.load)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	return pkg
+}
+
+// kattisPackage is a package as written by other tools, answers ending in a newline
+func kattisPackage() fstest.MapFS {
+	file := func(content string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(content)} }
+	return fstest.MapFS{
+		"sum/problem.yaml":                      file("name: Sum\nkeywords: [math, easy]\nlimits:\n  time_limit: 2\n  memory: 256\n"),
+		"sum/problem_statement/problem.en.md":   file("Add two numbers.\n"),
+		"sum/data/sample/1.in":                  file("1 2\n"),
+		"sum/data/sample/1.ans":                 file("3\n"),
+		"sum/data/secret/small/1.in":            file("2 2\n"),
+		"sum/data/secret/small/1.ans":           file("4\n"),
+		"sum/data/secret/small/testdata.yaml":   file("accept_score: 40\non_reject: break\n"),
+		"sum/data/secret/large/1.in":            file("1000000 1000000\n"),
+		"sum/data/secret/large/1.ans":           file("2000000\n\n"),
+		"sum/data/secret/large/2.in":            file("7 8\n"),
+		"sum/data/secret/large/2.out":           file("15\n"),
+		"sum/data/secret/large/testdata.yaml":   file("accept_score: 60\non_reject: continue\n"),
+		"sum/submissions/accepted/solution.py":  file("print(sum(map(int, input().split())))\n"),
+		"sum/submissions/accepted/README":       file("not a solution"),
+		"sum/problem_statement/sample.in.other": file("ignored"),
+	}
+}
+
+func TestReadKattisPackage(t *testing.T) {
+	blobs := &memoryBlobs{limit: 1 << 20, blobs: map[string]string{}}
+	pkg, err := Read(kattisPackage(), blobs.load)
+	if err != nil {
+		t.Fatal(err)
+	}
+	question := pkg.Question
+	if question.Title != "Sum" || question.TimeLimit != 2 || question.MemoryLimit != 256*1024 {
+		t.Errorf("title and limits = %q %v %v", question.Title, question.TimeLimit, question.MemoryLimit)
+	}
+	if !reflect.DeepEqual(question.Tags, []string{"math", "easy"}) {
+		t.Errorf("tags = %v", question.Tags)
+	}
+	if question.Solution == "" || question.SolutionLanguage != "py" {
+		t.Errorf("solution language = %q", question.SolutionLanguage)
+	}
+	wantSamples := []models.InputOutput{{Input: "1 2\n", Output: "3"}}
+	if !reflect.DeepEqual(question.SampleTestCases, wantSamples) {
+		t.Errorf("samples = %#v, want %#v", question.SampleTestCases, wantSamples)
+	}
+	wantGroups := []models.TestGroup{
+		{Name: "large", Points: 60, Scoring: models.ScoringProportional},
+		{Name: "small", Points: 40, Scoring: models.ScoringAllOrNothing},
+	}
+	if !reflect.DeepEqual(question.TestGroups, wantGroups) {
+		t.Errorf("groups = %#v, want %#v", question.TestGroups, wantGroups)
+	}
+	wantCases := []models.TestCase{
+		{Group: "large", IOPairs: []models.InputOutput{{Input: "1000000 1000000\n", Output: "2000000"}, {Input: "7 8\n", Output: "15"}}},
+		{Group: "small", IOPairs: []models.InputOutput{{Input: "2 2\n", Output: "4"}}},
+	}
+	if !reflect.DeepEqual(pkg.TestCases, wantCases) {
+		t.Errorf("test cases = %#v, want %#v", pkg.TestCases, wantCases)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	unlockAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	exported := &Package{
+		Question: models.Question{
+			Title:                 "Two Sum",
+			Slug:                  "two-sum",
+			Description:           "Find two numbers adding up to the target.",
+			Difficulty:            models.DifficultyEasy,
+			Tags:                  []string{"array", "hash-table"},
+			TestCaseVariableNames: "nums, target",
+			SampleTestCases:       []models.InputOutput{{Input: "[2,7,11,15]\n9", Output: "[0,1]"}},
+			CodeTemplates: map[string]models.CodeTemplate{
+				"go": {Precode: "package main\n", Template: "func twoSum(nums []int, target int) []int {\n}\n", Postcode: "func main() {}\n"},
+				"py": {Template: "class Solution:\n    pass\n"},
+			},
+			TestGroups: []models.TestGroup{
+				{Name: "small", Points: 30, Scoring: models.ScoringAllOrNothing},
+				{Name: "large", Points: 70, Dependencies: []string{"small"}, Scoring: models.ScoringProportional},
+			},
+			Solution:         "func twoSum(nums []int, target int) []int { return nil }",
+			SolutionLanguage: "go",
+			Editorial:        "Use a hash map.",
+			SolutionPolicy:   models.UnlockAfterTime,
+			UnlockAt:         &unlockAt,
+			TimeLimit:        1.5,
+			MemoryLimit:      65536,
+			Locale:           "en",
+			Translations: map[string]models.Translation{
+				"hi": {Title: "दो का योग", Description: "लक्ष्य तक जुड़ने वाली दो संख्याएँ खोजें।", Editorial: "हैश मैप का उपयोग करें।"},
+				"fr": {Title: "Somme de deux", Description: "Trouvez deux nombres dont la somme vaut la cible."},
+			},
+		},
+		TestCases: []models.TestCase{
+			{Group: "small", Visibility: models.VisibilityHidden, IOPairs: []models.InputOutput{{Input: "[3,3]\n6", Output: "[0,1]"}}},
+			{Group: "large", IOPairs: []models.InputOutput{{Input: "[1,2,3,4]\n7", Output: "[2,3]"}}},
+			{Group: "small", IOPairs: []models.InputOutput{{Input: "[1,5]\n6", Output: "[0,1]"}}},
+		},
+	}
+	tests := []struct {
+		name  string
+		limit int64
+	}{
+		{"inline data", 1 << 20},
+		{"blob data", 4},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			blobs := &memoryBlobs{limit: test.limit, blobs: map[string]string{}}
+			first := writePackage(t, exported, blobs)
+			imported := readPackage(t, first, blobs)
+			// The slug names the archive root, questions get a new one on import
+			imported.Question.Slug = exported.Question.Slug
+			second := writePackage(t, imported, blobs)
+			if !bytes.Equal(first, second) {
+				t.Error("writing the imported package gives a different archive")
+			}
+			reimported := readPackage(t, second, blobs)
+			reimported.Question.Slug = imported.Question.Slug
+			if !reflect.DeepEqual(imported, reimported) {
+				t.Errorf("reading the package again gives\n%#v\nwant\n%#v", reimported, imported)
+			}
+			if test.limit > 100 {
+				if !reflect.DeepEqual(imported.Question, exported.Question) {
+					t.Errorf("imported question\n%#v\nwant\n%#v", imported.Question, exported.Question)
+				}
+				if !reflect.DeepEqual(imported.TestCases, exported.TestCases) {
+					t.Errorf("imported test cases\n%#v\nwant\n%#v", imported.TestCases, exported.TestCases)
+				}
+			}
+		})
+	}
+}
+
+func TestKattisRoundTrip(t *testing.T) {
+	file := func(content string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(content)} }
+	tests := []struct {
+		name        string
+		problem     string
+		timeLimit   float64
+		memoryLimit float64
+		tags        []string
+	}{
+		{"legacy time multiplier", "name: Sum\nlimits:\n  time_multiplier: 5\n", DefaultTimeLimit, DefaultMemoryLimit * 1024, []string{}},
+		{"memory only", "name: Sum\nkeywords: math easy\nlimits:\n  memory: 512\n", DefaultTimeLimit, 512 * 1024, []string{"math", "easy"}},
+		{"absolute limits", "problem_format_version: 2023-07-draft\nname: {en: Sum}\nlimits:\n  time_limit: 0.5\n  memory: 64\n", 0.5, 64 * 1024, []string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fsys := fstest.MapFS{
+				"sum/problem.yaml":                    file(test.problem),
+				"sum/problem_statement/problem.en.md": file("Add two numbers.\n"),
+				"sum/data/sample/1.in":                file("1 2\n"),
+				"sum/data/sample/1.ans":               file("3\n"),
+				"sum/data/secret/1.in":                file("2 2\n"),
+				"sum/data/secret/1.ans":               file("4\n"),
+				"sum/submissions/accepted/sum.cc":     file("int main() {}\n"),
+			}
+			blobs := &memoryBlobs{limit: 1 << 20, blobs: map[string]string{}}
+			imported, err := Read(fsys, blobs.load)
+			if err != nil {
+				t.Fatal(err)
+			}
+			question := imported.Question
+			if question.Title != "Sum" || question.Solution == "" || question.SolutionLanguage != "cpp" {
+				t.Errorf("title and solution = %q %q %q", question.Title, question.Solution, question.SolutionLanguage)
+			}
+			if question.TimeLimit != test.timeLimit || question.MemoryLimit != test.memoryLimit {
+				t.Errorf("limits = %v %v, want %v %v", question.TimeLimit, question.MemoryLimit, test.timeLimit, test.memoryLimit)
+			}
+			if !reflect.DeepEqual(question.Tags, test.tags) {
+				t.Errorf("tags = %#v, want %#v", question.Tags, test.tags)
+			}
+			wantCases := []models.TestCase{{IOPairs: []models.InputOutput{{Input: "2 2\n", Output: "4"}}}}
+			if !reflect.DeepEqual(imported.TestCases, wantCases) {
+				t.Errorf("test cases = %#v, want %#v", imported.TestCases, wantCases)
+			}
+
+			imported.Question.Slug = "sum"
+			reimported := readPackage(t, writePackage(t, imported, blobs), blobs)
+			reimported.Question.Slug = imported.Question.Slug
+			if !reflect.DeepEqual(reimported, imported) {
+				t.Errorf("reading the exported package gives\n%#v\nwant\n%#v", reimported, imported)
+			}
+		})
+	}
+}
+
+func TestReadRejectsCustomValidators(t *testing.T) {
+	fsys := kattisPackage()
+	fsys["sum/output_validators/check/check.cpp"] = &fstest.MapFile{Data: []byte("int main() {}")}
+	if _, err := Read(fsys, (&memoryBlobs{limit: 1 << 20, blobs: map[string]string{}}).load); err == nil {
+		t.Error("Read accepted a package with a custom output validator")
+	}
+}
+
+func TestWriteRejectsUnsafeGroupNames(t *testing.T) {
+	tests := []struct {
+		name string
+		pkg  Package
+	}{
+		{"test group", Package{Question: models.Question{Slug: "sum", TestGroups: []models.TestGroup{{Name: "../../x", Points: 100}}}}},
+		{"test case group", Package{
+			Question:  models.Question{Slug: "sum"},
+			TestCases: []models.TestCase{{Group: "a/b", IOPairs: []models.InputOutput{{Input: "1", Output: "1"}}}},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			blobs := &memoryBlobs{limit: 1 << 20, blobs: map[string]string{}}
+			if err := Write(io.Discard, &test.pkg, blobs.open); err == nil {
+				t.Error("Write accepted a group name that is not a directory name")
+			}
+		})
+	}
+}
+
+func TestWriteKeepsOnlyTranslatedText(t *testing.T) {
+	updatedAt := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	tests := []struct {
+		name         string
+		translations map[string]models.Translation
+		want         map[string]models.Translation
+	}{
+		{"no translations", nil, nil},
+		{"empty translations", map[string]models.Translation{}, nil},
+		{
+			"bookkeeping dropped",
+			map[string]models.Translation{"fr": {
+				Title: "Somme", Description: "Additionnez.", Editorial: "Facile.",
+				DescriptionHTML: "<p>Additionnez.</p>", EditorialHTML: "<p>Facile.</p>",
+				SourceHash: "abc", UpdatedBy: "admin", UpdatedAt: updatedAt,
+			}},
+			map[string]models.Translation{"fr": {Title: "Somme", Description: "Additionnez.", Editorial: "Facile."}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			blobs := &memoryBlobs{limit: 1 << 20, blobs: map[string]string{}}
+			pkg := &Package{Question: models.Question{Title: "Sum", Slug: "sum", Locale: "de", Translations: test.translations}}
+			imported := readPackage(t, writePackage(t, pkg, blobs), blobs)
+			if !reflect.DeepEqual(imported.Question.Translations, test.want) {
+				t.Errorf("translations = %#v, want %#v", imported.Question.Translations, test.want)
+			}
+			if imported.Question.Locale != "de" {
+				t.Errorf("locale = %q, want de", imported.Question.Locale)
+			}
+		})
+	}
+}
+
+func TestAttachments(t *testing.T) {
+	blobs := &memoryBlobs{limit: 1 << 20, blobs: map[string]string{"aa": "first graph", "bb": "second graph", "cc": "notes"}}
+	exported := &Package{
+		Question: models.Question{Title: "Graphs", Slug: "graphs", Description: "![graph](/attachment?id=7)"},
+		Attachments: []Attachment{
+			{ID: "7", Name: "graph.png", Size: 11, Blob: "aa"},
+			{ID: "8", Name: "graph.png", Size: 12, Blob: "bb"},
+			{ID: "9", Name: "notes.pdf", Size: 5, Blob: "cc"},
+		},
+	}
+	kattis := kattisPackage()
+	kattis["sum/attachments/sum.py"] = &fstest.MapFile{Data: []byte("print(1)")}
+	tests := []struct {
+		name string
+		read func(t *testing.T) *Package
+		want []Attachment
+		data []string
+	}{
+		{
+			"our package",
+			func(t *testing.T) *Package { return readPackage(t, writePackage(t, exported, blobs), blobs) },
+			[]Attachment{{ID: "7", Name: "graph.png", Size: 11}, {ID: "8", Name: "graph.png", Size: 12}, {ID: "9", Name: "notes.pdf", Size: 5}},
+			[]string{"first graph", "second graph", "notes"},
+		},
+		{
+			"kattis package",
+			func(t *testing.T) *Package {
+				pkg, err := Read(kattis, blobs.load)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return pkg
+			},
+			[]Attachment{{Name: "sum.py", Size: 8}},
+			[]string{"print(1)"},
+		},
+		{
+			"no attachments",
+			func(t *testing.T) *Package {
+				pkg, err := Read(kattisPackage(), blobs.load)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return pkg
+			},
+			nil,
+			nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pkg := test.read(t)
+			var data []string
+			for i, attachment := range pkg.Attachments {
+				content, err := attachment.Open()
+				if err != nil {
+					t.Fatal(err)
+				}
+				read, err := io.ReadAll(content)
+				content.Close()
+				if err != nil {
+					t.Fatal(err)
+				}
+				data = append(data, string(read))
+				pkg.Attachments[i].Open = nil
+			}
+			if !reflect.DeepEqual(pkg.Attachments, test.want) {
+				t.Errorf("attachments = %#v, want %#v", pkg.Attachments, test.want)
+			}
+			if !reflect.DeepEqual(data, test.data) {
+				t.Errorf("attachment data = %q, want %q", data, test.data)
+			}
+		})
+	}
+}
+
+func TestReadRejectsMissingAttachments(t *testing.T) {
+	fsys := kattisPackage()
+	fsys["sum/code_compiler/question.json"] = &fstest.MapFile{Data: []byte(`{"attachments": [{"id": "1", "name": "a.png", "file": "attachments/a.png"}]}`)}
+	if _, err := Read(fsys, (&memoryBlobs{limit: 1 << 20, blobs: map[string]string{}}).load); err == nil {
+		t.Error("Read accepted a package listing a missing attachment")
+	}
+}
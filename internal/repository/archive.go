@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"code-compiler/db"
+	"code-compiler/internal/models"
+	"code-compiler/internal/search"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// PurgeReport counts the documents removed by a hard purge
+type PurgeReport struct {
+	Questions   int64 `json:"questions"`
+	TestCases   int64 `json:"testCases"`
+	Submissions int64 `json:"submissions"`
+	Revisions   int64 `json:"revisions"`
+	RejudgeJobs int64 `json:"rejudgeJobs"`
+	Performance int64 `json:"performance"` // Runtime and memory distributions
+	Attachments int64 `json:"attachments"`
+}
+
+// ArchiveQuestion soft deletes a question. It disappears for users and the
+// judge, and its test case batches are archived with it.
+func (r *Question) ArchiveQuestion(questionID string, author string) (*models.Question, error) {
+	question, err := r.GetQuestionById(questionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := question.ValidateLifecycleTransition(models.LifecycleArchived, nil, time.Now()); err != nil {
+		return nil, err
+	}
+	// Mongo keeps milliseconds, the batches are matched on this exact time on restore
+	archivedAt := time.Now().Truncate(time.Millisecond)
+	changes := bson.M{
+		"lifecycle":    models.LifecycleArchived,
+		"publishAt":    nil,
+		"archivedFrom": question.CurrentLifecycle(),
+		"archivedAt":   archivedAt,
+	}
+	archived, err := r.updateQuestionContent(questionID, changes, author, "archived")
+	if err != nil {
+		return nil, err
+	}
+	_, err = db.TestCasesCollection.UpdateMany(context.TODO(), bson.M{"questionId": questionID, "archivedAt": nil}, bson.M{
+		"$set": bson.M{"archivedAt": archivedAt, "archivedBy": author},
+	})
+	if err != nil {
+		return archived, fmt.Errorf("question archived but its test cases were not: %v", err)
+	}
+	return archived, nil
+}
+
+// RestoreQuestion brings an archived question back as a draft, or as
+// published when it was published before. Batches archived on their own stay archived.
+func (r *Question) RestoreQuestion(questionID string, author string) (*models.Question, error) {
+	question, err := r.GetQuestionById(questionID)
+	if err != nil {
+		return nil, err
+	}
+	if question.CurrentLifecycle() != models.LifecycleArchived {
+		return nil, errors.New("the question is not archived")
+	}
+	state := models.LifecycleDraft
+	if question.ArchivedFrom == models.LifecyclePublished {
+		state = models.LifecyclePublished
+	}
+	return r.SetLifecycle(questionID, state, nil, author)
+}
+
+// leaveArchive restores the batches archived together with the question
+func leaveArchive(question *models.Question) error {
+	if question.ArchivedAt == nil {
+		return nil
+	}
+	_, err := db.TestCasesCollection.UpdateMany(context.TODO(), bson.M{"questionId": question.ID, "archivedAt": *question.ArchivedAt}, bson.M{
+		"$unset": bson.M{"archivedAt": "", "archivedBy": ""},
+	})
+	return err
+}
+
+// PurgeQuestion permanently deletes an archived question with its test cases,
+// submissions, revisions, rejudge jobs and attachments. Test data blobs are content
+// addressed and may be shared, so they are kept.
+func (r *Question) PurgeQuestion(questionID string) (*PurgeReport, error) {
+	question, err := r.GetQuestionById(questionID)
+	if err != nil {
+		return nil, err
+	}
+	if question.CurrentLifecycle() != models.LifecycleArchived {
+		return nil, errors.New("only archived questions can be purged, archive it first")
+	}
+	return purgeQuestionData(questionID)
+}
+
+// purgeQuestionData deletes the question with everything stored for it
+func purgeQuestionData(questionID string) (*PurgeReport, error) {
+	if err := unlinkTestCases(bson.M{"questionId": questionID}); err != nil {
+		return nil, err
+	}
+	report := &PurgeReport{}
+	deletions := []struct {
+		collection *mongo.Collection
+		filter     bson.M
+		count      *int64
+	}{
+		{db.TestCasesCollection, bson.M{"questionId": questionID}, &report.TestCases},
+		{db.CodeSubmissionCollection, bson.M{"question": questionID}, &report.Submissions},
+		{db.RevisionsCollection, bson.M{"questionId": questionID}, &report.Revisions},
+		{db.RejudgeJobsCollection, bson.M{"request.questionId": questionID}, &report.RejudgeJobs},
+		{db.PerformanceCollection, bson.M{"questionId": questionID}, &report.Performance},
+		{db.AttachmentsCollection, bson.M{"questionId": questionID}, &report.Attachments},
+		{db.QuestionsCollection, bson.M{"_id": questionID}, &report.Questions},
+	}
+	for _, deletion := range deletions {
+		result, err := deletion.collection.DeleteMany(context.TODO(), deletion.filter)
+		if err != nil {
+			return report, err
+		}
+		*deletion.count = result.DeletedCount
+	}
+	search.Questions.Remove(questionID)
+	return report, nil
+}
+
+// ArchiveTestCase soft deletes a test case batch, the judge stops using it
+func (r *Question) ArchiveTestCase(testCaseId string, author string) (*models.TestCase, error) {
+	result, err := db.TestCasesCollection.UpdateOne(context.TODO(), bson.M{"_id": testCaseId, "archivedAt": nil}, bson.M{
+		"$set": bson.M{"archivedAt": time.Now(), "archivedBy": author, "updatedAt": time.Now()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, errors.New("test case not found or already archived")
+	}
+	return r.GetTestCasesById(testCaseId)
+}
+
+// RestoreTestCase brings an archived batch back. Batches of an archived
+// question come back with the question.
+func (r *Question) RestoreTestCase(testCaseId string) (*models.TestCase, error) {
+	testCase, err := r.GetTestCasesById(testCaseId)
+	if err != nil {
+		return nil, err
+	}
+	if testCase.ArchivedAt == nil {
+		return nil, errors.New("the test case is not archived")
+	}
+	question, err := r.GetQuestionById(testCase.QuestionID)
+	if err != nil {
+		return nil, fmt.Errorf("question %s of the test case: %v", testCase.QuestionID, err)
+	}
+	if question.CurrentLifecycle() == models.LifecycleArchived {
+		return nil, errors.New("the question is archived, restore the question first")
+	}
+	if _, err := db.TestCasesCollection.UpdateOne(context.TODO(), bson.M{"_id": testCaseId}, bson.M{
+		"$unset": bson.M{"archivedAt": "", "archivedBy": ""},
+		"$set":   bson.M{"updatedAt": time.Now()},
+	}); err != nil {
+		return nil, err
+	}
+	return r.GetTestCasesById(testCaseId)
+}
+
+// PurgeTestCase permanently deletes an archived test case batch
+func (r *Question) PurgeTestCase(testCaseId string) error {
+	if err := unlinkTestCases(bson.M{"_id": testCaseId, "archivedAt": bson.M{"$ne": nil}}); err != nil {
+		return err
+	}
+	result, err := db.TestCasesCollection.DeleteOne(context.TODO(), bson.M{"_id": testCaseId, "archivedAt": bson.M{"$ne": nil}})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errors.New("test case not found or not archived, archive it first")
+	}
+	return nil
+}
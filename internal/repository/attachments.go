@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"bytes"
+	"code-compiler/db"
+	"code-compiler/internal/models"
+	"code-compiler/internal/storage"
+	"code-compiler/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxAttachmentsPerQuestion keeps a statement from turning into a file share
+const maxAttachmentsPerQuestion = 50
+
+// AddAttachment validates an uploaded image or file by its content and stores
+// it for the question. The size comes from the upload and is checked again
+// against what was stored.
+func (r *Question) AddAttachment(questionID string, name string, content io.Reader, size int64, uploadedBy string) (*models.Attachment, error) {
+	if storage.Blobs == nil {
+		return nil, errors.New("blob store is not configured")
+	}
+	question, err := r.GetQuestionById(questionID)
+	if err != nil {
+		return nil, err
+	}
+	if question.CurrentLifecycle() == models.LifecycleArchived {
+		return nil, errors.New("the question is archived")
+	}
+	count, err := db.AttachmentsCollection.CountDocuments(context.TODO(), bson.M{"questionId": questionID})
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxAttachmentsPerQuestion {
+		return nil, errors.New("the question has too many attachments, delete some first")
+	}
+	id, err := utils.GetNextSequence("attachment")
+	if err != nil {
+		return nil, errors.New("got error while creating id")
+	}
+	return storeAttachment(id, questionID, name, content, size, uploadedBy)
+}
+
+// storeAttachment checks the content of an attachment and stores it under the
+// id. Content over the limit of its kind is rejected before it is stored.
+func storeAttachment(id string, questionID string, name string, content io.Reader, size int64, uploadedBy string) (*models.Attachment, error) {
+	// The declared type of an upload cannot be trusted, sniff the content
+	head := make([]byte, 512)
+	n, err := io.ReadFull(content, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+	contentType := http.DetectContentType(head)
+	kind, err := models.AttachmentKind(contentType, size)
+	if err != nil {
+		return nil, err
+	}
+	limit := int64(models.MaxFileSize)
+	if kind == models.AttachmentImage {
+		limit = models.MaxImageSize
+	}
+	hash, stored, err := storage.Blobs.PutLimited(io.MultiReader(bytes.NewReader(head), content), limit)
+	if errors.Is(err, storage.ErrTooLarge) {
+		return nil, fmt.Errorf("the %s is over the limit of %d bytes", kind, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := &models.Attachment{
+		ID:          id,
+		QuestionID:  questionID,
+		Name:        models.SanitizeAttachmentName(name),
+		Kind:        kind,
+		ContentType: contentType,
+		Size:        stored,
+		Blob:        hash,
+		UploadedBy:  uploadedBy,
+		CreatedAt:   time.Now(),
+	}
+	if _, err := db.AttachmentsCollection.InsertOne(context.TODO(), attachment); err != nil {
+		return nil, err
+	}
+	attachment.URL = models.AttachmentURL(attachment.ID)
+	return attachment, nil
+}
+
+// newAttachmentIds gives every attachment a new id for a copy of its
+// question, mapped from the old one
+func newAttachmentIds(attachments []models.Attachment) (map[string]string, error) {
+	ids := map[string]string{}
+	for _, attachment := range attachments {
+		id, err := utils.GetNextSequence("attachment")
+		if err != nil {
+			return nil, errors.New("got error while creating id")
+		}
+		ids[attachment.ID] = id
+	}
+	return ids, nil
+}
+
+// relinkQuestion points the statement, editorial and translations of a
+// question copy to the copies of its attachments
+func relinkQuestion(question *models.Question, ids map[string]string) {
+	question.Description = models.RelinkAttachments(question.Description, ids)
+	question.Editorial = models.RelinkAttachments(question.Editorial, ids)
+	if question.Translations == nil {
+		return
+	}
+	translations := make(map[string]models.Translation, len(question.Translations))
+	for locale, translation := range question.Translations {
+		translation.Description = models.RelinkAttachments(translation.Description, ids)
+		translation.Editorial = models.RelinkAttachments(translation.Editorial, ids)
+		translations[locale] = translation
+	}
+	question.Translations = translations
+}
+
+// GetAttachments lists the attachments of a question, oldest first
+func (r *Question) GetAttachments(questionID string) ([]models.Attachment, error) {
+	attachments := []models.Attachment{}
+	cursor, err := db.AttachmentsCollection.Find(context.TODO(), bson.M{"questionId": questionID}, options.Find().SetSort(bson.M{"createdAt": 1}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(context.TODO(), &attachments); err != nil {
+		return nil, err
+	}
+	for i := range attachments {
+		attachments[i].URL = models.AttachmentURL(attachments[i].ID)
+	}
+	return attachments, nil
+}
+
+func (r *Question) GetAttachment(id string) (*models.Attachment, error) {
+	var attachment models.Attachment
+	err := db.AttachmentsCollection.FindOne(context.TODO(), bson.M{"_id": id}).Decode(&attachment)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.New("attachment not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	attachment.URL = models.AttachmentURL(attachment.ID)
+	return &attachment, nil
+}
+
+// OpenAttachment returns the attachment with its content, when the question
+// can be seen: published for users, any state but archived for admins
+func (r *Question) OpenAttachment(id string, isAdmin bool) (*models.Attachment, *os.File, error) {
+	attachment, err := r.GetAttachment(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	question, err := r.GetQuestionById(attachment.QuestionID)
+	if err != nil || question.CurrentLifecycle() == models.LifecycleArchived || !isAdmin && !question.IsPublished() {
+		return nil, nil, errors.New("attachment not found")
+	}
+	if storage.Blobs == nil {
+		return nil, nil, errors.New("blob store is not configured")
+	}
+	file, err := storage.Blobs.Open(attachment.Blob)
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachment, file, nil
+}
+
+// DeleteAttachment unlinks an attachment from its question. The blob is kept,
+// content addressed blobs may be shared.
+func (r *Question) DeleteAttachment(id string) error {
+	result, err := db.AttachmentsCollection.DeleteOne(context.TODO(), bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return errors.New("attachment not found")
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"bytes"
+	"code-compiler/internal/models"
+	"code-compiler/internal/storage"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRelinkQuestion(t *testing.T) {
+	ids := map[string]string{"3": "30"}
+	origin := models.Question{
+		Description: "![tree](/attachment?id=3) ![other](/attachment?id=4)",
+		Editorial:   "See /attachment?id=3",
+		Translations: map[string]models.Translation{
+			"fr": {Title: "Arbre", Description: "![arbre](/attachment?id=3)", Editorial: "Voir /attachment?id=3"},
+		},
+	}
+	clone := origin
+	relinkQuestion(&clone, ids)
+	want := models.Question{
+		Description: "![tree](/attachment?id=30) ![other](/attachment?id=4)",
+		Editorial:   "See /attachment?id=30",
+		Translations: map[string]models.Translation{
+			"fr": {Title: "Arbre", Description: "![arbre](/attachment?id=30)", Editorial: "Voir /attachment?id=30"},
+		},
+	}
+	if !reflect.DeepEqual(clone, want) {
+		t.Errorf("relinked question = %#v, want %#v", clone, want)
+	}
+	if origin.Translations["fr"].Description != "![arbre](/attachment?id=3)" {
+		t.Error("relinking the copy changed the translations of the original")
+	}
+}
+
+func TestStoreAttachmentRejectsOversizedContent(t *testing.T) {
+	store, err := storage.NewBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := storage.Blobs
+	storage.Blobs = store
+	defer func() { storage.Blobs = previous }()
+
+	png := []byte("\x89PNG\r\n\x1a\n")
+	tests := []struct {
+		name         string
+		content      []byte
+		declaredSize int64
+	}{
+		{"declared too large", png, models.MaxImageSize + 1},
+		{"larger than declared", append(png, bytes.Repeat([]byte{0}, models.MaxImageSize)...), 100},
+		{"unsupported type", []byte("<html><script></script></html>"), 30},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := storeAttachment("1", "1", "image.png", bytes.NewReader(test.content), test.declaredSize, "admin"); err == nil {
+				t.Fatal("storeAttachment accepted the content")
+			}
+			var files []string
+			filepath.WalkDir(store.Root, func(path string, entry os.DirEntry, err error) error {
+				if err == nil && !entry.IsDir() {
+					files = append(files, strings.TrimPrefix(path, store.Root))
+				}
+				return err
+			})
+			if len(files) != 0 {
+				t.Errorf("the rejected attachment left %v in the blob store", files)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"code-compiler/db"
+	"code-compiler/internal/models"
+	"code-compiler/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CloneQuestion creates a draft copy of a question under a new ID and slug.
+// Attachments are copied with it, per-user progress, statistics and history
+// stay with the original.
+func (r *Question) CloneQuestion(questionID string, cloneOptions models.CloneOptions, author string) (*models.Question, []models.TestCase, error) {
+	origin, err := r.GetQuestionById(questionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cloneOptions.LinkTestCases && !cloneOptions.IncludeTestCases {
+		return nil, nil, errors.New("linkTestCases needs includeTestCases")
+	}
+	clone := *origin
+	clone.Title = cloneOptions.Title
+	if clone.Title == "" {
+		clone.Title = origin.Title + " (copy)"
+	}
+	clone.Slug = ""
+	clone.Users = nil
+	clone.UserStatus = ""
+	clone.SubmissionCount = 0
+	clone.SuccessRate = 0
+	clone.Stats = nil
+	clone.ArchivedFrom = ""
+	clone.ArchivedAt = nil
+	clone.ClonedFrom = origin.ID
+	clone.CreatedBy = author
+	// The copy gets its own attachment records, its statement links to them
+	attachments, err := r.GetAttachments(questionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	attachmentIds, err := newAttachmentIds(attachments)
+	if err != nil {
+		return nil, nil, err
+	}
+	relinkQuestion(&clone, attachmentIds)
+	createdQuestion, err := r.CreateQuestion(&clone)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, attachment := range attachments {
+		if err := cloneAttachment(attachment, attachmentIds[attachment.ID], createdQuestion.ID); err != nil {
+			return createdQuestion, nil, fmt.Errorf("question %s was cloned but attachment %s failed: %v", createdQuestion.ID, attachment.ID, err)
+		}
+	}
+	if !cloneOptions.IncludeTestCases {
+		return createdQuestion, nil, nil
+	}
+	var batches []models.TestCase
+	cursor, err := db.TestCasesCollection.Find(context.TODO(), bson.M{
+		"questionId":   questionID,
+		"reviewStatus": bson.M{"$ne": models.ReviewRejected},
+		"archivedAt":   nil,
+	}, options.Find().SetSort(bson.M{"createdAt": 1}))
+	if err != nil {
+		return createdQuestion, nil, err
+	}
+	if err = cursor.All(context.TODO(), &batches); err != nil {
+		return createdQuestion, nil, err
+	}
+	if err := resolveLinkedTestCases(batches); err != nil {
+		return createdQuestion, nil, err
+	}
+	var testCases []models.TestCase
+	for _, batch := range batches {
+		testCase, err := cloneTestCase(&batch, createdQuestion.ID, cloneOptions.LinkTestCases, author)
+		if err != nil {
+			return createdQuestion, testCases, fmt.Errorf("question %s was cloned but test case %s failed: %v", createdQuestion.ID, batch.ID, err)
+		}
+		testCases = append(testCases, *testCase)
+	}
+	return createdQuestion, testCases, nil
+}
+
+// cloneAttachment copies an attachment record to another question. Blobs are
+// content addressed, the copy shares the blob of the original.
+func cloneAttachment(origin models.Attachment, id string, questionID string) error {
+	attachment := origin
+	attachment.ID = id
+	attachment.QuestionID = questionID
+	attachment.CreatedAt = time.Now()
+	_, err := db.AttachmentsCollection.InsertOne(context.TODO(), attachment)
+	return err
+}
+
+// cloneTestCase copies a batch to another question. The copy keeps the review
+// state of the original, it holds the same reviewed data.
+func cloneTestCase(origin *models.TestCase, questionID string, link bool, author string) (*models.TestCase, error) {
+	seq, err := utils.GetNextSequence("testCase")
+	if err != nil {
+		return nil, errors.New("got error while creating id")
+	}
+	now := time.Now()
+	reviewStatus := origin.ReviewStatus
+	if reviewStatus == "" {
+		// Batches from before review states existed
+		reviewStatus = models.ReviewPending
+		if origin.Approved {
+			reviewStatus = models.ReviewApproved
+		}
+	}
+	testCase := models.TestCase{
+		ID:           seq,
+		QuestionID:   questionID,
+		IOPairs:      origin.IOPairs,
+		Group:        origin.Group,
+		Visibility:   origin.Visibility,
+		Approved:     origin.Approved,
+		ReviewStatus: reviewStatus,
+		Reviews: []models.TestCaseReview{{
+			Status:     reviewStatus,
+			ReviewerID: author,
+			Comment:    "cloned from test case " + origin.ID,
+			CreatedAt:  now,
+		}},
+		CreatedBy: author,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if link {
+		// Link to the batch holding the data, clones of clones share it too
+		testCase.LinkedFrom = origin.ID
+		if origin.LinkedFrom != "" {
+			testCase.LinkedFrom = origin.LinkedFrom
+		}
+		testCase.IOPairs = []models.InputOutput{}
+	}
+	if _, err := db.TestCasesCollection.InsertOne(context.TODO(), testCase); err != nil {
+		return nil, err
+	}
+	if link {
+		testCase.IOPairs = origin.IOPairs
+	}
+	return &testCase, nil
+}
+
+// resolveLinkedTestCases fills in the pairs of batches still linked to the
+// batch they were cloned from
+func resolveLinkedTestCases(testCases []models.TestCase) error {
+	var linkedIds []string
+	for _, testCase := range testCases {
+		if testCase.LinkedFrom != "" {
+			linkedIds = append(linkedIds, testCase.LinkedFrom)
+		}
+	}
+	if len(linkedIds) == 0 {
+		return nil
+	}
+	var origins []models.TestCase
+	cursor, err := db.TestCasesCollection.Find(context.TODO(), bson.M{"_id": bson.M{"$in": linkedIds}}, options.Find().SetProjection(bson.M{"ioPairs": 1}))
+	if err != nil {
+		return err
+	}
+	if err = cursor.All(context.TODO(), &origins); err != nil {
+		return err
+	}
+	pairs := map[string][]models.InputOutput{}
+	for _, origin := range origins {
+		pairs[origin.ID] = origin.IOPairs
+	}
+	for i := range testCases {
+		if testCases[i].LinkedFrom == "" {
+			continue
+		}
+		originPairs, ok := pairs[testCases[i].LinkedFrom]
+		if !ok {
+			return fmt.Errorf("test case %s is linked to missing test case %s", testCases[i].ID, testCases[i].LinkedFrom)
+		}
+		testCases[i].IOPairs = originPairs
+	}
+	return nil
+}
+
+// unlinkTestCases gives the clones linked to the batches matched by the filter
+// their own copy of the pairs, before those batches are deleted
+func unlinkTestCases(filter bson.M) error {
+	var origins []models.TestCase
+	cursor, err := db.TestCasesCollection.Find(context.TODO(), filter, options.Find().SetProjection(bson.M{"ioPairs": 1}))
+	if err != nil {
+		return err
+	}
+	if err = cursor.All(context.TODO(), &origins); err != nil {
+		return err
+	}
+	for _, origin := range origins {
+		if _, err := db.TestCasesCollection.UpdateMany(context.TODO(), bson.M{"linkedFrom": origin.ID}, bson.M{
+			"$set":   bson.M{"ioPairs": origin.IOPairs, "updatedAt": time.Now()},
+			"$unset": bson.M{"linkedFrom": ""},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -8,7 +8,9 @@ import (
 	"code-compiler/internal/models"
 	"code-compiler/internal/utils"
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"math/rand"
 	"os"
 	"os/exec"
@@ -21,6 +23,9 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// errTimedOut marks a test case that ran past the time limit
+var errTimedOut = errors.New("timed out")
+
 // CodeRunner struct to execute code
 type CodeRunner struct {
 	Question *Question // Add a reference to Question
@@ -47,8 +52,14 @@ func compileCode(codePath, language string) (string, error) {
 		outputFileName = filepath.Join(filepath.Dir(codePath), fileBaseNameWithoutExt+".out")
 		cmd = exec.Command("g++", codePath, "-o", outputFileName)
 	case "java":
-		outputFileName = fileBaseNameWithoutExt
-		cmd = exec.Command("javac", codePath)
+		// Every run gets its own class directory, the harness classes and
+		// Solution have the same names in every program
+		classDir, err := os.MkdirTemp(filepath.Dir(codePath), "classes")
+		if err != nil {
+			return "", fmt.Errorf("compilation failed: %v", err)
+		}
+		outputFileName = filepath.Join(classDir, fileBaseNameWithoutExt)
+		cmd = exec.Command("javac", "-d", classDir, codePath)
 	case "go":
 		outputFileName = filepath.Join(filepath.Dir(codePath), fileBaseNameWithoutExt+".out")
 		cmd = exec.Command("go", "build", "-o", outputFileName, codePath)
@@ -62,6 +73,9 @@ func compileCode(codePath, language string) (string, error) {
 
 	// Run the command and check for errors
 	if err := cmd.Run(); err != nil {
+		if language == "java" {
+			removeCompiled(outputFileName, language)
+		}
 		return "", fmt.Errorf("compilation failed: %v", stderr.String())
 	}
 
@@ -76,7 +90,7 @@ func getCommandForLanguage(compiledFilePath, language string) (*exec.Cmd, error)
 	case "cpp", "c":
 		return exec.Command(compiledFilePath), nil
 	case "java":
-		return exec.Command("java", "-cp", "codeFiles", compiledFilePath), nil
+		return exec.Command("java", "-cp", filepath.Dir(compiledFilePath), filepath.Base(compiledFilePath)), nil
 	case "js":
 		return exec.Command("node", compiledFilePath), nil
 	case "go":
@@ -86,98 +100,59 @@ func getCommandForLanguage(compiledFilePath, language string) (*exec.Cmd, error)
 	}
 }
 
-// RunTestCases executes the compiled code with the provided test cases
-func runTestCases(compiledFilePath string, testCases []models.InputOutput, language string) ([]commontypes.TestResult, error) {
-	var results []commontypes.TestResult
-	for i, testCase := range testCases {
-		cmd, err := getCommandForLanguage(compiledFilePath, language)
-		if err != nil {
-			return results, err
-		}
-		cmd.Stdin = bytes.NewBufferString(testCase.Input)
+// runTestCase executes the compiled code on a single test case
+func runTestCase(compiledFilePath string, testCase models.InputOutput, testCaseNumber int, language string) (*commontypes.TestResult, error) {
+	if testCase.InputBlob != "" || testCase.OutputBlob != "" {
+		return runStreamedTestCase(compiledFilePath, testCase, testCaseNumber, language)
+	}
+	cmd, err := getCommandForLanguage(compiledFilePath, language)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdin = bytes.NewBufferString(testCase.Input)
 
-		var outputBytes []byte
-		errChan := make(chan error, 1)
-		go func() {
-			output, err := cmd.CombinedOutput()
-			outputBytes = output
-			errChan <- err
-		}()
+	var outputBytes []byte
+	errChan := make(chan error, 1)
+	go func() {
+		output, err := cmd.CombinedOutput()
+		outputBytes = output
+		errChan <- err
+	}()
 
-		select {
-		case err := <-errChan:
-			if err != nil {
-				return results, fmt.Errorf("%s", string(outputBytes))
-			}
-		case <-time.After(2 * time.Second):
-			return results, fmt.Errorf("test case %d timed out", i+1)
+	select {
+	case err := <-errChan:
+		if err != nil {
+			return nil, fmt.Errorf("%s", string(outputBytes))
 		}
+	case <-time.After(2 * time.Second):
+		return nil, fmt.Errorf("test case %d %w", testCaseNumber, errTimedOut)
+	}
 
-		actualOutput := string(bytes.TrimSpace(outputBytes))
-		expectedOutput := testCase.Output
-		passed := actualOutput == expectedOutput
-
-		results = append(results, commontypes.TestResult{
-			TestCaseNumber: i + 1,
-			Input:          testCase.Input,
-			ExpectedOutput: expectedOutput,
-			ActualOutput:   actualOutput,
-			Passed:         passed,
-		})
+	// Both sides are trimmed like sameOutput does, answer files end with a newline
+	actualOutput := string(bytes.TrimSpace(outputBytes))
+	expectedOutput := strings.TrimSpace(testCase.Output)
+	result := &commontypes.TestResult{
+		TestCaseNumber: testCaseNumber,
+		Input:          testCase.Input,
+		ExpectedOutput: expectedOutput,
+		ActualOutput:   actualOutput,
+		Passed:         actualOutput == expectedOutput,
 	}
-	return results, nil
+	recordUsage(result, cmd.ProcessState)
+	return result, nil
 }
 
-// RunAllTestCases runs test cases and stops on the first failure
-func runAllTestCases(compiledFilePath string, testCases []models.InputOutput, language string) (*commontypes.TestResult, int, error) {
-	numberOfPassedTests := 0
+// RunTestCases executes the compiled code with the provided test cases
+func runTestCases(compiledFilePath string, testCases []models.InputOutput, language string) ([]commontypes.TestResult, error) {
+	var results []commontypes.TestResult
 	for i, testCase := range testCases {
-		// Create a command for the language
-		cmd, err := getCommandForLanguage(compiledFilePath, language)
+		result, err := runTestCase(compiledFilePath, testCase, i+1, language)
 		if err != nil {
-			return nil, numberOfPassedTests, err
-		}
-		cmd.Stdin = bytes.NewBufferString(testCase.Input)
-		// Execute the command and handle output
-		var outputBytes []byte
-		errChan := make(chan error, 1)
-		go func() {
-			output, err := cmd.CombinedOutput()
-			outputBytes = output
-			errChan <- err
-		}()
-
-		select {
-		case err := <-errChan:
-			if err != nil {
-				return nil, numberOfPassedTests, fmt.Errorf("%s", string(outputBytes))
-			}
-		case <-time.After(2 * time.Second):
-			return nil, numberOfPassedTests, fmt.Errorf("test case %d timed out", i+1)
-		}
-
-		actualOutput := string(bytes.TrimSpace(outputBytes))
-		expectedOutput := testCase.Output
-		passed := actualOutput == expectedOutput
-
-		// Create a TestResult for the current test case
-		result := &commontypes.TestResult{
-			TestCaseNumber: i + 1,
-			Input:          testCase.Input,
-			ExpectedOutput: expectedOutput,
-			ActualOutput:   actualOutput,
-			Passed:         passed,
-		}
-
-		// If the test case failed, return immediately
-		if !passed {
-			return result, numberOfPassedTests, nil
+			return results, err
 		}
-
-		numberOfPassedTests++
+		results = append(results, *result)
 	}
-
-	return nil, numberOfPassedTests, nil
+	return results, nil
 }
 
 func generateRandom(upperRange int) int {
@@ -223,12 +198,26 @@ func fileRemoving(filename string) bool {
 	return true
 }
 
+// removeCompiled removes what compileCode made, the class directory of a Java program
+func removeCompiled(compiledFilePath string, language string) {
+	if language != "java" {
+		fileRemoving(compiledFilePath)
+		return
+	}
+	if err := os.RemoveAll(filepath.Dir(compiledFilePath)); err != nil {
+		fmt.Println("Error removing classes:", err)
+	}
+}
+
 // Execute runs the code for either testing or submission
 func (r *CodeRunner) ExecuteTest(data commontypes.CodeRunnerType) ([]commontypes.TestResult, error) {
 	question, err := r.Question.GetQuestionById(data.QuestionId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve question: %v", err)
 	}
+	if question.CurrentLifecycle() == models.LifecycleArchived || !data.IsAdmin && !question.IsPublished() {
+		return nil, fmt.Errorf("failed to retrieve question: %v", mongo.ErrNoDocuments)
+	}
 	codeFilePath := fileWriter(data.Code, data.Language, question.CodeTemplates[data.Language])
 	if codeFilePath == "" {
 		return nil, fmt.Errorf("file creation failed")
@@ -241,13 +230,7 @@ func (r *CodeRunner) ExecuteTest(data commontypes.CodeRunnerType) ([]commontypes
 	if err != nil {
 		return nil, fmt.Errorf("code compilation failed: %v", err)
 	}
-	var fileRemoveName string
-	if data.Language == "java" {
-		fileRemoveName = "codeFiles/" + compiledFilePath + ".class"
-	} else {
-		fileRemoveName = compiledFilePath
-	}
-	defer fileRemoving(fileRemoveName)
+	defer removeCompiled(compiledFilePath, data.Language)
 	results, err := runTestCases(compiledFilePath, question.SampleTestCases, data.Language)
 	if err != nil {
 		return nil, err
@@ -263,75 +246,119 @@ func SaveUserSubmissionData(data *models.CodeSubmission) {
 	return
 }
 
+// SaveUserIdInQuestion records the user's status on the question. A solved
+// status is never downgraded back to attempted.
 func SaveUserIdInQuestion(questionId string, userId string, status string) error {
 	filter := bson.M{"_id": questionId}
+	if status != "solved" {
+		filter["users."+userId] = bson.M{"$ne": "solved"}
+	}
 	update := bson.M{
 		"$set": bson.M{
 			"users." + userId: status,
 		},
 	}
-	result := db.QuestionsCollection.FindOneAndUpdate(context.TODO(), filter, update)
-	if err := result.Err(); err != nil {
-		if err == mongo.ErrNoDocuments {
-			return fmt.Errorf("no question found with ID: %s", questionId)
-		}
+	result, err := db.QuestionsCollection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
 		return fmt.Errorf("error updating question: %v", err)
 	}
+	if result.MatchedCount == 0 && status == "solved" {
+		return fmt.Errorf("no question found with ID: %s", questionId)
+	}
 	return nil
 }
 
-func (r *CodeRunner) ExecuteSubmit(data commontypes.CodeRunnerType) (*commontypes.TestResult, int, int, error) {
+// judgeOutcome is the result of running a submission against the hidden test cases.
+// Verdict is empty when the code could not be judged at all.
+type judgeOutcome struct {
+	FailedCase *commontypes.TestResult
+	Passed     int
+	Total      int
+	Score      float64
+	MaxScore   float64
+	Groups     []commontypes.GroupResult
+	Verdict    string
+	Err        error
+	Runtime    float64 // Slowest test case, CPU time in ms
+	Memory     float64 // Largest peak memory of a test case in kb
+}
+
+// judgeCode compiles the code with the question's templates and scores it on the approved test cases
+func (r *CodeRunner) judgeCode(question *models.Question, code string, language string) judgeOutcome {
+	codeFilePath := fileWriter(code, language, question.CodeTemplates[language])
+	if codeFilePath == "" {
+		return judgeOutcome{Err: fmt.Errorf("file creation failed")}
+	}
+	compiledFilePath, err := compileCode(codeFilePath, language)
+	if err != nil {
+		return judgeOutcome{Verdict: models.VerdictCompilationError, Err: fmt.Errorf("code compilation failed: %v", err)}
+	}
+	defer removeCompiled(compiledFilePath, language)
+	batches, err := r.Question.GetTestCaseBatches(question.ID)
+	if err != nil {
+		return judgeOutcome{Err: fmt.Errorf("failed to retrieve test cases: %v", err)}
+	}
+	return scoreTestGroups(compiledFilePath, buildTestGroups(question, batches), language)
+}
+
+// ExecuteSubmit judges the code on the hidden test cases and stores the submission.
+// The stored submission is returned whenever the code got a verdict. An accepted
+// one carries its performance ranking, which is not stored since later
+// submissions move it, so stored submissions are listed without one.
+func (r *CodeRunner) ExecuteSubmit(data commontypes.CodeRunnerType) (*models.CodeSubmission, error) {
 	question, err := r.Question.GetQuestionById(data.QuestionId)
 	if err != nil {
-		return nil, 0, 0, fmt.Errorf("failed to retrieve question: %v", err)
+		return nil, fmt.Errorf("failed to retrieve question: %v", err)
 	}
-	codeFilePath := fileWriter(data.Code, data.Language, question.CodeTemplates[data.Language])
-	if codeFilePath == "" {
-		return nil, 0, 0, fmt.Errorf("file creation failed")
+	if question.CurrentLifecycle() == models.LifecycleArchived || !data.IsAdmin && !question.IsPublished() {
+		return nil, fmt.Errorf("failed to retrieve question: %v", mongo.ErrNoDocuments)
 	}
 	hasImport := utils.CheckRiskyImports(data.Code, data.Language)
 	if hasImport {
-		return nil, 0, 0, fmt.Errorf("please don't use import statements: %v", err)
+		return nil, fmt.Errorf("please don't use import statements")
 	}
-	compiledFilePath, err := compileCode(codeFilePath, data.Language)
-	if err != nil {
-		return nil, 0, 0, fmt.Errorf("code compilation failed: %v", err)
+	outcome := r.judgeCode(question, data.Code, data.Language)
+	if outcome.Verdict == "" {
+		return nil, outcome.Err
 	}
-	var fileRemoveName string
-	if data.Language == "java" {
-		fileRemoveName = "codeFiles/" + compiledFilePath + ".class"
-	} else {
-		fileRemoveName = compiledFilePath
-	}
-	codeToBeSave := &models.CodeSubmission{}
-	codeToBeSave.UserId = data.UserId
-	codeToBeSave.Question = data.QuestionId
-	codeToBeSave.Code = data.Code
-	codeToBeSave.Language = data.Language
-	userStatus := "attempted"
-	defer fileRemoving(fileRemoveName)
-	defer SaveUserSubmissionData(codeToBeSave)
-	defer SaveUserIdInQuestion(data.QuestionId, data.UserId, userStatus)
-	numberOfPassedTests := 0
-	totalTestCases := 0
-	var failedCase *commontypes.TestResult
-	testCases, err := r.Question.GetTestCases(question.ID)
+	submissionId, err := utils.GetNextSequence("codeSubmission")
 	if err != nil {
-		return nil, 0, totalTestCases, fmt.Errorf("failed to retrieve test cases: %v", err)
+		return nil, errors.New("got error while creating id")
 	}
-	totalTestCases = len(testCases)
-	failedCase, numberOfPassedTests, err = runAllTestCases(compiledFilePath, testCases, data.Language)
-	if err != nil {
-		return failedCase, numberOfPassedTests, totalTestCases, err
+	codeToBeSave := &models.CodeSubmission{
+		ID:               submissionId,
+		UserId:           data.UserId,
+		Question:         data.QuestionId,
+		QuestionRevision: question.Revision,
+		FailedCase:       outcome.FailedCase,
+		PassedTestCases:  outcome.Passed,
+		TotalTestCases:   outcome.Total,
+		Score:            outcome.Score,
+		MaxScore:         outcome.MaxScore,
+		GroupResults:     outcome.Groups,
+		Verdict:          outcome.Verdict,
+		Runtime:          outcome.Runtime,
+		Memory:           outcome.Memory,
+		Code:             data.Code,
+		Language:         data.Language,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+	if outcome.Err != nil {
+		codeToBeSave.Err = outcome.Err.Error()
 	}
-	codeToBeSave.FailedCase = failedCase
-	codeToBeSave.PassedTestCases = numberOfPassedTests
-	codeToBeSave.TotalTestCases = totalTestCases
-	if numberOfPassedTests == totalTestCases {
-		userStatus = "solved"
+	SaveUserSubmissionData(codeToBeSave)
+	if err := recordSubmission(codeToBeSave); err != nil {
+		log.Printf("Could not record submission %s in the question statistics: %v", codeToBeSave.ID, err)
+	}
+	if outcome.Verdict == models.VerdictAccepted {
+		if err := recordPerformance(codeToBeSave); err != nil {
+			log.Printf("Could not record the performance of submission %s: %v", codeToBeSave.ID, err)
+		} else if codeToBeSave.Performance, err = performanceRanking(codeToBeSave); err != nil {
+			log.Printf("Could not rank submission %s: %v", codeToBeSave.ID, err)
+		}
 	}
-	codeToBeSave.Err = ""
-	return failedCase, numberOfPassedTests, totalTestCases, nil
+	return codeToBeSave, outcome.Err
 }
 
 func (r *CodeRunner) GetUserSubmission(userId string, question string) ([]models.CodeSubmission, error) {
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetCommandForLanguage(t *testing.T) {
+	tests := []struct {
+		language string
+		path     string
+		want     []string
+	}{
+		{"py", "codeFiles/a.py", []string{"python3", "codeFiles/a.py"}},
+		{"js", "codeFiles/a.js", []string{"node", "codeFiles/a.js"}},
+		{"c", "codeFiles/a.out", []string{"codeFiles/a.out"}},
+		{"java", "codeFiles/classes123/Main42", []string{"java", "-cp", "codeFiles/classes123", "Main42"}},
+	}
+	for _, test := range tests {
+		t.Run(test.language, func(t *testing.T) {
+			cmd, err := getCommandForLanguage(test.path, test.language)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(cmd.Args, test.want) {
+				t.Errorf("args = %v, want %v", cmd.Args, test.want)
+			}
+		})
+	}
+	if _, err := getCommandForLanguage("a.rs", "rust"); err == nil {
+		t.Error("got a command for an unsupported language")
+	}
+}
+
+func TestRemoveCompiled(t *testing.T) {
+	dir := t.TempDir()
+	classDir := filepath.Join(dir, "classes1")
+	if err := os.Mkdir(classDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Main1.class", "Solution.class", "Judge.class"} {
+		if err := os.WriteFile(filepath.Join(classDir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	binary := filepath.Join(dir, "c.out")
+	if err := os.WriteFile(binary, nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+	removeCompiled(filepath.Join(classDir, "Main1"), "java")
+	removeCompiled(binary, "c")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("left behind %v", entries)
+	}
+}
+
+// Two Java programs compiled side by side each run their own Solution
+func TestCompileJavaSeparately(t *testing.T) {
+	if _, err := exec.LookPath("javac"); err != nil {
+		t.Skip("javac is not installed")
+	}
+	dir := t.TempDir()
+	program := func(name string, answer string) string {
+		path := filepath.Join(dir, name+".java")
+		code := "class Solution { int answer() { return " + answer + "; } }\n" +
+			"public class " + name + " { public static void main(String[] args) { System.out.println(new Solution().answer()); } }\n"
+		if err := os.WriteFile(path, []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	first, err := compileCode(program("Main1", "1"), "java")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := compileCode(program("Main2", "2"), "java")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for compiled, want := range map[string]string{first: "1", second: "2"} {
+		cmd, _ := getCommandForLanguage(compiled, "java")
+		output, err := cmd.Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(output) != want+"\n" {
+			t.Errorf("%s printed %q, want %s", compiled, output, want)
+		}
+		removeCompiled(compiled, "java")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("left behind %v", entries)
+	}
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"code-compiler/db"
+	"code-compiler/internal/models"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// publishedFilter matches the questions users other than admins may see.
+// Questions without a lifecycle predate it and count as published.
+func publishedFilter() bson.M {
+	return bson.M{"lifecycle": bson.M{"$in": bson.A{nil, "", models.LifecyclePublished}}}
+}
+
+// lifecycleFilter matches the questions in one lifecycle state
+func lifecycleFilter(state string) bson.M {
+	if state == models.LifecyclePublished {
+		return publishedFilter()
+	}
+	return bson.M{"lifecycle": state}
+}
+
+// SetLifecycle moves a question to another lifecycle state as a new revision.
+// The publish time is only kept for scheduled questions. Archiving and leaving
+// the archive take the question's test case batches along.
+func (r *Question) SetLifecycle(questionID string, state string, publishAt *time.Time, author string) (*models.Question, error) {
+	if state == models.LifecycleArchived {
+		return r.ArchiveQuestion(questionID, author)
+	}
+	question, err := r.GetQuestionById(questionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := question.ValidateLifecycleTransition(state, publishAt, time.Now()); err != nil {
+		return nil, err
+	}
+	changes := bson.M{"lifecycle": state, "publishAt": nil}
+	if state == models.LifecycleScheduled {
+		changes["publishAt"] = publishAt
+	}
+	restoring := question.CurrentLifecycle() == models.LifecycleArchived
+	if restoring {
+		changes["archivedFrom"] = nil
+		changes["archivedAt"] = nil
+	}
+	updated, err := r.updateQuestionContent(questionID, changes, author, "lifecycle changed to "+state)
+	if err != nil {
+		return nil, err
+	}
+	if restoring {
+		if err := leaveArchive(question); err != nil {
+			return updated, fmt.Errorf("question restored but its test cases were not: %v", err)
+		}
+	}
+	return updated, nil
+}
+
+// PublishDueQuestions publishes the scheduled questions whose publish time has passed
+func (r *Question) PublishDueQuestions(now time.Time) (int, error) {
+	var due []models.Question
+	cursor, err := db.QuestionsCollection.Find(context.TODO(), bson.M{
+		"lifecycle": models.LifecycleScheduled,
+		"publishAt": bson.M{"$lte": now},
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err = cursor.All(context.TODO(), &due); err != nil {
+		return 0, err
+	}
+	published := 0
+	for _, question := range due {
+		changes := bson.M{"lifecycle": models.LifecyclePublished, "publishAt": nil}
+		if _, err := r.updateQuestionContent(question.ID, changes, "scheduler", "published on schedule"); err != nil {
+			// Another instance may have published it first, the next tick retries otherwise
+			log.Println("Failed to publish scheduled question", question.ID, ":", err)
+			continue
+		}
+		published++
+	}
+	return published, nil
+}
+
+// StartPublishScheduler publishes due questions every interval until the context is done
+func (r *Question) StartPublishScheduler(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if published, err := r.PublishDueQuestions(time.Now()); err != nil {
+				log.Println("Publish scheduler:", err)
+			} else if published > 0 {
+				log.Println("Publish scheduler published", published, "questions")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"code-compiler/db"
+	"code-compiler/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RequiredLocales are the statement languages every question should have,
+// from STATEMENT_LOCALES (comma separated) and English and Hindi otherwise
+func RequiredLocales() []string {
+	configured := os.Getenv("STATEMENT_LOCALES")
+	if configured == "" {
+		configured = "en,hi"
+	}
+	locales := []string{}
+	for _, locale := range strings.Split(configured, ",") {
+		if normalized, err := models.NormalizeLocale(locale); err == nil {
+			locales = append(locales, normalized)
+		}
+	}
+	return locales
+}
+
+// prepareTranslations validates the translations sent with a new question
+// and marks them as translated from its statement
+func prepareTranslations(question *models.Question, author string) error {
+	if len(question.Translations) == 0 {
+		question.Translations = nil
+		return nil
+	}
+	prepared := make(map[string]models.Translation, len(question.Translations))
+	for locale, translation := range question.Translations {
+		normalized, err := models.NormalizeLocale(locale)
+		if err != nil {
+			return err
+		}
+		if normalized == question.SourceLocale() {
+			return fmt.Errorf("%s is the source locale of the question", normalized)
+		}
+		if err := translation.Validate(); err != nil {
+			return fmt.Errorf("translation %s: %v", normalized, err)
+		}
+		translation.SourceHash = question.StatementHash()
+		translation.UpdatedBy = author
+		translation.UpdatedAt = time.Now()
+		prepared[normalized] = translation
+	}
+	question.Translations = prepared
+	return nil
+}
+
+// SetTranslation adds or replaces the statement of a question in a locale.
+// It counts as up to date with the current source statement.
+func (r *Question) SetTranslation(questionID string, locale string, translation models.Translation, author string) (*models.Question, error) {
+	locale, err := models.NormalizeLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	if err := translation.Validate(); err != nil {
+		return nil, err
+	}
+	question, err := r.GetQuestionById(questionID)
+	if err != nil {
+		return nil, err
+	}
+	if locale == question.SourceLocale() {
+		return nil, fmt.Errorf("%s is the source locale, update the question instead", locale)
+	}
+	if err := renderTranslation(&translation); err != nil {
+		return nil, err
+	}
+	translation.SourceHash = question.StatementHash()
+	translation.UpdatedBy = author
+	translation.UpdatedAt = time.Now()
+	return r.updateQuestionContent(questionID, bson.M{"translations." + locale: translation}, author, "translation "+locale+" updated")
+}
+
+// DeleteTranslation removes the statement of a question in a locale
+func (r *Question) DeleteTranslation(questionID string, locale string, author string) (*models.Question, error) {
+	locale, err := models.NormalizeLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+	question, err := r.GetQuestionById(questionID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := question.Translations[locale]; !ok {
+		return nil, errors.New("the question has no translation for " + locale)
+	}
+	translations := map[string]models.Translation{}
+	for other, translation := range question.Translations {
+		if other != locale {
+			translations[other] = translation
+		}
+	}
+	return r.updateQuestionContent(questionID, bson.M{"translations": translations}, author, "translation "+locale+" removed")
+}
+
+// GetLocaleReport lists the questions missing a required locale or having a
+// translation older than the source statement. Archived questions are left out.
+func (r *Question) GetLocaleReport(required []string) ([]models.LocaleReport, error) {
+	var questions []models.Question
+	cursor, err := db.QuestionsCollection.Find(context.TODO(), bson.M{"lifecycle": bson.M{"$ne": models.LifecycleArchived}}, options.Find().SetProjection(bson.M{
+		"title": 1, "slug": 1, "description": 1, "editorial": 1, "locale": 1, "translations": 1,
+	}).SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(context.TODO(), &questions); err != nil {
+		return nil, err
+	}
+	reports := []models.LocaleReport{}
+	for _, question := range questions {
+		report := question.LocaleReport(required)
+		if len(report.Missing) > 0 || len(report.Stale) > 0 {
+			reports = append(reports, report)
+		}
+	}
+	return reports, nil
+}
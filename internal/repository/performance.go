@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"code-compiler/db"
+	"code-compiler/internal/models"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// recordPerformance adds an accepted submission to the runtime and memory
+// distributions of its question and language
+func recordPerformance(submission *models.CodeSubmission) error {
+	inc := bson.M{
+		"runtime.buckets." + models.BucketKey(submission.Runtime): 1,
+		"runtime.count": 1,
+	}
+	// Memory is not measured on every system
+	if submission.Memory > 0 {
+		inc["memory.buckets."+models.BucketKey(submission.Memory)] = 1
+		inc["memory.count"] = 1
+	}
+	_, err := db.PerformanceCollection.UpdateOne(context.TODO(), bson.M{"_id": models.PerformanceID(submission.Question, submission.Language)}, bson.M{
+		"$inc":         inc,
+		"$set":         bson.M{"updatedAt": time.Now()},
+		"$setOnInsert": bson.M{"questionId": submission.Question, "language": submission.Language},
+	}, options.Update().SetUpsert(true))
+	return err
+}
+
+// performanceRanking ranks an accepted submission against the others of its language
+func performanceRanking(submission *models.CodeSubmission) (*models.PerformanceRanking, error) {
+	var distribution models.PerformanceDistribution
+	err := db.PerformanceCollection.FindOne(context.TODO(), bson.M{"_id": models.PerformanceID(submission.Question, submission.Language)}).Decode(&distribution)
+	if err != nil {
+		return nil, err
+	}
+	ranking := &models.PerformanceRanking{
+		Language:    submission.Language,
+		Submissions: distribution.Runtime.Count,
+		Runtime:     distribution.Runtime.Rank(submission.Runtime),
+	}
+	if submission.Memory > 0 && distribution.Memory.Count > 0 {
+		ranking.Memory = distribution.Memory.Rank(submission.Memory)
+	}
+	return ranking, nil
+}
+
+// savePerformance replaces the distributions of a question
+func savePerformance(questionId string, distributions map[string]*models.PerformanceDistribution) error {
+	if _, err := db.PerformanceCollection.DeleteMany(context.TODO(), bson.M{"questionId": questionId}); err != nil {
+		return err
+	}
+	if len(distributions) == 0 {
+		return nil
+	}
+	documents := make([]interface{}, 0, len(distributions))
+	for _, distribution := range distributions {
+		distribution.UpdatedAt = time.Now()
+		documents = append(documents, distribution)
+	}
+	_, err := db.PerformanceCollection.InsertMany(context.TODO(), documents)
+	return err
+}
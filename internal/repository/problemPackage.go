@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"code-compiler/db"
+	"code-compiler/internal/models"
+	"code-compiler/internal/problempackage"
+	"code-compiler/internal/storage"
+	"code-compiler/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// loadTestData keeps small test files inline and moves large ones to the blob store
+func loadTestData(content io.Reader, size int64) (string, string, error) {
+	if size > int64(inlineTestDataLimit()) {
+		hash, err := putBlob(content)
+		return "", hash, err
+	}
+	var data strings.Builder
+	if _, err := io.Copy(&data, content); err != nil {
+		return "", "", err
+	}
+	return data.String(), "", nil
+}
+
+// ExportQuestion writes the question with its test case batches, except
+// rejected and archived ones, as a zipped problem package
+func (r *Question) ExportQuestion(questionId string, w io.Writer) error {
+	question, err := r.GetQuestionById(questionId)
+	if err != nil {
+		return err
+	}
+	var testCases []models.TestCase
+	cursor, err := db.TestCasesCollection.Find(context.TODO(), bson.M{
+		"questionId":   questionId,
+		"reviewStatus": bson.M{"$ne": models.ReviewRejected},
+		"archivedAt":   nil,
+	}, options.Find().SetSort(bson.M{"createdAt": 1}))
+	if err != nil {
+		return err
+	}
+	if err = cursor.All(context.TODO(), &testCases); err != nil {
+		return err
+	}
+	if err := resolveLinkedTestCases(testCases); err != nil {
+		return err
+	}
+	attachments, err := r.GetAttachments(questionId)
+	if err != nil {
+		return err
+	}
+	pkg := &problempackage.Package{Question: *question, TestCases: testCases}
+	for _, attachment := range attachments {
+		pkg.Attachments = append(pkg.Attachments, problempackage.Attachment{
+			ID:   attachment.ID,
+			Name: attachment.Name,
+			Size: attachment.Size,
+			Blob: attachment.Blob,
+		})
+	}
+	return problempackage.Write(w, pkg, openBlob)
+}
+
+// ImportQuestion creates a question and its test case batches from a problem
+// package. Imported batches wait for review like any other new test cases.
+// The difficulty is only used when the package does not carry one. When an
+// attachment or a batch cannot be created the question is removed again, the
+// import either succeeds whole or leaves nothing behind.
+func (r *Question) ImportQuestion(fsys fs.FS, difficulty string, createdBy string) (*models.Question, []models.TestCase, error) {
+	pkg, err := problempackage.Read(fsys, loadTestData)
+	if err != nil {
+		return nil, nil, err
+	}
+	question := pkg.Question
+	if question.Title == "" {
+		return nil, nil, errors.New("the package has no name in problem.yaml")
+	}
+	if question.Solution == "" {
+		return nil, nil, errors.New("the package has no accepted solution in a supported language under submissions/accepted")
+	}
+	if question.Difficulty == "" {
+		question.Difficulty = difficulty
+	}
+	if question.Difficulty == "" {
+		return nil, nil, errors.New("the package has no difficulty, pass one with the import")
+	}
+	question.CreatedBy = createdBy
+	if len(pkg.Attachments) > maxAttachmentsPerQuestion {
+		return nil, nil, fmt.Errorf("the package has %d attachments, the limit is %d", len(pkg.Attachments), maxAttachmentsPerQuestion)
+	}
+	if len(pkg.Attachments) > 0 && storage.Blobs == nil {
+		return nil, nil, errors.New("blob store is not configured")
+	}
+	// The statement links to the attachments by id, they get new ones here
+	attachmentIds := make([]string, len(pkg.Attachments))
+	links := map[string]string{}
+	for i, attachment := range pkg.Attachments {
+		if attachmentIds[i], err = utils.GetNextSequence("attachment"); err != nil {
+			return nil, nil, errors.New("got error while creating id")
+		}
+		if attachment.ID != "" {
+			links[attachment.ID] = attachmentIds[i]
+		}
+	}
+	relinkQuestion(&question, links)
+	createdQuestion, err := r.CreateQuestion(&question)
+	if err != nil {
+		return nil, nil, err
+	}
+	// undo removes the question again when a part of the package fails
+	undo := func(err error) (*models.Question, []models.TestCase, error) {
+		if _, purgeErr := purgeQuestionData(createdQuestion.ID); purgeErr != nil {
+			return createdQuestion, nil, fmt.Errorf("%v, and question %s could not be removed: %v", err, createdQuestion.ID, purgeErr)
+		}
+		return nil, nil, err
+	}
+	for i, attachment := range pkg.Attachments {
+		if err := importAttachment(attachment, attachmentIds[i], createdQuestion.ID, createdBy); err != nil {
+			return undo(fmt.Errorf("attachment %s failed: %v", attachment.Name, err))
+		}
+	}
+	var testCases []models.TestCase
+	for i := range pkg.TestCases {
+		testCase := pkg.TestCases[i]
+		testCase.QuestionID = createdQuestion.ID
+		testCase.CreatedBy = createdBy
+		createdTestCase, err := r.CreateTestCase(&testCase)
+		if err != nil {
+			return undo(fmt.Errorf("test case batch %d failed: %v", i+1, err))
+		}
+		testCases = append(testCases, *createdTestCase)
+	}
+	return createdQuestion, testCases, nil
+}
+
+// importAttachment stores an attachment of a package under its new id, it is
+// checked like an upload
+func importAttachment(attachment problempackage.Attachment, id string, questionID string, uploadedBy string) error {
+	content, err := attachment.Open()
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+	_, err = storeAttachment(id, questionID, attachment.Name, content, attachment.Size, uploadedBy)
+	return err
+}
@@ -3,13 +3,13 @@ package repository
 import (
 	"code-compiler/db"
 	"code-compiler/internal/models"
+	"code-compiler/internal/storage"
 	"code-compiler/internal/utils"
 	"context"
 	"errors"
 	"fmt"
-	"log"
-	"os"
-	"strconv"
+	"io"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -23,32 +23,98 @@ type Question struct {
 
 // CreateQuestion inserts a new question in the database.
 func (r *Question) CreateQuestion(question *models.Question) (*models.Question, error) {
+	if question.Signature != nil {
+		templates, err := signatureTemplates(question.Signature, question.CodeTemplates)
+		if err != nil {
+			return nil, err
+		}
+		question.CodeTemplates = templates
+		if question.TestCaseVariableNames == "" {
+			question.TestCaseVariableNames = question.Signature.VariableNames()
+		}
+	}
 	if question.Title == "" || question.Description == "" || question.Difficulty == "" ||
 		question.MemoryLimit == 0.0 || question.Solution == "" || question.CodeTemplates == nil || question.SampleTestCases == nil || question.Tags == nil || question.TimeLimit == 0 {
 		return nil, errors.New("please pass title, Description, Difficulty, MemoryLimit, Solution, CodeTemplate, SampleTestCases, Tags, TimeLimit")
 	}
-	slug, _ := r.GetQuestionBySlug(question.Slug, "")
-	if slug != nil {
-		return nil, errors.New("question already exists")
+	question.Difficulty = strings.ToLower(strings.TrimSpace(question.Difficulty))
+	if err := models.ValidateDifficulty(question.Difficulty); err != nil {
+		return nil, err
+	}
+	if question.TimeLimit < 0 || question.MemoryLimit < 0 {
+		return nil, errors.New("timeLimit and memoryLimit must be positive")
+	}
+	if err := models.ValidateCodeTemplates(question.CodeTemplates); err != nil {
+		return nil, err
+	}
+	question.Locale = question.SourceLocale()
+	locale, err := models.NormalizeLocale(question.Locale)
+	if err != nil {
+		return nil, err
+	}
+	question.Locale = locale
+	if err := prepareTranslations(question, question.CreatedBy); err != nil {
+		return nil, err
+	}
+	if err := renderStatement(question); err != nil {
+		return nil, err
+	}
+	tags, err := canonicalTags(question.Tags)
+	if err != nil {
+		return nil, err
+	}
+	question.Tags = tags
+	if err := models.ValidateUnlockPolicy(question.SolutionPolicy, question.UnlockAt); err != nil {
+		return nil, err
+	}
+	if err := validateTestGroups(question.TestGroups); err != nil {
+		return nil, err
 	}
 	var seq, err_ = utils.GetNextSequence("question") // Create a new id
 	if err_ != nil {
 		return nil, errors.New("got error while creating id")
 	}
+	// A slug passed with the question is a custom one, otherwise it comes from the title
+	slug, err := uniqueSlug(slugBase(question.Title, seq), seq)
+	if question.Slug != "" {
+		slug, err = customSlug(question.Slug, seq)
+	}
+	if err != nil {
+		return nil, err
+	}
 	question.ID = seq
 	question.CreatedAt = time.Now()
 	question.UpdatedAt = time.Now()
-	question.Slug = utils.MakeSlug(question.Title)
-	_, err := db.QuestionsCollection.InsertOne(context.TODO(), question)
+	question.Slug = slug
+	question.PreviousSlugs = nil
+	question.Revision = 1
+	question.Lifecycle = models.LifecycleDraft // published through SetLifecycle once reviewed
+	question.PublishAt = nil
+	question.Users = nil // statistics come from judged submissions only
+	question.SubmissionCount = 0
+	question.SuccessRate = 0
+	question.Stats = nil
+	_, err = db.QuestionsCollection.InsertOne(context.TODO(), question)
 	if err != nil {
 		return nil, err
 	}
+	indexQuestion(question)
+	if err := saveRevision(question, question.CreatedBy, "created"); err != nil {
+		return nil, fmt.Errorf("question created but its revision could not be saved: %v", err)
+	}
 	return question, nil
 }
 
 func (r *Question) GetQuestionsByTag(tagName string) ([]models.Question, error) {
 	var questions []models.Question
-	cursor, err := db.QuestionsCollection.Find(context.TODO(), bson.M{"tags": bson.M{"$in": []string{tagName}}})
+	// Aliases resolve to the tag, and questions of its subtags are included
+	tags, err := tagWithDescendants(tagName)
+	if err != nil {
+		return nil, err
+	}
+	filter := publishedFilter()
+	filter["tags"] = bson.M{"$in": tags}
+	cursor, err := db.QuestionsCollection.Find(context.TODO(), filter)
 	if err != nil {
 		return nil, err
 	}
@@ -120,14 +186,80 @@ func (r *Question) GetQuestionBySlug(slug string, userId string) (*models.Questi
 	return &results[0], nil
 }
 
-func (r *Question) GetQuestions(userId string, skip int, title string, difficulty string, status string) ([]models.Question, int64, error) {
+// catalogSortFields are the sort keys of the catalog, computed ones are added
+// by catalogSortStages
+var catalogSortFields = map[string]string{
+	models.SortByID:          "idNumber",
+	models.SortByTitle:       "title",
+	models.SortByDifficulty:  "difficultyRank",
+	models.SortByAcceptance:  "successRate",
+	models.SortBySubmissions: "submissionCount",
+	models.SortByNewest:      "createdAt",
+	models.SortByRelevance:   "searchRank",
+}
+
+// catalogSortStages orders the catalog, ties are broken by ID so pages never
+// overlap
+func catalogSortStages(query models.QuestionQuery, rankedIds []string) []bson.M {
+	sortOrder := query.SortOrder()
+	direction := 1
+	// Newest, acceptance and submissions read best first
+	switch sortOrder {
+	case models.SortByNewest, models.SortByAcceptance, models.SortBySubmissions:
+		direction = -1
+	}
+	if query.Descending {
+		direction = -direction
+	}
+	// Ids are numeric strings, compare them as numbers
+	computed := bson.M{"idNumber": bson.M{"$convert": bson.M{"input": "$_id", "to": "long", "onError": 0, "onNull": 0}}}
+	switch sortOrder {
+	case models.SortByDifficulty:
+		computed["difficultyRank"] = bson.M{"$indexOfArray": bson.A{
+			bson.A{models.DifficultyEasy, models.DifficultyMedium, models.DifficultyHard}, "$difficulty",
+		}}
+	case models.SortByRelevance:
+		computed["searchRank"] = bson.M{"$indexOfArray": bson.A{rankedIds, "$_id"}}
+	}
+	sort := bson.D{{Key: catalogSortFields[sortOrder], Value: direction}}
+	if sortOrder != models.SortByID {
+		sort = append(sort, bson.E{Key: "idNumber", Value: 1})
+	}
+	return []bson.M{
+		{"$addFields": computed},
+		{"$sort": sort},
+	}
+}
+
+// GetQuestions lists a page of the catalog. With a search query the questions
+// come ranked by relevance with a highlighted snippet.
+func (r *Question) GetQuestions(query models.QuestionQuery) ([]models.Question, int64, error) {
 	var questions []models.Question
+	userId := query.UserID
+	aliases, err := tagAliases()
+	if err != nil {
+		return nil, 0, err
+	}
+	query.Tags = resolveAliases(models.NormalizeTags(query.Tags), aliases)
 	matchConditions := bson.M{}
-	if title != "" {
-		matchConditions["title"] = bson.M{"$regex": title, "$options": "i"} // Case-insensitive regex
+	if !query.IncludeUnpublished {
+		matchConditions = publishedFilter()
+	} else if query.Lifecycle != "" {
+		matchConditions = lifecycleFilter(query.Lifecycle)
+	} else {
+		matchConditions["lifecycle"] = bson.M{"$ne": models.LifecycleArchived}
 	}
-	if difficulty != "" {
-		matchConditions["difficulty"] = difficulty
+	var rankedIds []string
+	var snippets map[string]string
+	if query.Search != "" {
+		rankedIds, snippets = rankedSearch(query, aliases)
+		matchConditions["_id"] = bson.M{"$in": rankedIds}
+	}
+	if len(query.Difficulties) > 0 {
+		matchConditions["difficulty"] = bson.M{"$in": query.Difficulties}
+	}
+	if len(query.Tags) > 0 {
+		matchConditions["$and"] = allTagsFilter(query.Tags, aliases)
 	}
 	matchStage := bson.M{
 		"$match": matchConditions,
@@ -139,30 +271,46 @@ func (r *Question) GetQuestions(userId string, skip int, title string, difficult
 		pipeline = append(pipeline, addFieldsStage)
 	}
 
-	if status != "" && userId != "" {
+	if query.Status != "" && userId != "" {
+		status := bson.M{"$eq": query.Status}
+		if query.Status == "todo" {
+			status = bson.M{"$eq": nil}
+		}
 		pipeline = append(pipeline, bson.M{
 			"$match": bson.M{
 				"userStatus": status,
 			},
 		})
 	}
-	limit, err := strconv.Atoi(os.Getenv("PAGE_LIMIT"))
-	if err != nil {
-		limit = 10
+	if query.Search != "" {
+		pipeline = append(pipeline, searchLimitStages(rankedIds)...)
 	}
 	paginationStages := []bson.M{
-		{"$skip": skip},
-		{"$limit": limit},
+		{"$skip": (query.Page - 1) * query.Limit},
+		{"$limit": query.Limit},
 	}
 	projectStage := bson.M{
 		"$project": bson.M{
-			"_id":        1,
-			"slug":       1,
-			"title":      1,
-			"difficulty": 1,
-			"userStatus": 1,
+			"_id":             1,
+			"slug":            1,
+			"title":           1,
+			"difficulty":      1,
+			"userStatus":      1,
+			"lifecycle":       1,
+			"tags":            1,
+			"successRate":     1,
+			"submissionCount": 1,
+			"stats":           1,
+			"locale":          1,
+			// Only the translated titles are listed
+			"translations": bson.M{"$arrayToObject": bson.M{"$map": bson.M{
+				"input": bson.M{"$objectToArray": bson.M{"$ifNull": bson.A{"$translations", bson.M{}}}},
+				"as":    "translation",
+				"in":    bson.M{"k": "$$translation.k", "v": bson.M{"title": "$$translation.v.title"}},
+			}}},
 		},
 	}
+	dataStages := append(catalogSortStages(query, rankedIds), paginationStages...)
 	// pipeline = append(pipeline, projectStage)
 	// Facet stage to calculate count and get paginated results
 	pipeline = append(pipeline, bson.M{
@@ -170,9 +318,7 @@ func (r *Question) GetQuestions(userId string, skip int, title string, difficult
 			"metadata": []bson.M{
 				{"$count": "total"}, // Count total documents
 			},
-			"data": append([]bson.M{
-				projectStage,
-			}, paginationStages...), // Apply projection and pagination
+			"data": append(dataStages, projectStage), // Sort, paginate, then project
 		},
 	})
 	// Use the Aggregate method instead of Find
@@ -200,6 +346,9 @@ func (r *Question) GetQuestions(userId string, skip int, title string, difficult
 		totalCount = 0
 		questions = []models.Question{}
 	}
+	for i := range questions {
+		questions[i].Snippet = snippets[questions[i].ID]
+	}
 	return questions, totalCount, nil
 }
 
@@ -214,16 +363,10 @@ func (r *Question) GetQuestionById(questionID string) (*models.Question, error)
 }
 
 func (r *Question) GetTestCases(questionId string) ([]models.InputOutput, error) {
-	var testCases []struct {
-		IOPairs []models.InputOutput
-	}
-	cursor, err := db.TestCasesCollection.Find(context.TODO(), bson.M{"questionId": questionId, "approved": true}, options.Find().SetProjection(bson.M{"ioPairs": 1}))
+	testCases, err := r.GetTestCaseBatches(questionId)
 	if err != nil {
 		return nil, err
 	}
-	if err = cursor.All(context.TODO(), &testCases); err != nil {
-		log.Fatal(err)
-	}
 	var ioPairs []models.InputOutput
 	for _, testCase := range testCases {
 		ioPairs = append(ioPairs, testCase.IOPairs...)
@@ -231,31 +374,122 @@ func (r *Question) GetTestCases(questionId string) ([]models.InputOutput, error)
 	return ioPairs, nil
 }
 
+// GetTestCaseBatches returns the approved, not archived test case documents of a question with their groups
+func (r *Question) GetTestCaseBatches(questionId string) ([]models.TestCase, error) {
+	var testCases []models.TestCase
+	cursor, err := db.TestCasesCollection.Find(context.TODO(), bson.M{"questionId": questionId, "approved": true, "archivedAt": nil}, options.Find().SetSort(bson.M{"createdAt": 1}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(context.TODO(), &testCases); err != nil {
+		return nil, err
+	}
+	if err := resolveLinkedTestCases(testCases); err != nil {
+		return nil, err
+	}
+	return testCases, nil
+}
+
 func (r *Question) GetTestCasesById(testCaseId string) (*models.TestCase, error) {
 	var testCases models.TestCase
 	err := db.TestCasesCollection.FindOne(context.TODO(), bson.M{"_id": testCaseId}).Decode(&testCases)
 	if err != nil {
 		return nil, err
 	}
-	return &testCases, nil
+	resolved := []models.TestCase{testCases}
+	if err := resolveLinkedTestCases(resolved); err != nil {
+		return nil, err
+	}
+	return &resolved[0], nil
 }
 
-func (r *Question) UpdateTestCases(testCaseId string, updatedData bson.M) (*models.TestCase, error) {
-	updatedData["updatedAt"] = time.Now()
-	err := db.TestCasesCollection.FindOneAndUpdate(
-		context.TODO(),
-		bson.M{"_id": testCaseId},
-		bson.M{"$set": updatedData},
-	).Decode(&updatedData)
+// UpdateTestCases edits a test case batch. Approval can only change through a
+// review, and editing the ioPairs sends the batch back to review. A cloned
+// batch gets its own pairs once they are edited, and clones still linked to
+// the batch go back to review with it.
+func (r *Question) UpdateTestCases(testCaseId string, patch *models.TestCasePatch, editorId string) (*models.TestCase, error) {
+	if err := patch.Validate(); err != nil {
+		return nil, err
+	}
+	if patch.IOPairs != nil {
+		if err := moveLargeTestData(*patch.IOPairs); err != nil {
+			return nil, err
+		}
+		if err := validateTestDataBlobs(*patch.IOPairs); err != nil {
+			return nil, err
+		}
+	}
+	changes := bson.M(patch.Changes())
+	if len(changes) == 0 {
+		return nil, errors.New("nothing to update")
+	}
+	changes["updatedAt"] = time.Now()
+	update := bson.M{"$set": changes}
+	if patch.IOPairs != nil {
+		update["$unset"] = bson.M{"linkedFrom": ""}
+		changes["approved"] = false
+		changes["reviewStatus"] = models.ReviewPending
+		update["$push"] = bson.M{"reviews": models.TestCaseReview{
+			Status:     models.ReviewPending,
+			ReviewerID: editorId,
+			Comment:    "test data edited",
+			CreatedAt:  time.Now(),
+		}}
+	}
+	result, err := db.TestCasesCollection.UpdateOne(context.TODO(), bson.M{"_id": testCaseId}, update)
 	if err != nil {
 		return nil, err
 	}
+	if result.MatchedCount == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+	if patch.IOPairs != nil {
+		if _, err := db.TestCasesCollection.UpdateMany(context.TODO(), bson.M{"linkedFrom": testCaseId}, bson.M{
+			"$set": bson.M{"approved": false, "reviewStatus": models.ReviewPending, "updatedAt": time.Now()},
+			"$push": bson.M{"reviews": models.TestCaseReview{
+				Status:     models.ReviewPending,
+				ReviewerID: editorId,
+				Comment:    "linked test data edited in test case " + testCaseId,
+				CreatedAt:  time.Now(),
+			}},
+		}); err != nil {
+			return nil, err
+		}
+	}
 	return r.GetTestCasesById(testCaseId)
 }
 
+// StoreTestData saves an uploaded test input or output in the blob store.
+// The returned hash goes in inputBlob or outputBlob of a test case.
+func (r *Question) StoreTestData(content io.Reader) (string, int64, error) {
+	if storage.Blobs == nil {
+		return "", 0, errors.New("blob store is not configured")
+	}
+	return storage.Blobs.Put(content)
+}
+
 // CreateTestCase inserts a new test case in the database.
 func (r *Question) CreateTestCase(testCase *models.TestCase) (*models.TestCase, error) {
-	var seq, err = utils.GetNextSequence("testCase") // Create a new ObjectID
+	question, err := r.GetQuestionById(testCase.QuestionID)
+	if err != nil {
+		return nil, fmt.Errorf("question %s: %v", testCase.QuestionID, err)
+	}
+	if question.CurrentLifecycle() == models.LifecycleArchived {
+		return nil, errors.New("the question is archived")
+	}
+	if err := models.ValidateVisibility(testCase.Visibility); err != nil {
+		return nil, err
+	}
+	if err := models.ValidateGroupName(testCase.Group); err != nil {
+		return nil, err
+	}
+	if err := moveLargeTestData(testCase.IOPairs); err != nil {
+		return nil, err
+	}
+	if err := validateTestDataBlobs(testCase.IOPairs); err != nil {
+		return nil, err
+	}
+	seq, err := utils.GetNextSequence("testCase") // Create a new ObjectID
 	if err != nil {
 		return nil, errors.New("got error while creating id")
 	}
@@ -263,6 +497,13 @@ func (r *Question) CreateTestCase(testCase *models.TestCase) (*models.TestCase,
 	testCase.CreatedAt = time.Now()
 	testCase.UpdatedAt = time.Now()
 	testCase.Approved = false
+	testCase.ReviewStatus = models.ReviewPending
+	testCase.Reviews = []models.TestCaseReview{{
+		Status:     models.ReviewPending,
+		ReviewerID: testCase.CreatedBy,
+		Comment:    "submitted for review",
+		CreatedAt:  testCase.CreatedAt,
+	}}
 	_, err = db.TestCasesCollection.InsertOne(context.TODO(), testCase)
 	if err != nil {
 		return nil, err
@@ -270,17 +511,69 @@ func (r *Question) CreateTestCase(testCase *models.TestCase) (*models.TestCase,
 	return testCase, nil
 }
 
-func (r *Question) UpdateQuestionById(questionID string, updatedData bson.M) (*models.Question, error) {
-	updatedData["updatedAt"] = time.Now()
-	err := db.QuestionsCollection.FindOneAndUpdate(
-		context.TODO(),
-		bson.M{"_id": questionID},
-		bson.M{"$set": updatedData},
-	).Decode(&updatedData)
-	if err != nil {
+// UpdateQuestionById validates the patch and saves it as a new revision of the question
+func (r *Question) UpdateQuestionById(questionID string, patch *models.QuestionPatch, author string) (*models.Question, error) {
+	if err := patch.Validate(); err != nil {
+		return nil, err
+	}
+	if patch.TestGroups != nil {
+		if err := validateTestGroups(*patch.TestGroups); err != nil {
+			return nil, err
+		}
+	}
+	changes := bson.M(patch.Changes())
+	if len(changes) == 0 {
+		return nil, errors.New("nothing to update")
+	}
+	if patch.Tags != nil {
+		tags, err := canonicalTags(*patch.Tags)
+		if err != nil {
+			return nil, err
+		}
+		changes["tags"] = tags
+	}
+	if err := renderPatch(patch, changes); err != nil {
 		return nil, err
 	}
-	return r.GetQuestionById(questionID)
+	if patch.Signature != nil {
+		var custom map[string]models.CodeTemplate
+		if patch.CodeTemplates != nil {
+			custom = *patch.CodeTemplates
+		}
+		templates, err := signatureTemplates(patch.Signature, custom)
+		if err != nil {
+			return nil, err
+		}
+		changes["codeTemplates"] = templates
+		if patch.TestCaseVariableNames == nil {
+			changes["testcasevariablenames"] = patch.Signature.VariableNames()
+		}
+	}
+	if patch.Locale != nil {
+		current, err := r.GetQuestionById(questionID)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := current.Translations[*patch.Locale]; ok {
+			return nil, fmt.Errorf("the question has a translation for %s, remove it before making it the source locale", *patch.Locale)
+		}
+	}
+	if patch.Slug != nil || patch.Title != nil {
+		current, err := r.GetQuestionById(questionID)
+		if err != nil {
+			return nil, err
+		}
+		slug, err := nextSlug(current, patch)
+		if err != nil {
+			return nil, err
+		}
+		delete(changes, "slug")
+		if slug != current.Slug {
+			changes["slug"] = slug
+			changes["previousSlugs"] = previousSlugsAfter(current, slug)
+		}
+	}
+	return r.updateQuestionContent(questionID, changes, author, "updated")
 }
 
 // CreateTestCase inserts a new test case in the database.
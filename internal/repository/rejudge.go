@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"code-compiler/db"
+	"code-compiler/internal/models"
+	"code-compiler/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// rejudgeSlot lets only one rejudge job run at a time, later jobs wait queued
+var rejudgeSlot = make(chan struct{}, 1)
+
+// submissionIdFilter matches a submission by its id. Submissions stored before
+// ids were assigned carry a Mongo ObjectID instead of a sequence string.
+func submissionIdFilter(id string) bson.M {
+	if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+		return bson.M{"_id": bson.M{"$in": bson.A{id, oid}}}
+	}
+	return bson.M{"_id": id}
+}
+
+// submissionVerdict returns the stored verdict, deriving one for submissions saved before verdicts existed
+func submissionVerdict(submission *models.CodeSubmission) string {
+	if submission.Verdict != "" {
+		return submission.Verdict
+	}
+	// failed runs used to be stored without any test counts
+	if submission.Err != "" || submission.TotalTestCases == 0 {
+		return models.VerdictRuntimeError
+	}
+	if submission.FailedCase != nil || submission.PassedTestCases != submission.TotalTestCases {
+		return models.VerdictWrongAnswer
+	}
+	return models.VerdictAccepted
+}
+
+func rejudgeFilter(request models.RejudgeRequest) (bson.M, error) {
+	filter := bson.M{}
+	if request.QuestionID != "" {
+		filter["question"] = request.QuestionID
+	}
+	createdAt := bson.M{}
+	if request.From != nil {
+		createdAt["$gte"] = *request.From
+	}
+	if request.To != nil {
+		createdAt["$lte"] = *request.To
+	}
+	if len(createdAt) > 0 {
+		filter["createdAt"] = createdAt
+	}
+	if len(request.SubmissionIDs) > 0 {
+		ids := bson.A{}
+		for _, id := range request.SubmissionIDs {
+			ids = append(ids, id)
+			if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+				ids = append(ids, oid)
+			}
+		}
+		filter["_id"] = bson.M{"$in": ids}
+	}
+	if len(filter) == 0 {
+		return nil, errors.New("pass questionId, a from/to time range or submissionIds")
+	}
+	return filter, nil
+}
+
+// StartRejudge stores a new rejudge job and runs it in the background
+func (r *CodeRunner) StartRejudge(request models.RejudgeRequest, requestedBy string) (*models.RejudgeJob, error) {
+	filter, err := rejudgeFilter(request)
+	if err != nil {
+		return nil, err
+	}
+	if request.QuestionID != "" {
+		question, err := r.Question.GetQuestionById(request.QuestionID)
+		if err != nil {
+			return nil, err
+		}
+		if question.CurrentLifecycle() == models.LifecycleArchived {
+			return nil, errors.New("the question is archived")
+		}
+	}
+	total, err := db.CodeSubmissionCollection.CountDocuments(context.TODO(), filter)
+	if err != nil {
+		return nil, err
+	}
+	seq, err := utils.GetNextSequence("rejudgeJob")
+	if err != nil {
+		return nil, errors.New("got error while creating id")
+	}
+	job := &models.RejudgeJob{
+		ID:          seq,
+		Request:     request,
+		Status:      models.RejudgeQueued,
+		RequestedBy: requestedBy,
+		Total:       int(total),
+		Changes:     []models.RejudgeChange{},
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if _, err := db.RejudgeJobsCollection.InsertOne(context.TODO(), job); err != nil {
+		return nil, err
+	}
+	go r.runRejudge(job.ID, filter, false)
+	return job, nil
+}
+
+// ResumeRejudgeJobs runs again, in their original order, the jobs left queued
+// or running when the server stopped. A job resumed mid-run starts over, the
+// submissions it already rejudged keep their new verdict and show no change,
+// so the solved status and statistics of everything it covers are refreshed.
+func (r *CodeRunner) ResumeRejudgeJobs() (int, error) {
+	filter := bson.M{"status": bson.M{"$in": bson.A{models.RejudgeQueued, models.RejudgeRunning}}}
+	cursor, err := db.RejudgeJobsCollection.Find(context.TODO(), filter, options.Find().SetSort(bson.M{"createdAt": 1}))
+	if err != nil {
+		return 0, err
+	}
+	var jobs []models.RejudgeJob
+	if err := cursor.All(context.TODO(), &jobs); err != nil {
+		return 0, err
+	}
+	resumed := []models.RejudgeJob{}
+	for _, job := range jobs {
+		if _, err := rejudgeFilter(job.Request); err != nil {
+			setRejudgeJob(job.ID, bson.M{"status": models.RejudgeFailed, "err": err.Error(), "finishedAt": time.Now()})
+			continue
+		}
+		setRejudgeJob(job.ID, bson.M{"status": models.RejudgeQueued, "processed": 0})
+		resumed = append(resumed, job)
+	}
+	go func() {
+		for _, job := range resumed {
+			filter, _ := rejudgeFilter(job.Request)
+			r.runRejudge(job.ID, filter, true)
+		}
+	}()
+	return len(resumed), nil
+}
+
+// GetRejudgeJob returns the progress and verdict changes of a rejudge job
+func (r *CodeRunner) GetRejudgeJob(jobId string) (*models.RejudgeJob, error) {
+	var job models.RejudgeJob
+	if err := db.RejudgeJobsCollection.FindOne(context.TODO(), bson.M{"_id": jobId}).Decode(&job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func setRejudgeJob(jobId string, fields bson.M) {
+	fields["updatedAt"] = time.Now()
+	if _, err := db.RejudgeJobsCollection.UpdateOne(context.TODO(), bson.M{"_id": jobId}, bson.M{"$set": fields}); err != nil {
+		log.Printf("Could not update rejudge job %s: %v", jobId, err)
+	}
+}
+
+func (r *CodeRunner) runRejudge(jobId string, filter bson.M, resumed bool) {
+	rejudgeSlot <- struct{}{}
+	defer func() { <-rejudgeSlot }()
+	setRejudgeJob(jobId, bson.M{"status": models.RejudgeRunning})
+
+	err := r.rejudgeSubmissions(jobId, filter, resumed)
+	finishedAt := time.Now()
+	if err != nil {
+		setRejudgeJob(jobId, bson.M{"status": models.RejudgeFailed, "err": err.Error(), "finishedAt": finishedAt})
+		return
+	}
+	setRejudgeJob(jobId, bson.M{"status": models.RejudgeCompleted, "finishedAt": finishedAt})
+}
+
+// rejudgeSubmissions judges every submission the filter matches. With
+// refreshAll the status of every user it covers is refreshed, not only of
+// those whose verdict changed in this run.
+func (r *CodeRunner) rejudgeSubmissions(jobId string, filter bson.M, refreshAll bool) error {
+	// Only the ids are read up front, judging can outlast the server's idle cursor timeout
+	cursor, err := db.CodeSubmissionCollection.Find(context.TODO(), filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+	var ids []bson.M
+	if err := cursor.All(context.TODO(), &ids); err != nil {
+		return err
+	}
+
+	questions := map[string]*models.Question{}
+	// users whose solved status may have changed, per question
+	touched := map[string]map[string]bool{}
+	processed := 0
+	for _, id := range ids {
+		var submission models.CodeSubmission
+		err := db.CodeSubmissionCollection.FindOne(context.TODO(), bson.M{"_id": id["_id"]}).Decode(&submission)
+		if err == mongo.ErrNoDocuments {
+			// deleted since the job started
+			processed++
+			setRejudgeJob(jobId, bson.M{"processed": processed})
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		question, ok := questions[submission.Question]
+		if !ok {
+			question, err = r.Question.GetQuestionById(submission.Question)
+			if err != nil || question.CurrentLifecycle() == models.LifecycleArchived {
+				question = nil
+			}
+			questions[submission.Question] = question
+		}
+		if question != nil {
+			change, err := r.rejudgeSubmission(jobId, question, &submission)
+			if err != nil {
+				log.Printf("Rejudge job %s skipped submission %s: %v", jobId, submission.ID, err)
+			}
+			if change != nil || refreshAll {
+				if touched[submission.Question] == nil {
+					touched[submission.Question] = map[string]bool{}
+				}
+				touched[submission.Question][submission.UserId] = true
+			}
+			if change != nil {
+				if _, err := db.RejudgeJobsCollection.UpdateOne(context.TODO(), bson.M{"_id": jobId}, bson.M{
+					"$push": bson.M{"changes": change},
+				}); err != nil {
+					log.Printf("Could not record rejudge change for submission %s: %v", submission.ID, err)
+				}
+			}
+		}
+		processed++
+		setRejudgeJob(jobId, bson.M{"processed": processed})
+	}
+	for questionId, users := range touched {
+		for userId := range users {
+			if err := refreshUserStatus(questionId, userId); err != nil {
+				log.Printf("Could not refresh status of user %s on question %s: %v", userId, questionId, err)
+			}
+		}
+		// Verdicts changed, the counters and distributions follow the stored submissions again
+		if _, err := RecomputeQuestionStats(questionId); err != nil {
+			log.Printf("Could not recompute statistics of question %s: %v", questionId, err)
+		}
+	}
+	return nil
+}
+
+// rejudgeSubmission judges one stored submission again and saves the result
+// when the verdict or the passed count changed
+func (r *CodeRunner) rejudgeSubmission(jobId string, question *models.Question, submission *models.CodeSubmission) (*models.RejudgeChange, error) {
+	outcome := r.judgeCode(question, submission.Code, submission.Language)
+	if outcome.Verdict == "" {
+		return nil, outcome.Err
+	}
+	oldVerdict := submissionVerdict(submission)
+	if oldVerdict == outcome.Verdict && submission.PassedTestCases == outcome.Passed &&
+		submission.TotalTestCases == outcome.Total && submission.Score == outcome.Score {
+		return nil, nil
+	}
+	errMessage := ""
+	if outcome.Err != nil {
+		errMessage = outcome.Err.Error()
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"failedCase":      outcome.FailedCase,
+			"passedTestCases": outcome.Passed,
+			"totalTestCases":  outcome.Total,
+			"score":           outcome.Score,
+			"maxScore":        outcome.MaxScore,
+			"groupResults":    outcome.Groups,
+			"verdict":         outcome.Verdict,
+			"runtime":         outcome.Runtime,
+			"memory":          outcome.Memory,
+			"err":             errMessage,
+			"updatedAt":       time.Now(),
+		},
+		"$push": bson.M{
+			"verdictHistory": models.VerdictChange{
+				Verdict:         oldVerdict,
+				PassedTestCases: submission.PassedTestCases,
+				TotalTestCases:  submission.TotalTestCases,
+				Score:           submission.Score,
+				RejudgeJobID:    jobId,
+				ChangedAt:       time.Now(),
+			},
+		},
+	}
+	if _, err := db.CodeSubmissionCollection.UpdateOne(context.TODO(), submissionIdFilter(submission.ID), update); err != nil {
+		return nil, fmt.Errorf("could not save rejudged submission: %v", err)
+	}
+	return &models.RejudgeChange{
+		SubmissionID: submission.ID,
+		UserId:       submission.UserId,
+		Question:     submission.Question,
+		OldVerdict:   oldVerdict,
+		NewVerdict:   outcome.Verdict,
+		OldPassed:    submission.PassedTestCases,
+		NewPassed:    outcome.Passed,
+	}, nil
+}
+
+// refreshUserStatus sets the user's status on the question from their stored
+// submissions, so a rejudge can both grant and revoke solved.
+func refreshUserStatus(questionId string, userId string) error {
+	var submissions []models.CodeSubmission
+	cursor, err := db.CodeSubmissionCollection.Find(context.TODO(), bson.M{"question": questionId, "userId": userId})
+	if err != nil {
+		return err
+	}
+	if err = cursor.All(context.TODO(), &submissions); err != nil {
+		return err
+	}
+	status := "attempted"
+	for i := range submissions {
+		if submissionVerdict(&submissions[i]) == models.VerdictAccepted {
+			status = "solved"
+			break
+		}
+	}
+	_, err = db.QuestionsCollection.UpdateOne(context.TODO(), bson.M{"_id": questionId}, bson.M{
+		"$set": bson.M{"users." + userId: status},
+	})
+	return err
+}
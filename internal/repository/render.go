@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"code-compiler/db"
+	"code-compiler/internal/markdown"
+	"code-compiler/internal/models"
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// renderTranslation caches the HTML of a translated statement
+func renderTranslation(translation *models.Translation) error {
+	var err error
+	if translation.DescriptionHTML, err = markdown.Render(translation.Description); err != nil {
+		return err
+	}
+	translation.EditorialHTML, err = markdown.Render(translation.Editorial)
+	return err
+}
+
+// renderStatement caches the HTML of the description and editorial of a
+// question and of its translations
+func renderStatement(question *models.Question) error {
+	var err error
+	if question.DescriptionHTML, err = markdown.Render(question.Description); err != nil {
+		return fmt.Errorf("description: %v", err)
+	}
+	if question.EditorialHTML, err = markdown.Render(question.Editorial); err != nil {
+		return fmt.Errorf("editorial: %v", err)
+	}
+	for locale, translation := range question.Translations {
+		if err := renderTranslation(&translation); err != nil {
+			return fmt.Errorf("translation %s: %v", locale, err)
+		}
+		question.Translations[locale] = translation
+	}
+	return nil
+}
+
+// renderPatch adds the HTML of a patched description or editorial to the changes
+func renderPatch(patch *models.QuestionPatch, changes bson.M) error {
+	if patch.Description != nil {
+		html, err := markdown.Render(*patch.Description)
+		if err != nil {
+			return fmt.Errorf("description: %v", err)
+		}
+		changes["descriptionHtml"] = html
+	}
+	if patch.Editorial != nil {
+		html, err := markdown.Render(*patch.Editorial)
+		if err != nil {
+			return fmt.Errorf("editorial: %v", err)
+		}
+		changes["editorialHtml"] = html
+	}
+	return nil
+}
+
+// renderRollback adds the HTML of the statements a rollback restores to its
+// content. Revisions saved before rendering existed have none, and the others
+// hold what an older renderer made.
+func renderRollback(snapshot *models.Question, content bson.M) error {
+	if err := renderStatement(snapshot); err != nil {
+		return err
+	}
+	content["descriptionHtml"] = snapshot.DescriptionHTML
+	content["editorialHtml"] = snapshot.EditorialHTML
+	if _, ok := content["translations"]; ok {
+		content["translations"] = snapshot.Translations
+	}
+	return nil
+}
+
+// RenderAllStatements renders the statements of every question again, for
+// questions saved before rendering existed or after the renderer changed. The
+// cached HTML is not a content change, so no revision is written.
+func RenderAllStatements() (int, error) {
+	var questions []models.Question
+	cursor, err := db.QuestionsCollection.Find(context.TODO(), bson.M{}, options.Find().SetProjection(bson.M{
+		"description": 1, "editorial": 1, "translations": 1,
+	}))
+	if err != nil {
+		return 0, err
+	}
+	if err = cursor.All(context.TODO(), &questions); err != nil {
+		return 0, err
+	}
+	updated := 0
+	for i := range questions {
+		question := &questions[i]
+		if err := renderStatement(question); err != nil {
+			return updated, fmt.Errorf("question %s: %v", question.ID, err)
+		}
+		set := bson.M{"descriptionHtml": question.DescriptionHTML, "editorialHtml": question.EditorialHTML}
+		for locale, translation := range question.Translations {
+			set["translations."+locale+".descriptionHtml"] = translation.DescriptionHTML
+			set["translations."+locale+".editorialHtml"] = translation.EditorialHTML
+		}
+		if _, err := db.QuestionsCollection.UpdateOne(context.TODO(), bson.M{"_id": question.ID}, bson.M{"$set": set}); err != nil {
+			return updated, fmt.Errorf("question %s: %v", question.ID, err)
+		}
+		updated++
+	}
+	return updated, nil
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	commontypes "code-compiler/internal/commonTypes"
+	"os"
+)
+
+// recordUsage stores the CPU time in ms and the peak memory in kb of a
+// finished test run on its result
+func recordUsage(result *commontypes.TestResult, state *os.ProcessState) {
+	if result == nil || state == nil {
+		return
+	}
+	result.TimeTaken = float64((state.UserTime() + state.SystemTime()).Microseconds()) / 1000
+	result.MemoryUsed = peakMemoryKB(state)
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package repository
+
+import "os"
+
+// peakMemoryKB is not measured on systems without rusage
+func peakMemoryKB(state *os.ProcessState) float64 {
+	return 0
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package repository
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// peakMemoryKB reads the maximum resident set size of the finished process
+func peakMemoryKB(state *os.ProcessState) float64 {
+	usage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return 0
+	}
+	// Darwin reports bytes, the other systems kilobytes
+	if runtime.GOOS == "darwin" || runtime.GOOS == "ios" {
+		return float64(usage.Maxrss) / 1024
+	}
+	return float64(usage.Maxrss)
+}
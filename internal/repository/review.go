@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"code-compiler/db"
+	"code-compiler/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// pendingReviewFilter matches batches still waiting for a decision, including
+// unapproved batches created before review states existed
+var pendingReviewFilter = bson.M{
+	"approved":     bson.M{"$ne": true},
+	"reviewStatus": bson.M{"$ne": models.ReviewRejected},
+	"archivedAt":   nil,
+}
+
+// GetPendingTestCases lists the test case batches waiting for review, oldest first
+func (r *Question) GetPendingTestCases(questionId string) ([]models.TestCase, error) {
+	filter := bson.M{}
+	for key, value := range pendingReviewFilter {
+		filter[key] = value
+	}
+	if questionId != "" {
+		filter["questionId"] = questionId
+	}
+	var testCases []models.TestCase
+	cursor, err := db.TestCasesCollection.Find(context.TODO(), filter, options.Find().SetSort(bson.M{"createdAt": 1}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(context.TODO(), &testCases); err != nil {
+		return nil, err
+	}
+	if err := resolveLinkedTestCases(testCases); err != nil {
+		return nil, err
+	}
+	return testCases, nil
+}
+
+// DryRunTestCase runs the question's reference solution on every pair of the
+// batch, so a reviewer can see whether the expected outputs agree with it.
+func (r *Question) DryRunTestCase(testCase *models.TestCase) (*models.DryRun, error) {
+	question, err := r.GetQuestionById(testCase.QuestionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve question: %v", err)
+	}
+	language := question.SolutionLanguage
+	if language == "" {
+		language = "go"
+	}
+	return dryRun(question.Solution, language, question.CodeTemplates[language], testCase.IOPairs)
+}
+
+// dryRun runs a reference solution on the pairs with the template of its
+// language. Compilation and runtime errors are reported in the result.
+func dryRun(code string, language string, template models.CodeTemplate, pairs []models.InputOutput) (*models.DryRun, error) {
+	dryRun := &models.DryRun{Language: language, Total: len(pairs)}
+	codeFilePath := fileWriter(code, language, template)
+	if codeFilePath == "" {
+		return nil, errors.New("file creation failed")
+	}
+	compiledFilePath, err := compileCode(codeFilePath, language)
+	if err != nil {
+		dryRun.Err = fmt.Sprintf("reference solution compilation failed: %v", err)
+		return dryRun, nil
+	}
+	defer removeCompiled(compiledFilePath, language)
+	for i, pair := range pairs {
+		result, err := runTestCase(compiledFilePath, pair, i+1, language)
+		if err != nil {
+			dryRun.Err = err.Error()
+			break
+		}
+		if result.Passed {
+			dryRun.Passed++
+		}
+		dryRun.Results = append(dryRun.Results, *result)
+	}
+	return dryRun, nil
+}
+
+// ReviewTestCase approves or rejects a batch and appends the decision to its
+// audit trail. Only approved batches are used for judging.
+func (r *Question) ReviewTestCase(testCaseId string, status string, reviewerId string, comment string) (*models.TestCase, error) {
+	if status != models.ReviewApproved && status != models.ReviewRejected {
+		return nil, fmt.Errorf("status must be %s or %s", models.ReviewApproved, models.ReviewRejected)
+	}
+	if status == models.ReviewRejected && comment == "" {
+		return nil, errors.New("a comment is required to reject test cases")
+	}
+	review := models.TestCaseReview{
+		Status:     status,
+		ReviewerID: reviewerId,
+		Comment:    comment,
+		CreatedAt:  time.Now(),
+	}
+	result, err := db.TestCasesCollection.UpdateOne(context.TODO(), bson.M{"_id": testCaseId}, bson.M{
+		"$set": bson.M{
+			"approved":     status == models.ReviewApproved,
+			"reviewStatus": status,
+			"updatedAt":    time.Now(),
+		},
+		"$push": bson.M{"reviews": review},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, fmt.Errorf("no test case found with ID: %s", testCaseId)
+	}
+	return r.GetTestCasesById(testCaseId)
+}
@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"code-compiler/db"
+	"code-compiler/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// rollbackSkippedFields are never restored by a rollback: identity, slug,
+// bookkeeping and the lifecycle stay as they are now. The statement HTML is
+// rendered again instead of taken from the revision.
+var rollbackSkippedFields = []string{
+	"_id", "slug", "previousSlugs", "revision", "createdAt", "updatedAt",
+	"users", "userStatus", "submissionCount", "successRate", "stats",
+	"lifecycle", "publishAt", "archivedFrom", "archivedAt",
+	"descriptionHtml", "editorialHtml",
+}
+
+// snapshotOf copies the question without per-user state and statistics
+func snapshotOf(question *models.Question) *models.Question {
+	snapshot := *question
+	snapshot.Users = nil
+	snapshot.UserStatus = ""
+	snapshot.SubmissionCount = 0
+	snapshot.SuccessRate = 0
+	snapshot.Stats = nil
+	return &snapshot
+}
+
+func saveRevision(question *models.Question, author string, message string) error {
+	revision := models.QuestionRevision{
+		ID:         fmt.Sprintf("%s-%d", question.ID, question.Revision),
+		QuestionID: question.ID,
+		Revision:   question.Revision,
+		Author:     author,
+		Message:    message,
+		Snapshot:   snapshotOf(question),
+		CreatedAt:  time.Now(),
+	}
+	_, err := db.RevisionsCollection.InsertOne(context.TODO(), revision)
+	return err
+}
+
+// updateQuestionContent applies the changes as a new revision. The update only
+// goes through if nobody saved another revision in the meantime.
+func (r *Question) updateQuestionContent(questionID string, changes bson.M, author string, message string) (*models.Question, error) {
+	return r.replaceQuestionContent(questionID, changes, nil, author, message)
+}
+
+// replaceQuestionContent sets the changes and removes the cleared fields as a
+// new revision, like updateQuestionContent
+func (r *Question) replaceQuestionContent(questionID string, changes bson.M, cleared []string, author string, message string) (*models.Question, error) {
+	current, err := r.GetQuestionById(questionID)
+	if err != nil {
+		return nil, err
+	}
+	if current.Revision == 0 {
+		// Questions created before revisions existed get their current state as revision 1
+		current.Revision = 1
+		if _, err := db.QuestionsCollection.UpdateOne(context.TODO(), bson.M{"_id": questionID, "revision": bson.M{"$in": bson.A{0, nil}}}, bson.M{
+			"$set": bson.M{"revision": 1},
+		}); err != nil {
+			return nil, err
+		}
+		if err := saveRevision(current, current.CreatedBy, "revision history started"); err != nil && !mongo.IsDuplicateKeyError(err) {
+			return nil, err
+		}
+	}
+	delete(changes, "_id")
+	delete(changes, "revision")
+	changes["updatedAt"] = time.Now()
+	update := bson.M{"$set": changes, "$inc": bson.M{"revision": 1}}
+	if len(cleared) > 0 {
+		unset := bson.M{}
+		for _, field := range cleared {
+			unset[field] = ""
+		}
+		update["$unset"] = unset
+	}
+	var question models.Question
+	err = db.QuestionsCollection.FindOneAndUpdate(
+		context.TODO(),
+		bson.M{"_id": questionID, "revision": current.Revision},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&question)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.New("the question was changed by someone else, reload it and try again")
+	}
+	if err != nil {
+		return nil, err
+	}
+	indexQuestion(&question)
+	if err := saveRevision(&question, author, message); err != nil {
+		return nil, fmt.Errorf("question updated but its revision could not be saved: %v", err)
+	}
+	return &question, nil
+}
+
+// GetQuestionRevisions lists the revisions of a question, newest first, without snapshots
+func (r *Question) GetQuestionRevisions(questionID string) ([]models.QuestionRevision, error) {
+	var revisions []models.QuestionRevision
+	cursor, err := db.RevisionsCollection.Find(context.TODO(), bson.M{"questionId": questionID},
+		options.Find().SetSort(bson.M{"revision": -1}).SetProjection(bson.M{"snapshot": 0}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(context.TODO(), &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// GetQuestionRevision returns one revision with its snapshot
+func (r *Question) GetQuestionRevision(questionID string, revision int) (*models.QuestionRevision, error) {
+	var questionRevision models.QuestionRevision
+	err := db.RevisionsCollection.FindOne(context.TODO(), bson.M{"questionId": questionID, "revision": revision}).Decode(&questionRevision)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("question %s has no revision %d", questionID, revision)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &questionRevision, nil
+}
+
+// DiffQuestionRevisions lists the fields that differ between two revisions.
+// Nested objects such as code templates are compared field by field.
+func (r *Question) DiffQuestionRevisions(questionID string, from int, to int) ([]models.FieldChange, error) {
+	fromRevision, err := r.GetQuestionRevision(questionID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := r.GetQuestionRevision(questionID, to)
+	if err != nil {
+		return nil, err
+	}
+	fromFields, err := flattenQuestion(fromRevision.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	toFields, err := flattenQuestion(toRevision.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	ignored := map[string]bool{"revision": true, "updatedAt": true}
+	changes := []models.FieldChange{}
+	for field, fromValue := range fromFields {
+		if toValue := toFields[field]; !ignored[field] && !reflect.DeepEqual(fromValue, toValue) {
+			changes = append(changes, models.FieldChange{Field: field, From: fromValue, To: toValue})
+		}
+	}
+	for field, toValue := range toFields {
+		if _, ok := fromFields[field]; !ok && !ignored[field] {
+			changes = append(changes, models.FieldChange{Field: field, To: toValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+// flattenQuestion maps every leaf field of the question's JSON form to its
+// dotted path, arrays are kept whole
+func flattenQuestion(question *models.Question) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(question)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+	flat := map[string]interface{}{}
+	var walk func(prefix string, value interface{})
+	walk = func(prefix string, value interface{}) {
+		if object, ok := value.(map[string]interface{}); ok && len(object) > 0 {
+			for key, child := range object {
+				walk(prefix+"."+key, child)
+			}
+			return
+		}
+		flat[prefix[1:]] = value
+	}
+	for key, value := range fields {
+		walk("."+key, value)
+	}
+	return flat, nil
+}
+
+// questionFields lists the document keys of every stored question field
+func questionFields() []string {
+	var fields []string
+	questionType := reflect.TypeOf(models.Question{})
+	for i := 0; i < questionType.NumField(); i++ {
+		field := questionType.Field(i)
+		name := strings.Split(field.Tag.Get("bson"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields = append(fields, name)
+	}
+	return fields
+}
+
+// rollbackContent returns the fields a rollback to the snapshot sets and the
+// ones it clears. Fields left empty in the snapshot are omitted when it is
+// encoded, they must be cleared or the newer values would stay.
+func rollbackContent(snapshot *models.Question) (bson.M, []string, error) {
+	encoded, err := bson.Marshal(snapshot)
+	if err != nil {
+		return nil, nil, err
+	}
+	var content bson.M
+	if err := bson.Unmarshal(encoded, &content); err != nil {
+		return nil, nil, err
+	}
+	skipped := map[string]bool{}
+	for _, field := range rollbackSkippedFields {
+		skipped[field] = true
+		delete(content, field)
+	}
+	var cleared []string
+	for _, field := range questionFields() {
+		if _, ok := content[field]; !ok && !skipped[field] {
+			cleared = append(cleared, field)
+		}
+	}
+	return content, cleared, nil
+}
+
+// RollbackQuestion restores the content of an earlier revision as a new revision
+func (r *Question) RollbackQuestion(questionID string, revision int, author string) (*models.Question, error) {
+	target, err := r.GetQuestionRevision(questionID, revision)
+	if err != nil {
+		return nil, err
+	}
+	content, cleared, err := rollbackContent(target.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if err := renderRollback(target.Snapshot, content); err != nil {
+		return nil, err
+	}
+	return r.replaceQuestionContent(questionID, content, cleared, author, fmt.Sprintf("rolled back to revision %d", revision))
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"code-compiler/internal/models"
+	"sort"
+	"testing"
+)
+
+func TestRollbackContent(t *testing.T) {
+	snapshot := &models.Question{
+		ID:          "7",
+		Title:       "Two Sum",
+		Slug:        "two-sum",
+		Description: "Find two numbers.",
+		Difficulty:  models.DifficultyEasy,
+		Revision:    3,
+	}
+	content, cleared, err := rollbackContent(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content["title"] != "Two Sum" || content["description"] != "Find two numbers." {
+		t.Errorf("content = %v, want the snapshot's title and description", content)
+	}
+	sort.Strings(cleared)
+	isCleared := map[string]bool{}
+	for _, field := range cleared {
+		isCleared[field] = true
+		if _, ok := content[field]; ok {
+			t.Errorf("field %q is both set and cleared", field)
+		}
+	}
+	// Empty in the snapshot and omitted when encoded, newer values must go
+	for _, field := range []string{"testGroups", "signature", "editorial", "translations", "solutionLanguage", "unlockAt"} {
+		if !isCleared[field] {
+			t.Errorf("%q is not cleared, cleared fields are %v", field, cleared)
+		}
+	}
+	for _, field := range rollbackSkippedFields {
+		if isCleared[field] {
+			t.Errorf("skipped field %q is cleared", field)
+		}
+		if _, ok := content[field]; ok {
+			t.Errorf("skipped field %q is restored", field)
+		}
+	}
+	for _, field := range []string{"availableLocales", "snippet"} {
+		if isCleared[field] {
+			t.Errorf("unstored field %q is cleared", field)
+		}
+	}
+}
+
+func TestFlattenQuestion(t *testing.T) {
+	question := &models.Question{
+		Title: "Two Sum",
+		Tags:  []string{"array"},
+		CodeTemplates: map[string]models.CodeTemplate{
+			"go": {Template: "func twoSum() {}"},
+		},
+	}
+	fields, err := flattenQuestion(question)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		field string
+		want  interface{}
+	}{
+		{"title", "Two Sum"},
+		{"codeTemplates.go.template", "func twoSum() {}"},
+	}
+	for _, test := range tests {
+		if fields[test.field] != test.want {
+			t.Errorf("%s = %v, want %v", test.field, fields[test.field], test.want)
+		}
+	}
+	if tags, ok := fields["tags"].([]interface{}); !ok || len(tags) != 1 {
+		t.Errorf("tags = %v, want the array kept whole", fields["tags"])
+	}
+}
+
+func TestRenderRollback(t *testing.T) {
+	tests := []struct {
+		name            string
+		snapshot        models.Question
+		descriptionHtml string
+		editorialHtml   string
+		translationHtml string
+	}{
+		{
+			"revision saved before rendering",
+			models.Question{Description: "Add **two** numbers."},
+			"<p>Add <strong>two</strong> numbers.</p>\n", "", "",
+		},
+		{
+			"stale cached html",
+			models.Question{
+				Description: "New *text*", DescriptionHTML: "<p>old</p>",
+				Editorial: "Use a map.", EditorialHTML: "<p>stale</p>",
+				Translations: map[string]models.Translation{"fr": {Title: "Somme", Description: "Un *mot*", DescriptionHTML: "<p>ancien</p>"}},
+			},
+			"<p>New <em>text</em></p>\n", "<p>Use a map.</p>\n", "<p>Un <em>mot</em></p>\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content, _, err := rollbackContent(&test.snapshot)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := renderRollback(&test.snapshot, content); err != nil {
+				t.Fatal(err)
+			}
+			if content["descriptionHtml"] != test.descriptionHtml || content["editorialHtml"] != test.editorialHtml {
+				t.Errorf("html = %q %q, want %q %q", content["descriptionHtml"], content["editorialHtml"], test.descriptionHtml, test.editorialHtml)
+			}
+			if test.translationHtml == "" {
+				if _, ok := content["translations"]; ok {
+					t.Errorf("translations = %v, want none restored", content["translations"])
+				}
+				return
+			}
+			translations, _ := content["translations"].(map[string]models.Translation)
+			if html := translations["fr"].DescriptionHTML; html != test.translationHtml {
+				t.Errorf("translation html = %q, want %q", html, test.translationHtml)
+			}
+		})
+	}
+}
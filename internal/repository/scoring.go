@@ -0,0 +1,181 @@
+package repository
+
+import (
+	commontypes "code-compiler/internal/commonTypes"
+	"code-compiler/internal/models"
+	"errors"
+	"fmt"
+)
+
+// judgeCase is a hidden test case with the visibility of the batch it came from
+type judgeCase struct {
+	models.InputOutput
+	Visibility string
+}
+
+// testGroupRun is a test group together with the hidden test cases assigned to it
+type testGroupRun struct {
+	models.TestGroup
+	TestCases []judgeCase
+}
+
+func batchCases(batch models.TestCase) []judgeCase {
+	cases := make([]judgeCase, 0, len(batch.IOPairs))
+	for _, pair := range batch.IOPairs {
+		cases = append(cases, judgeCase{InputOutput: pair, Visibility: batch.Visibility})
+	}
+	return cases
+}
+
+// validateTestGroups checks group names, points and scoring policies. A group
+// may only depend on groups declared before it, which keeps the order acyclic.
+func validateTestGroups(groups []models.TestGroup) error {
+	seen := map[string]bool{}
+	for _, group := range groups {
+		if group.Name == "" {
+			return errors.New("every test group needs a name")
+		}
+		if err := models.ValidateGroupName(group.Name); err != nil {
+			return err
+		}
+		if seen[group.Name] {
+			return fmt.Errorf("test group %q is declared twice", group.Name)
+		}
+		if group.Points < 0 {
+			return fmt.Errorf("test group %q has negative points", group.Name)
+		}
+		if group.Scoring != "" && group.Scoring != models.ScoringAllOrNothing && group.Scoring != models.ScoringProportional {
+			return fmt.Errorf("test group %q has unknown scoring %q", group.Name, group.Scoring)
+		}
+		for _, dependency := range group.Dependencies {
+			if !seen[dependency] {
+				return fmt.Errorf("test group %q depends on %q which is not declared before it", group.Name, dependency)
+			}
+		}
+		seen[group.Name] = true
+	}
+	return nil
+}
+
+// buildTestGroups assigns the approved test case batches to the question's
+// test groups. A question without groups is judged as one all-or-nothing group,
+// and batches of undeclared groups are gathered in an extra group worth no points.
+func buildTestGroups(question *models.Question, batches []models.TestCase) []testGroupRun {
+	if len(question.TestGroups) == 0 {
+		group := testGroupRun{TestGroup: models.TestGroup{Name: "all", Points: 100, Scoring: models.ScoringAllOrNothing}}
+		for _, batch := range batches {
+			group.TestCases = append(group.TestCases, batchCases(batch)...)
+		}
+		return []testGroupRun{group}
+	}
+	index := map[string]int{}
+	groups := make([]testGroupRun, 0, len(question.TestGroups)+1)
+	for i, group := range question.TestGroups {
+		index[group.Name] = i
+		groups = append(groups, testGroupRun{TestGroup: group})
+	}
+	var ungrouped []judgeCase
+	for _, batch := range batches {
+		if i, ok := index[batch.Group]; ok {
+			groups[i].TestCases = append(groups[i].TestCases, batchCases(batch)...)
+		} else {
+			ungrouped = append(ungrouped, batchCases(batch)...)
+		}
+	}
+	if len(ungrouped) > 0 {
+		groups = append(groups, testGroupRun{
+			TestGroup: models.TestGroup{Name: "ungrouped", Scoring: models.ScoringAllOrNothing},
+			TestCases: ungrouped,
+		})
+	}
+	return groups
+}
+
+// scoreTestGroups runs the groups in order and scores each by its policy.
+// The verdict and failed case come from the first test case that did not pass.
+// A group without test cases earns nothing, and code passing every other
+// group gets no verdict since the question cannot be judged as configured.
+func scoreTestGroups(compiledFilePath string, groups []testGroupRun, language string) judgeOutcome {
+	outcome := judgeOutcome{}
+	fullScore := map[string]bool{}
+	emptyGroup := ""
+	caseNumber := 0
+	for _, group := range groups {
+		result := commontypes.GroupResult{
+			Name:     group.Name,
+			MaxScore: group.Points,
+			Total:    len(group.TestCases),
+		}
+		firstCase := caseNumber
+		caseNumber += len(group.TestCases)
+		outcome.Total += result.Total
+		outcome.MaxScore += result.MaxScore
+
+		for _, dependency := range group.Dependencies {
+			if !fullScore[dependency] {
+				result.Skipped = true
+			}
+		}
+		if result.Skipped {
+			outcome.Groups = append(outcome.Groups, result)
+			continue
+		}
+
+		stopOnFailure := group.Scoring != models.ScoringProportional
+		for i, testCase := range group.TestCases {
+			testResult, err := runTestCase(compiledFilePath, testCase.InputOutput, firstCase+i+1, language)
+			if testResult != nil {
+				outcome.Runtime = max(outcome.Runtime, testResult.TimeTaken)
+				outcome.Memory = max(outcome.Memory, testResult.MemoryUsed)
+			}
+			if err == nil && testResult.Passed {
+				result.Passed++
+				continue
+			}
+			if outcome.Verdict == "" {
+				switch {
+				case errors.Is(err, errTimedOut):
+					outcome.Verdict, outcome.Err = models.VerdictTimeLimitExceeded, err
+				case err != nil:
+					outcome.Verdict, outcome.Err = models.VerdictRuntimeError, err
+				default:
+					outcome.Verdict = models.VerdictWrongAnswer
+				}
+				if testResult == nil {
+					testResult = &commontypes.TestResult{
+						TestCaseNumber: firstCase + i + 1,
+						Input:          previewTestData(testCase.Input, testCase.InputBlob),
+						ExpectedOutput: previewTestData(testCase.Output, testCase.OutputBlob),
+					}
+				}
+				testResult.Visibility = testCase.Visibility
+				outcome.FailedCase = testResult
+			}
+			if stopOnFailure {
+				break
+			}
+		}
+
+		if result.Total == 0 {
+			if emptyGroup == "" {
+				emptyGroup = group.Name
+			}
+		} else if result.Passed == result.Total {
+			result.Score = result.MaxScore
+			fullScore[group.Name] = true
+		} else if group.Scoring == models.ScoringProportional {
+			result.Score = result.MaxScore * float64(result.Passed) / float64(result.Total)
+		}
+		outcome.Passed += result.Passed
+		outcome.Score += result.Score
+		outcome.Groups = append(outcome.Groups, result)
+	}
+	if outcome.Verdict == "" && emptyGroup != "" {
+		outcome.Err = fmt.Errorf("test group %q has no approved test cases", emptyGroup)
+		return outcome
+	}
+	if outcome.Verdict == "" {
+		outcome.Verdict = models.VerdictAccepted
+	}
+	return outcome
+}
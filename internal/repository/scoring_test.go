@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"code-compiler/internal/models"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// echoProgram writes a Python program printing its input back, so a test case
+// passes when its output equals its input
+func echoProgram(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "echo.py")
+	if err := os.WriteFile(path, []byte("import sys\nsys.stdout.write(sys.stdin.read())\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// echoCases builds test cases from input and output pairs
+func echoCases(pairs ...string) []judgeCase {
+	var testCases []judgeCase
+	for i := 0; i < len(pairs); i += 2 {
+		testCases = append(testCases, judgeCase{InputOutput: models.InputOutput{Input: pairs[i], Output: pairs[i+1]}})
+	}
+	return testCases
+}
+
+func TestScoreTestGroups(t *testing.T) {
+	program := echoProgram(t)
+	tests := []struct {
+		name     string
+		groups   []testGroupRun
+		verdict  string
+		score    float64
+		maxScore float64
+		passed   int
+		wantErr  bool
+	}{
+		{
+			name: "all groups pass",
+			groups: []testGroupRun{
+				{TestGroup: models.TestGroup{Name: "small", Points: 40}, TestCases: echoCases("1", "1", "2", "2")},
+				{TestGroup: models.TestGroup{Name: "large", Points: 60, Dependencies: []string{"small"}}, TestCases: echoCases("3", "3")},
+			},
+			verdict: models.VerdictAccepted, score: 100, maxScore: 100, passed: 3,
+		},
+		{
+			name: "failed dependency skips the dependent group",
+			groups: []testGroupRun{
+				{TestGroup: models.TestGroup{Name: "small", Points: 40}, TestCases: echoCases("1", "2")},
+				{TestGroup: models.TestGroup{Name: "large", Points: 60, Dependencies: []string{"small"}}, TestCases: echoCases("3", "3")},
+			},
+			verdict: models.VerdictWrongAnswer, score: 0, maxScore: 100, passed: 0,
+		},
+		{
+			name: "proportional group scores the passed share",
+			groups: []testGroupRun{
+				{TestGroup: models.TestGroup{Name: "all", Points: 100, Scoring: models.ScoringProportional}, TestCases: echoCases("1", "1", "2", "0", "3", "3", "4", "4")},
+			},
+			verdict: models.VerdictWrongAnswer, score: 75, maxScore: 100, passed: 3,
+		},
+		{
+			name: "empty group earns nothing and cannot be judged",
+			groups: []testGroupRun{
+				{TestGroup: models.TestGroup{Name: "all", Points: 100, Scoring: models.ScoringAllOrNothing}},
+			},
+			score: 0, maxScore: 100, wantErr: true,
+		},
+		{
+			name: "empty group blocks its dependents",
+			groups: []testGroupRun{
+				{TestGroup: models.TestGroup{Name: "small", Points: 40}},
+				{TestGroup: models.TestGroup{Name: "large", Points: 60, Dependencies: []string{"small"}}, TestCases: echoCases("3", "3")},
+			},
+			score: 0, maxScore: 100, wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			outcome := scoreTestGroups(program, test.groups, "py")
+			if outcome.Verdict != test.verdict {
+				t.Errorf("verdict = %q, want %q", outcome.Verdict, test.verdict)
+			}
+			if outcome.Score != test.score || outcome.MaxScore != test.maxScore {
+				t.Errorf("score = %v/%v, want %v/%v", outcome.Score, outcome.MaxScore, test.score, test.maxScore)
+			}
+			if outcome.Passed != test.passed {
+				t.Errorf("passed = %d, want %d", outcome.Passed, test.passed)
+			}
+			if (outcome.Err != nil) != test.wantErr {
+				t.Errorf("err = %v, want error %v", outcome.Err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTestGroups(t *testing.T) {
+	tests := []struct {
+		name    string
+		groups  []models.TestGroup
+		wantErr bool
+	}{
+		{"valid", []models.TestGroup{{Name: "a", Points: 50}, {Name: "b", Points: 50, Dependencies: []string{"a"}}}, false},
+		{"unnamed", []models.TestGroup{{Points: 10}}, true},
+		{"duplicate", []models.TestGroup{{Name: "a"}, {Name: "a"}}, true},
+		{"negative points", []models.TestGroup{{Name: "a", Points: -1}}, true},
+		{"unknown scoring", []models.TestGroup{{Name: "a", Scoring: "best_of"}}, true},
+		{"forward dependency", []models.TestGroup{{Name: "a", Dependencies: []string{"b"}}, {Name: "b"}}, true},
+		{"slug names", []models.TestGroup{{Name: "group-1"}, {Name: "9_large"}}, false},
+		{"parent directory", []models.TestGroup{{Name: "../../x"}}, true},
+		{"nested directory", []models.TestGroup{{Name: "a/b"}}, true},
+		{"mongo key characters", []models.TestGroup{{Name: "a.b"}, {Name: "$a"}}, true},
+		{"uppercase", []models.TestGroup{{Name: "Small"}}, true},
+		{"leading dash", []models.TestGroup{{Name: "-a"}}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateTestGroups(test.groups); (err != nil) != test.wantErr {
+				t.Errorf("validateTestGroups() = %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
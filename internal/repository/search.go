@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"code-compiler/db"
+	"code-compiler/internal/models"
+	"code-compiler/internal/search"
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxSearchResults bounds the questions a search lists. The cut is made after
+// the database has applied lifecycle and status, so hidden matches take no place.
+const maxSearchResults = 1000
+
+func searchDocument(question *models.Question) search.Document {
+	doc := search.Document{
+		ID:          question.ID,
+		Title:       question.Title,
+		Description: question.Description,
+		Tags:        question.Tags,
+		Difficulty:  question.Difficulty,
+	}
+	// Translated statements match as well, in a stable order
+	for _, locale := range question.Locales()[1:] {
+		translation := question.Translations[locale]
+		doc.Translations = append(doc.Translations, search.Translation{Title: translation.Title, Description: translation.Description})
+	}
+	return doc
+}
+
+// indexQuestion brings the search index up to date with a saved question.
+// Lifecycle and visibility are left to the database query.
+func indexQuestion(question *models.Question) {
+	search.Questions.Add(searchDocument(question))
+}
+
+// BuildSearchIndex loads every question into the search index
+func (r *Question) BuildSearchIndex() error {
+	var questions []models.Question
+	cursor, err := db.QuestionsCollection.Find(context.TODO(), bson.M{}, options.Find().SetProjection(bson.M{
+		"title": 1, "description": 1, "tags": 1, "difficulty": 1, "locale": 1, "translations": 1,
+	}))
+	if err != nil {
+		return err
+	}
+	if err = cursor.All(context.TODO(), &questions); err != nil {
+		return err
+	}
+	docs := make([]search.Document, 0, len(questions))
+	for i := range questions {
+		docs = append(docs, searchDocument(&questions[i]))
+	}
+	search.Questions.Replace(docs)
+	return nil
+}
+
+// StartSearchIndexRefresher rebuilds the search index every interval, picking
+// up changes saved by other instances, until the context is done
+func (r *Question) StartSearchIndexRefresher(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := r.BuildSearchIndex(); err != nil {
+					log.Println("Search index refresh:", err)
+				}
+			}
+		}
+	}()
+}
+
+// rankedSearch runs the full-text query and returns the ids best first with
+// their snippets. The query tags are canonical, aliases resolve to them.
+// Every match is returned, searchLimitStages cuts them to maxSearchResults.
+func rankedSearch(query models.QuestionQuery, aliases map[string]string) ([]string, map[string]string) {
+	filter := search.Filter{Tags: query.Tags, Aliases: aliases, Difficulties: query.Difficulties}
+	results := search.Questions.Search(query.Search, filter)
+	ids := make([]string, 0, len(results))
+	snippets := map[string]string{}
+	for _, result := range results {
+		ids = append(ids, result.ID)
+		snippets[result.ID] = result.Snippet
+	}
+	return ids, snippets
+}
+
+// searchLimitStages keeps the best maxSearchResults of the questions left
+// after the catalog filters, ranked by their place in rankedIds
+func searchLimitStages(rankedIds []string) []bson.M {
+	return []bson.M{
+		{"$addFields": bson.M{"searchRank": bson.M{"$indexOfArray": bson.A{rankedIds, "$_id"}}}},
+		{"$sort": bson.D{{Key: "searchRank", Value: 1}, {Key: "_id", Value: 1}}},
+		{"$limit": maxSearchResults},
+	}
+}
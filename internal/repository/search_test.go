@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"code-compiler/internal/models"
+	"code-compiler/internal/search"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestSearchDocument(t *testing.T) {
+	tests := []struct {
+		name     string
+		question models.Question
+		want     []search.Translation
+	}{
+		{"untranslated", models.Question{ID: "1", Title: "Sum"}, nil},
+		{
+			"translations sorted by locale",
+			models.Question{ID: "1", Title: "Sum", Locale: "en", Translations: map[string]models.Translation{
+				"hi": {Title: "योग", Description: "जोड़ें।", DescriptionHTML: "<p>जोड़ें।</p>"},
+				"fr": {Title: "Somme", Description: "Additionnez."},
+			}},
+			[]search.Translation{{Title: "Somme", Description: "Additionnez."}, {Title: "योग", Description: "जोड़ें।"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc := searchDocument(&test.question)
+			if doc.ID != test.question.ID || doc.Title != test.question.Title {
+				t.Errorf("document = %#v", doc)
+			}
+			if !reflect.DeepEqual(doc.Translations, test.want) {
+				t.Errorf("translations = %#v, want %#v", doc.Translations, test.want)
+			}
+		})
+	}
+}
+
+func TestRankedSearchKeepsEveryMatch(t *testing.T) {
+	docs := make([]search.Document, 0, maxSearchResults+10)
+	for i := 0; i < maxSearchResults+10; i++ {
+		docs = append(docs, search.Document{ID: strconv.Itoa(i + 1), Title: "Graph walk " + strconv.Itoa(i)})
+	}
+	previous := search.Questions
+	search.Questions = search.NewIndex()
+	defer func() { search.Questions = previous }()
+	search.Questions.Replace(docs)
+
+	tests := []struct {
+		name   string
+		query  models.QuestionQuery
+		wanted int
+	}{
+		{"more matches than listed", models.QuestionQuery{Search: "graph"}, maxSearchResults + 10},
+		{"no match", models.QuestionQuery{Search: "string"}, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ids, snippets := rankedSearch(test.query, nil)
+			if len(ids) != test.wanted || len(snippets) != test.wanted {
+				t.Errorf("got %d ids and %d snippets, want %d", len(ids), len(snippets), test.wanted)
+			}
+		})
+	}
+}
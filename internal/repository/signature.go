@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"code-compiler/internal/codegen"
+	"code-compiler/internal/models"
+)
+
+// signatureTemplates generates the code templates of every language from the
+// signature. Templates sent along replace the generated one of their language,
+// so a setter can still adjust a single language by hand.
+func signatureTemplates(signature *models.FunctionSignature, custom map[string]models.CodeTemplate) (map[string]models.CodeTemplate, error) {
+	if err := signature.Validate(); err != nil {
+		return nil, err
+	}
+	templates, err := codegen.Generate(*signature)
+	if err != nil {
+		return nil, err
+	}
+	for language, template := range custom {
+		templates[language] = template
+	}
+	return templates, nil
+}
+
+// GenerateTemplates returns the templates of a signature without saving them
+func (r *Question) GenerateTemplates(signature *models.FunctionSignature) (map[string]models.CodeTemplate, error) {
+	return signatureTemplates(signature, nil)
+}
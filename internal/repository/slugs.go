@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"code-compiler/db"
+	"code-compiler/internal/models"
+	"code-compiler/internal/utils"
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxSlugSuffix bounds the search for a free numbered slug
+const maxSlugSuffix = 1000
+
+// slugTaken reports whether another question uses the slug now or used it
+// before. Old slugs stay reserved so their redirects keep working.
+func slugTaken(slug string, questionID string) (bool, error) {
+	count, err := db.QuestionsCollection.CountDocuments(context.TODO(), bson.M{
+		"$or": bson.A{bson.M{"slug": slug}, bson.M{"previousSlugs": slug}},
+		"_id": bson.M{"$ne": questionID},
+	})
+	return count > 0, err
+}
+
+// slugBase is the slug generated from the title. Titles in scripts that are
+// not transliterated fall back to the question id, so the slug stays the same
+// as long as the title does.
+func slugBase(title string, questionID string) string {
+	if base := utils.MakeSlug(title); base != "" {
+		return base
+	}
+	return "question-" + questionID
+}
+
+// uniqueSlug returns the base slug, or its first free numbered variant when
+// other questions have the same title
+func uniqueSlug(base string, questionID string) (string, error) {
+	for n := 1; n <= maxSlugSuffix; n++ {
+		candidate := utils.SlugWithSuffix(base, n)
+		taken, err := slugTaken(candidate, questionID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no free slug left for %s", base)
+}
+
+// customSlug checks a slug chosen by an admin, it is used as is or rejected
+func customSlug(slug string, questionID string) (string, error) {
+	if !utils.IsValidSlug(slug) {
+		return "", fmt.Errorf("slug %q must be lowercase letters and digits joined by single hyphens", slug)
+	}
+	taken, err := slugTaken(slug, questionID)
+	if err != nil {
+		return "", err
+	}
+	if taken {
+		return "", fmt.Errorf("slug %s is already used by another question", slug)
+	}
+	return slug, nil
+}
+
+// nextSlug works out the slug of a question after the patch. A custom slug in
+// the patch wins; a new title only moves slugs that were generated from the
+// old title, custom slugs stay.
+func nextSlug(current *models.Question, patch *models.QuestionPatch) (string, error) {
+	if patch.Slug != nil {
+		if *patch.Slug == current.Slug {
+			return current.Slug, nil
+		}
+		return customSlug(*patch.Slug, current.ID)
+	}
+	if patch.Title == nil {
+		return current.Slug, nil
+	}
+	oldBase := slugBase(current.Title, current.ID)
+	// Slugs from before transliteration were cut at 30 characters
+	generated := utils.IsSlugVariant(current.Slug, oldBase) || strings.HasPrefix(oldBase, current.Slug)
+	newBase := slugBase(*patch.Title, current.ID)
+	if !generated || utils.IsSlugVariant(current.Slug, newBase) {
+		return current.Slug, nil
+	}
+	return uniqueSlug(newBase, current.ID)
+}
+
+// previousSlugsAfter keeps the slug being replaced as a redirect
+func previousSlugsAfter(current *models.Question, slug string) []string {
+	previous := []string{}
+	for _, old := range current.PreviousSlugs {
+		if old != slug && old != current.Slug {
+			previous = append(previous, old)
+		}
+	}
+	return append(previous, current.Slug)
+}
+
+// FindSlugRedirect returns the current slug of the question that used the
+// slug before, or an empty string when no question did
+func (r *Question) FindSlugRedirect(slug string) (string, error) {
+	var question models.Question
+	err := db.QuestionsCollection.FindOne(context.TODO(), bson.M{"previousSlugs": slug}).Decode(&question)
+	if err == mongo.ErrNoDocuments {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return question.Slug, nil
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"code-compiler/internal/models"
+	"testing"
+)
+
+func TestSlugBase(t *testing.T) {
+	tests := []struct {
+		title string
+		id    string
+		want  string
+	}{
+		{"Two Sum", "7", "two-sum"},
+		{"दो का योग", "7", "do-kaa-yog"},
+		{"二数之和", "7", "question-7"},
+		{"", "12", "question-12"},
+	}
+	for _, test := range tests {
+		if got := slugBase(test.title, test.id); got != test.want {
+			t.Errorf("slugBase(%q, %q) = %q, want %q", test.title, test.id, got, test.want)
+		}
+	}
+}
+
+func TestNextSlugKeepsSlugs(t *testing.T) {
+	title := func(s string) *string { return &s }
+	tests := []struct {
+		name    string
+		current models.Question
+		patch   models.QuestionPatch
+	}{
+		{"no new title", models.Question{ID: "1", Title: "Two Sum", Slug: "two-sum"}, models.QuestionPatch{}},
+		{"custom slug", models.Question{ID: "1", Title: "Two Sum", Slug: "pairs"}, models.QuestionPatch{Title: title("Three Sum")}},
+		{"same base", models.Question{ID: "1", Title: "Two Sum", Slug: "two-sum-2"}, models.QuestionPatch{Title: title("Two  sum!")}},
+		{"untransliterated title", models.Question{ID: "1", Title: "二数之和", Slug: "question-1"}, models.QuestionPatch{Title: title("三数之和")}},
+		{"same slug sent", models.Question{ID: "1", Title: "Two Sum", Slug: "two-sum"}, models.QuestionPatch{Slug: title("two-sum")}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			slug, err := nextSlug(&test.current, &test.patch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if slug != test.current.Slug {
+				t.Errorf("nextSlug() = %q, want %q", slug, test.current.Slug)
+			}
+		})
+	}
+}
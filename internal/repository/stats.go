@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"code-compiler/db"
+	"code-compiler/internal/models"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// successRateUpdate recomputes the success rate from the stored counters, as
+// an update pipeline so it always reads the latest counts
+var successRateUpdate = bson.A{
+	bson.M{"$set": bson.M{
+		"submissionCount": bson.M{"$ifNull": bson.A{"$stats.submissions", 0}},
+		"successRate": bson.M{"$cond": bson.A{
+			bson.M{"$gt": bson.A{"$stats.submissions", 0}},
+			bson.M{"$round": bson.A{bson.M{"$multiply": bson.A{bson.M{"$divide": bson.A{bson.M{"$ifNull": bson.A{"$stats.accepted", 0}}, "$stats.submissions"}}, 100}}, 2}},
+			0,
+		}},
+	}},
+}
+
+// statsLanguage is the key a language is counted under in the statistics. It
+// becomes part of a field path, so unsupported languages count as other.
+func statsLanguage(language string) string {
+	if !models.IsSupportedLanguage(language) {
+		return "other"
+	}
+	return language
+}
+
+// recordSubmission counts a judged submission in the question's statistics and
+// records the user's status. The first accepted submission of a user counts
+// them as a solver, in the same update that marks them solved.
+func recordSubmission(submission *models.CodeSubmission) error {
+	accepted := submission.Verdict == models.VerdictAccepted
+	language := statsLanguage(submission.Language)
+	inc := bson.M{
+		"stats.submissions":                    1,
+		"stats.languages." + language:          1,
+		"stats.verdicts." + submission.Verdict: 1,
+	}
+	if accepted {
+		inc["stats.accepted"] = 1
+	}
+	result, err := db.QuestionsCollection.UpdateOne(context.TODO(), bson.M{"_id": submission.Question}, bson.M{
+		"$inc": inc,
+		"$set": bson.M{"stats.updatedAt": time.Now()},
+	})
+	if err != nil {
+		return fmt.Errorf("error updating question statistics: %v", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no question found with ID: %s", submission.Question)
+	}
+	if accepted {
+		_, err = db.QuestionsCollection.UpdateOne(context.TODO(), bson.M{
+			"_id":                        submission.Question,
+			"users." + submission.UserId: bson.M{"$ne": "solved"},
+		}, bson.M{
+			"$set": bson.M{"users." + submission.UserId: "solved"},
+			"$inc": bson.M{"stats.solvers": 1},
+		})
+	} else {
+		err = SaveUserIdInQuestion(submission.Question, submission.UserId, "attempted")
+	}
+	if err != nil {
+		return err
+	}
+	_, err = db.QuestionsCollection.UpdateOne(context.TODO(), bson.M{"_id": submission.Question}, successRateUpdate)
+	return err
+}
+
+// statsAccumulator collects the statistics and performance distributions of
+// one question from its submissions
+type statsAccumulator struct {
+	stats       models.QuestionStats
+	solvers     map[string]bool
+	performance map[string]*models.PerformanceDistribution
+}
+
+func (a *statsAccumulator) add(submission *models.CodeSubmission) {
+	if a.solvers == nil {
+		a.solvers = map[string]bool{}
+		a.stats.Languages = map[string]int64{}
+		a.stats.Verdicts = map[string]int64{}
+		a.performance = map[string]*models.PerformanceDistribution{}
+	}
+	verdict := submissionVerdict(submission)
+	a.stats.Submissions++
+	a.stats.Languages[statsLanguage(submission.Language)]++
+	a.stats.Verdicts[verdict]++
+	if verdict == models.VerdictAccepted {
+		a.stats.Accepted++
+		a.solvers[submission.UserId] = true
+		// Submissions judged before usage was measured have no runtime
+		if submission.Runtime > 0 || submission.Memory > 0 {
+			a.addPerformance(submission)
+		}
+	}
+	a.stats.Solvers = int64(len(a.solvers))
+}
+
+func (a *statsAccumulator) addPerformance(submission *models.CodeSubmission) {
+	distribution := a.performance[submission.Language]
+	if distribution == nil {
+		distribution = &models.PerformanceDistribution{
+			ID:         models.PerformanceID(submission.Question, submission.Language),
+			QuestionID: submission.Question,
+			Language:   submission.Language,
+		}
+		a.performance[submission.Language] = distribution
+	}
+	distribution.Runtime.Add(submission.Runtime)
+	if submission.Memory > 0 {
+		distribution.Memory.Add(submission.Memory)
+	}
+}
+
+// saveQuestionStats replaces the statistics of a question
+func saveQuestionStats(questionId string, stats models.QuestionStats) error {
+	stats.UpdatedAt = time.Now()
+	_, err := db.QuestionsCollection.UpdateOne(context.TODO(), bson.M{"_id": questionId}, bson.M{"$set": bson.M{
+		"stats":           stats,
+		"submissionCount": stats.Submissions,
+		"successRate":     stats.SuccessRate(),
+	}})
+	return err
+}
+
+// submissionStatsProjection leaves out the code, only the verdict is counted
+var submissionStatsProjection = bson.M{
+	"question": 1, "userId": 1, "language": 1, "verdict": 1, "err": 1,
+	"failedCase": 1, "passedTestCases": 1, "totalTestCases": 1, "runtime": 1, "memory": 1,
+}
+
+// RecomputeQuestionStats rebuilds the statistics of one question from its stored submissions
+func RecomputeQuestionStats(questionId string) (*models.QuestionStats, error) {
+	cursor, err := db.CodeSubmissionCollection.Find(context.TODO(), bson.M{"question": questionId}, options.Find().SetProjection(submissionStatsProjection))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+	var accumulator statsAccumulator
+	for cursor.Next(context.TODO()) {
+		var submission models.CodeSubmission
+		if err := cursor.Decode(&submission); err != nil {
+			return nil, err
+		}
+		accumulator.add(&submission)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	if err := saveQuestionStats(questionId, accumulator.stats); err != nil {
+		return nil, err
+	}
+	if err := savePerformance(questionId, accumulator.performance); err != nil {
+		return nil, err
+	}
+	return &accumulator.stats, nil
+}
+
+// BackfillQuestionStats recomputes the statistics of every question from the
+// codeSubmission collection and returns how many questions were updated.
+// Questions without submissions are reset to zero.
+func BackfillQuestionStats() (int, error) {
+	cursor, err := db.CodeSubmissionCollection.Find(context.TODO(), bson.M{}, options.Find().SetProjection(submissionStatsProjection))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(context.TODO())
+	accumulators := map[string]*statsAccumulator{}
+	for cursor.Next(context.TODO()) {
+		var submission models.CodeSubmission
+		if err := cursor.Decode(&submission); err != nil {
+			return 0, err
+		}
+		accumulator := accumulators[submission.Question]
+		if accumulator == nil {
+			accumulator = &statsAccumulator{}
+			accumulators[submission.Question] = accumulator
+		}
+		accumulator.add(&submission)
+	}
+	if err := cursor.Err(); err != nil {
+		return 0, err
+	}
+
+	var questions []models.Question
+	questionCursor, err := db.QuestionsCollection.Find(context.TODO(), bson.M{}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return 0, err
+	}
+	if err = questionCursor.All(context.TODO(), &questions); err != nil {
+		return 0, err
+	}
+	updated := 0
+	existing := map[string]bool{}
+	for _, question := range questions {
+		existing[question.ID] = true
+		accumulator := accumulators[question.ID]
+		if accumulator == nil {
+			accumulator = &statsAccumulator{}
+		}
+		if err := saveQuestionStats(question.ID, accumulator.stats); err != nil {
+			return updated, fmt.Errorf("question %s: %v", question.ID, err)
+		}
+		if err := savePerformance(question.ID, accumulator.performance); err != nil {
+			return updated, fmt.Errorf("question %s: %v", question.ID, err)
+		}
+		updated++
+	}
+	for questionId := range accumulators {
+		if !existing[questionId] {
+			log.Printf("Stats backfill: submissions of missing question %s were skipped", questionId)
+		}
+	}
+	return updated, nil
+}
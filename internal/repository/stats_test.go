@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"code-compiler/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestStatsLanguage(t *testing.T) {
+	tests := []struct {
+		language string
+		want     string
+	}{
+		{"go", "go"},
+		{"py", "py"},
+		{"rust", "other"},
+		{"", "other"},
+		{"go.mod", "other"},
+		{"$where", "other"},
+	}
+	for _, test := range tests {
+		if got := statsLanguage(test.language); got != test.want {
+			t.Errorf("statsLanguage(%q) = %q, want %q", test.language, got, test.want)
+		}
+	}
+}
+
+func TestStatsAccumulator(t *testing.T) {
+	submissions := []models.CodeSubmission{
+		{UserId: "a", Language: "go", Verdict: models.VerdictWrongAnswer},
+		{UserId: "a", Language: "go", Verdict: models.VerdictAccepted, Runtime: 10},
+		{UserId: "a", Language: "py", Verdict: models.VerdictAccepted},
+		{UserId: "b", Language: "rust", Verdict: models.VerdictCompilationError},
+		{UserId: "b", Language: "a.b", Verdict: models.VerdictAccepted},
+	}
+	var accumulator statsAccumulator
+	for i := range submissions {
+		accumulator.add(&submissions[i])
+	}
+	stats := accumulator.stats
+	if stats.Submissions != 5 || stats.Accepted != 3 || stats.Solvers != 2 {
+		t.Errorf("submissions, accepted, solvers = %d, %d, %d, want 5, 3, 2", stats.Submissions, stats.Accepted, stats.Solvers)
+	}
+	// Backfilled counts use the same keys as the ones recordSubmission increments
+	wantLanguages := map[string]int64{"go": 2, "py": 1, "other": 2}
+	if !reflect.DeepEqual(stats.Languages, wantLanguages) {
+		t.Errorf("languages = %v, want %v", stats.Languages, wantLanguages)
+	}
+	if len(accumulator.performance) != 1 || accumulator.performance["go"] == nil {
+		t.Errorf("performance = %v, want only go, the one accepted run with a runtime", accumulator.performance)
+	}
+}
@@ -0,0 +1,407 @@
+package repository
+
+import (
+	"code-compiler/db"
+	"code-compiler/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxTagDepth bounds the walk up the hierarchy, deeper chains are treated as cycles
+const maxTagDepth = 32
+
+func getTag(name string) (*models.Tag, error) {
+	var tag models.Tag
+	err := db.TagsCollection.FindOne(context.TODO(), bson.M{"_id": name}).Decode(&tag)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("tag %q not found", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// canonicalTags resolves aliases to their canonical tag, unknown tags are kept as they are
+func canonicalTags(tags []string) ([]string, error) {
+	tags = models.NormalizeTags(tags)
+	if len(tags) == 0 {
+		return tags, nil
+	}
+	var managed []models.Tag
+	cursor, err := db.TagsCollection.Find(context.TODO(), bson.M{"aliases": bson.M{"$in": tags}}, options.Find().SetProjection(bson.M{"aliases": 1}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(context.TODO(), &managed); err != nil {
+		return nil, err
+	}
+	return resolveAliases(tags, aliasMap(managed)), nil
+}
+
+// aliasMap maps the aliases of the tags to their canonical name
+func aliasMap(managed []models.Tag) map[string]string {
+	canonical := map[string]string{}
+	for _, tag := range managed {
+		for _, alias := range tag.Aliases {
+			canonical[alias] = tag.ID
+		}
+	}
+	return canonical
+}
+
+// resolveAliases replaces the aliases among normalized tags by their canonical name
+func resolveAliases(tags []string, aliases map[string]string) []string {
+	resolved := make([]string, len(tags))
+	for i, tag := range tags {
+		if name, ok := aliases[tag]; ok {
+			tag = name
+		}
+		resolved[i] = tag
+	}
+	return models.NormalizeTags(resolved)
+}
+
+// tagAliases maps every alias in the taxonomy to its canonical tag. Questions
+// keep an alias until they are retagged, so filters match both names.
+func tagAliases() (map[string]string, error) {
+	var managed []models.Tag
+	cursor, err := db.TagsCollection.Find(context.TODO(), bson.M{"aliases.0": bson.M{"$exists": true}}, options.Find().SetProjection(bson.M{"aliases": 1}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(context.TODO(), &managed); err != nil {
+		return nil, err
+	}
+	return aliasMap(managed), nil
+}
+
+// allTagsFilter matches questions having every canonical tag, under its name or an alias
+func allTagsFilter(tags []string, aliases map[string]string) bson.A {
+	names := map[string][]string{}
+	for _, tag := range tags {
+		names[tag] = []string{tag}
+	}
+	for alias, tag := range aliases {
+		if _, ok := names[tag]; ok {
+			names[tag] = append(names[tag], alias)
+		}
+	}
+	conditions := bson.A{}
+	for _, tag := range tags {
+		sort.Strings(names[tag][1:])
+		conditions = append(conditions, bson.M{"tags": bson.M{"$in": names[tag]}})
+	}
+	return conditions
+}
+
+// tagNameTaken reports the tag already using a name or alias, other than the one being saved
+func tagNameTaken(names []string, except string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	var existing models.Tag
+	err := db.TagsCollection.FindOne(context.TODO(), bson.M{
+		"_id": bson.M{"$ne": except},
+		"$or": bson.A{
+			bson.M{"_id": bson.M{"$in": names}},
+			bson.M{"aliases": bson.M{"$in": names}},
+		},
+	}).Decode(&existing)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("tag %q already uses one of %v, merge the tags instead", existing.ID, names)
+}
+
+// validateTagParent checks the parent exists and is not below the tag itself
+func validateTagParent(name string, parent string) error {
+	for depth := 0; parent != ""; depth++ {
+		if parent == name || depth == maxTagDepth {
+			return errors.New("the parent would make the tag hierarchy circular")
+		}
+		tag, err := getTag(parent)
+		if err != nil {
+			return fmt.Errorf("parent: %v", err)
+		}
+		parent = tag.Parent
+	}
+	return nil
+}
+
+// CreateTag adds a tag to the taxonomy
+func (r *Question) CreateTag(tag *models.Tag) (*models.Tag, error) {
+	if err := tag.Normalize(); err != nil {
+		return nil, err
+	}
+	if err := tagNameTaken(append([]string{tag.ID}, tag.Aliases...), ""); err != nil {
+		return nil, err
+	}
+	if err := validateTagParent(tag.ID, tag.Parent); err != nil {
+		return nil, err
+	}
+	tag.CreatedAt = time.Now()
+	tag.UpdatedAt = time.Now()
+	if _, err := db.TagsCollection.InsertOne(context.TODO(), tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// UpdateTag changes the description, aliases or parent of a tag
+func (r *Question) UpdateTag(name string, patch *models.TagPatch) (*models.Tag, error) {
+	tag, err := getTag(models.NormalizeTag(name))
+	if err != nil {
+		return nil, err
+	}
+	if patch.Description != nil {
+		tag.Description = *patch.Description
+	}
+	if patch.Aliases != nil {
+		tag.Aliases = *patch.Aliases
+	}
+	if patch.Parent != nil {
+		tag.Parent = *patch.Parent
+	}
+	if err := tag.Normalize(); err != nil {
+		return nil, err
+	}
+	if err := tagNameTaken(tag.Aliases, tag.ID); err != nil {
+		return nil, err
+	}
+	if err := validateTagParent(tag.ID, tag.Parent); err != nil {
+		return nil, err
+	}
+	tag.UpdatedAt = time.Now()
+	if _, err := db.TagsCollection.ReplaceOne(context.TODO(), bson.M{"_id": tag.ID}, tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// retagQuestions replaces the old tags with the new one on every question,
+// archived ones included, and returns how many questions changed
+func (r *Question) retagQuestions(from []string, to string, author string, message string) (int, error) {
+	var questions []models.Question
+	cursor, err := db.QuestionsCollection.Find(context.TODO(), bson.M{"tags": bson.M{"$in": from}}, options.Find().SetProjection(bson.M{"tags": 1}))
+	if err != nil {
+		return 0, err
+	}
+	if err = cursor.All(context.TODO(), &questions); err != nil {
+		return 0, err
+	}
+	replaced := map[string]bool{}
+	for _, tag := range from {
+		replaced[tag] = true
+	}
+	updated := 0
+	for _, question := range questions {
+		tags := make([]string, 0, len(question.Tags))
+		for _, tag := range question.Tags {
+			if replaced[tag] {
+				tag = to
+			}
+			tags = append(tags, tag)
+		}
+		if _, err := r.updateQuestionContent(question.ID, bson.M{"tags": models.NormalizeTags(tags)}, author, message); err != nil {
+			return updated, fmt.Errorf("question %s: %v", question.ID, err)
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+// RenameTag gives a tag a new canonical name on every question. The old name
+// stays as an alias, so links and searches keep working. Unmanaged tags are
+// added to the taxonomy under the new name.
+func (r *Question) RenameTag(rename models.TagRename, author string) (*models.Tag, int, error) {
+	from, to := models.NormalizeTag(rename.From), models.NormalizeTag(rename.To)
+	if from == "" || to == "" {
+		return nil, 0, errors.New("from and to are required")
+	}
+	if from == to {
+		return nil, 0, errors.New("the tag already has this name")
+	}
+	// An alias belongs to another tag, renaming it would leave it on both
+	if err := tagNameTaken([]string{from}, from); err != nil {
+		return nil, 0, err
+	}
+	if err := tagNameTaken([]string{to}, from); err != nil {
+		return nil, 0, err
+	}
+	tag, err := getTag(from)
+	if err != nil {
+		tag = &models.Tag{CreatedAt: time.Now()}
+	}
+	tag.ID = to
+	tag.Aliases = append(tag.Aliases, from)
+	if err := tag.Normalize(); err != nil {
+		return nil, 0, err
+	}
+	tag.UpdatedAt = time.Now()
+	if _, err := db.TagsCollection.InsertOne(context.TODO(), tag); err != nil {
+		return nil, 0, err
+	}
+	if _, err := db.TagsCollection.UpdateMany(context.TODO(), bson.M{"parent": from}, bson.M{"$set": bson.M{"parent": to}}); err != nil {
+		return nil, 0, err
+	}
+	if _, err := db.TagsCollection.DeleteOne(context.TODO(), bson.M{"_id": from}); err != nil {
+		return nil, 0, err
+	}
+	updated, err := r.retagQuestions([]string{from}, to, author, fmt.Sprintf("tag %s renamed to %s", from, to))
+	return tag, updated, err
+}
+
+// MergeTags folds the source tags into the target on every question. The
+// sources become aliases of the target and their children move under it.
+func (r *Question) MergeTags(merge models.TagMerge, author string) (*models.Tag, int, error) {
+	target, err := getTag(models.NormalizeTag(merge.Target))
+	if err != nil {
+		return nil, 0, err
+	}
+	sources := []string{}
+	for _, source := range models.NormalizeTags(merge.Sources) {
+		if source != target.ID {
+			sources = append(sources, source)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, 0, errors.New("no tags to merge into the target")
+	}
+	for _, source := range sources {
+		target.Aliases = append(target.Aliases, source)
+		tag, err := getTag(source)
+		if err != nil {
+			continue // unmanaged tag, only used on questions
+		}
+		target.Aliases = append(target.Aliases, tag.Aliases...)
+		if target.Parent == tag.ID {
+			target.Parent = tag.Parent
+		}
+		if _, err := db.TagsCollection.UpdateMany(context.TODO(), bson.M{"parent": tag.ID}, bson.M{"$set": bson.M{"parent": target.ID}}); err != nil {
+			return nil, 0, err
+		}
+		if _, err := db.TagsCollection.DeleteOne(context.TODO(), bson.M{"_id": tag.ID}); err != nil {
+			return nil, 0, err
+		}
+	}
+	// The target's parent may have been one of the merged tags further up
+	if err := validateTagParent(target.ID, target.Parent); err != nil {
+		target.Parent = ""
+	}
+	if err := target.Normalize(); err != nil {
+		return nil, 0, err
+	}
+	target.UpdatedAt = time.Now()
+	if _, err := db.TagsCollection.ReplaceOne(context.TODO(), bson.M{"_id": target.ID}, target); err != nil {
+		return nil, 0, err
+	}
+	updated, err := r.retagQuestions(sources, target.ID, author, fmt.Sprintf("tags %v merged into %s", sources, target.ID))
+	return target, updated, err
+}
+
+// tagQuestionCounts counts the questions per tag, only published ones unless
+// unpublished are included
+func tagQuestionCounts(includeUnpublished bool) (map[string]int64, error) {
+	match := publishedFilter()
+	if includeUnpublished {
+		match = bson.M{"lifecycle": bson.M{"$ne": models.LifecycleArchived}}
+	}
+	cursor, err := db.QuestionsCollection.Aggregate(context.TODO(), []bson.M{
+		{"$match": match},
+		{"$unwind": "$tags"},
+		{"$group": bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var results []struct {
+		Tag   string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err = cursor.All(context.TODO(), &results); err != nil {
+		return nil, err
+	}
+	counts := map[string]int64{}
+	for _, result := range results {
+		counts[result.Tag] = result.Count
+	}
+	return counts, nil
+}
+
+// GetTagTree returns the taxonomy as a tree with question counts per tag.
+// Admins also see tags used on questions but missing from the taxonomy.
+func (r *Question) GetTagTree(includeUnpublished bool) ([]*models.Tag, error) {
+	var tags []*models.Tag
+	cursor, err := db.TagsCollection.Find(context.TODO(), bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(context.TODO(), &tags); err != nil {
+		return nil, err
+	}
+	counts, err := tagQuestionCounts(includeUnpublished)
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string]*models.Tag{}
+	for _, tag := range tags {
+		tag.QuestionCount = counts[tag.ID]
+		byName[tag.ID] = tag
+	}
+	if includeUnpublished {
+		for name, count := range counts {
+			if byName[name] == nil {
+				tag := &models.Tag{ID: name, QuestionCount: count, Unmanaged: true}
+				tags = append(tags, tag)
+				byName[name] = tag
+			}
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].ID < tags[j].ID })
+	roots := []*models.Tag{}
+	for _, tag := range tags {
+		if parent := byName[tag.Parent]; parent != nil && parent != tag {
+			parent.Children = append(parent.Children, tag)
+			continue
+		}
+		roots = append(roots, tag)
+	}
+	return roots, nil
+}
+
+// tagWithDescendants resolves an alias and returns the tag with every tag below it
+func tagWithDescendants(name string) ([]string, error) {
+	resolved, err := canonicalTags([]string{name})
+	if err != nil || len(resolved) == 0 {
+		return resolved, err
+	}
+	names := resolved
+	level := resolved
+	for depth := 0; len(level) > 0 && depth < maxTagDepth; depth++ {
+		var children []models.Tag
+		cursor, err := db.TagsCollection.Find(context.TODO(), bson.M{"parent": bson.M{"$in": level}}, options.Find().SetProjection(bson.M{"_id": 1}))
+		if err != nil {
+			return nil, err
+		}
+		if err = cursor.All(context.TODO(), &children); err != nil {
+			return nil, err
+		}
+		level = nil
+		for _, child := range children {
+			level = append(level, child.ID)
+		}
+		names = append(names, level...)
+	}
+	return names, nil
+}
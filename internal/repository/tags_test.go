@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"code-compiler/internal/models"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestResolveAliases(t *testing.T) {
+	aliases := aliasMap([]models.Tag{
+		{ID: "javascript", Aliases: []string{"js", "ecmascript"}},
+		{ID: "dynamic-programming", Aliases: []string{"dp"}},
+	})
+	tests := []struct {
+		tags []string
+		want []string
+	}{
+		{nil, []string{}},
+		{[]string{"js"}, []string{"javascript"}},
+		{[]string{"js", "javascript", "ecmascript"}, []string{"javascript"}},
+		{[]string{"dp", "graphs"}, []string{"dynamic-programming", "graphs"}},
+	}
+	for _, test := range tests {
+		if got := resolveAliases(test.tags, aliases); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("resolveAliases(%v) = %v, want %v", test.tags, got, test.want)
+		}
+	}
+}
+
+func TestAllTagsFilter(t *testing.T) {
+	aliases := map[string]string{"js": "javascript", "ecmascript": "javascript", "dp": "dynamic-programming"}
+	tests := []struct {
+		tags []string
+		want bson.A
+	}{
+		{[]string{}, bson.A{}},
+		{[]string{"graphs"}, bson.A{bson.M{"tags": bson.M{"$in": []string{"graphs"}}}}},
+		{[]string{"javascript", "dynamic-programming"}, bson.A{
+			bson.M{"tags": bson.M{"$in": []string{"javascript", "ecmascript", "js"}}},
+			bson.M{"tags": bson.M{"$in": []string{"dynamic-programming", "dp"}}},
+		}},
+	}
+	for _, test := range tests {
+		if got := allTagsFilter(test.tags, aliases); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("allTagsFilter(%v) = %v, want %v", test.tags, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"bytes"
+	"code-compiler/internal/models"
+	"errors"
+	"fmt"
+	"os/exec"
+)
+
+// pythonSyntaxCheck compiles the file named by its argument without running
+// it, printing where the first syntax error is
+const pythonSyntaxCheck = `import sys
+try:
+    compile(open(sys.argv[1]).read(), sys.argv[1], "exec")
+except SyntaxError as error:
+    sys.exit("%s:%s: %s" % (error.filename, error.lineno, error.msg))`
+
+// syntaxCheckCommand parses code in the languages compileCode leaves
+// uncompiled, without running it
+func syntaxCheckCommand(codePath, language string) *exec.Cmd {
+	switch language {
+	case "py":
+		return exec.Command("python3", "-c", pythonSyntaxCheck, codePath)
+	case "js":
+		return exec.Command("node", "--check", codePath)
+	}
+	return nil
+}
+
+// compileTemplate compiles the stub of the template between its precode and
+// postcode. The compiler errors are returned as a message, empty when it compiled.
+func compileTemplate(language string, template models.CodeTemplate) (string, error) {
+	codeFilePath := fileWriter(template.Template, language, template)
+	if codeFilePath == "" {
+		return "", errors.New("file creation failed")
+	}
+	compiledFilePath, err := compileCode(codeFilePath, language)
+	if err != nil {
+		return err.Error(), nil
+	}
+	defer removeCompiled(compiledFilePath, language)
+	if cmd := syntaxCheckCommand(compiledFilePath, language); cmd != nil {
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Sprintf("compilation failed: %v", stderr.String()), nil
+		}
+	}
+	return "", nil
+}
+
+// CheckTemplates compiles the template of every language of the question and
+// runs the reference solutions on the sample test cases. The saved solution
+// is run in its own language unless another one is sent for it.
+func (r *Question) CheckTemplates(request *models.TemplateCheckRequest) ([]models.TemplateCheck, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	question, err := r.GetQuestionById(request.QuestionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve question: %v", err)
+	}
+	templates := map[string]models.CodeTemplate{}
+	for language, template := range question.CodeTemplates {
+		templates[language] = template
+	}
+	for language, template := range request.CodeTemplates {
+		templates[language] = template
+	}
+	for language := range request.Solutions {
+		if _, ok := templates[language]; !ok {
+			return nil, fmt.Errorf("no code template to run the %s solution in", language)
+		}
+	}
+	solutions := templateSolutions(question, request)
+	checks := []models.TemplateCheck{}
+	for _, language := range models.SupportedLanguages {
+		template, ok := templates[language]
+		if !ok {
+			if _, ok := solutions[language]; ok {
+				// Only the saved solution can lack a template, sent ones were checked above
+				checks = append(checks, models.TemplateCheck{Language: language, Solution: &models.DryRun{
+					Language: language,
+					Err:      fmt.Sprintf("solution not run: the question has no %s code template", language),
+				}})
+			}
+			continue
+		}
+		check := models.TemplateCheck{Language: language}
+		if check.CompileError, err = compileTemplate(language, template); err != nil {
+			return nil, err
+		}
+		check.Compiled = check.CompileError == ""
+		if solution, ok := solutions[language]; ok {
+			if check.Solution, err = dryRun(solution, language, template, question.SampleTestCases); err != nil {
+				return nil, err
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// templateSolutions keys the reference solutions to run by language, the
+// saved one in its own language, go when it has none, and the sent ones
+func templateSolutions(question *models.Question, request *models.TemplateCheckRequest) map[string]string {
+	solutions := map[string]string{}
+	if question.Solution != "" {
+		language := question.SolutionLanguage
+		if language == "" {
+			language = "go"
+		}
+		solutions[language] = question.Solution
+	}
+	for language, solution := range request.Solutions {
+		solutions[language] = solution
+	}
+	return solutions
+}
+
+// PreviewTemplates returns the code templates as users see them in their
+// editor, without the harness code around them
+func (r *Question) PreviewTemplates(questionId string) (map[string]models.CodeTemplate, error) {
+	question, err := r.GetQuestionById(questionId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve question: %v", err)
+	}
+	return question.ForView(models.ViewPublic).CodeTemplates, nil
+}
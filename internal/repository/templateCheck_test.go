@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"code-compiler/internal/models"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSyntaxCheckCommand(t *testing.T) {
+	tests := []struct {
+		language string
+		want     []string // nil when compileCode already checks the language
+	}{
+		{"py", []string{"python3", "-c", pythonSyntaxCheck, "code.py"}},
+		{"js", []string{"node", "--check", "code.js"}},
+		{"go", nil},
+		{"c", nil},
+		{"java", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.language, func(t *testing.T) {
+			cmd := syntaxCheckCommand("code."+test.language, test.language)
+			if test.want == nil {
+				if cmd != nil {
+					t.Errorf("got %v, want no command", cmd.Args)
+				}
+				return
+			}
+			if cmd == nil || !reflect.DeepEqual(cmd.Args, test.want) {
+				t.Errorf("got %v, want %v", cmd, test.want)
+			}
+		})
+	}
+}
+
+func TestSyntaxCheckRuns(t *testing.T) {
+	tests := []struct {
+		language string
+		code     string
+		wantErr  bool
+	}{
+		{"py", "def f():\n    return 1\n", false},
+		{"py", "def f(:\n", true},
+		{"py", "import sys\nsys.exit(3)\n", false}, // parsed, never run
+		{"js", "var f = function() { return 1; };\n", false},
+		{"js", "var f = function( {\n", true},
+	}
+	for _, test := range tests {
+		t.Run(test.language, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "code."+test.language)
+			if err := os.WriteFile(path, []byte(test.code), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cmd := syntaxCheckCommand(path, test.language)
+			if _, err := exec.LookPath(cmd.Args[0]); err != nil {
+				t.Skipf("%s is not installed", cmd.Args[0])
+			}
+			if err := cmd.Run(); (err != nil) != test.wantErr {
+				t.Errorf("checking %q: %v, want error %v", test.code, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestTemplateSolutions(t *testing.T) {
+	tests := []struct {
+		name     string
+		question models.Question
+		sent     map[string]string
+		want     map[string]string
+	}{
+		{"none", models.Question{}, nil, map[string]string{}},
+		{"saved without language", models.Question{Solution: "func f() {}"}, nil, map[string]string{"go": "func f() {}"}},
+		{"saved in python", models.Question{Solution: "pass", SolutionLanguage: "py"}, nil, map[string]string{"py": "pass"}},
+		{
+			"sent beside the saved one",
+			models.Question{Solution: "pass", SolutionLanguage: "py"},
+			map[string]string{"js": "var f;"},
+			map[string]string{"py": "pass", "js": "var f;"},
+		},
+		{
+			"sent replaces the saved one",
+			models.Question{Solution: "pass", SolutionLanguage: "py"},
+			map[string]string{"py": "print(1)"},
+			map[string]string{"py": "print(1)"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := templateSolutions(&test.question, &models.TemplateCheckRequest{Solutions: test.sent})
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("solutions = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
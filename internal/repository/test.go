@@ -13,8 +13,11 @@ type Test struct {
 	MongoCollection *mongo.Collection
 }
 
+// ValidateQuestions returns the ids an assessment cannot use, those of missing,
+// archived or not yet published questions, which the judge would not run
 func (r *Test) ValidateQuestions(questionIds []string) ([]string, error) {
-	filter := bson.M{"_id": bson.M{"$in": questionIds}}
+	filter := publishedFilter()
+	filter["_id"] = bson.M{"$in": questionIds}
 	cursor, err := db.QuestionsCollection.Find(context.TODO(), filter)
 	if err != nil {
 		return nil, err
@@ -37,12 +40,15 @@ func (r *Test) ValidateQuestions(questionIds []string) ([]string, error) {
 	return missingAptiIds, nil
 }
 
+// GetTestQuestions returns the published questions among questionIds, the
+// route is open so drafts, scheduled and archived questions stay hidden
 func (r *Test) GetTestQuestions(questionIds []string) ([]models.Question, error) {
-	filter := bson.M{"_id": bson.M{"$in": questionIds}}
+	filter := publishedFilter()
+	filter["_id"] = bson.M{"$in": questionIds}
 	projection := bson.D{
-		{"_id", 1},
-		{"slug", 1},
-		{"title", 1}, 
+		{Key: "_id", Value: 1},
+		{Key: "slug", Value: 1},
+		{Key: "title", Value: 1},
 	}
 	cursor, err := db.QuestionsCollection.Find(
 		context.TODO(), 
@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	commontypes "code-compiler/internal/commonTypes"
+	"code-compiler/internal/models"
+	"code-compiler/internal/storage"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// previewLimit caps how much of a streamed test's data is kept for display
+const previewLimit = 4096
+
+// inlineTestDataLimit returns the size above which test data is moved to the blob store
+func inlineTestDataLimit() int {
+	limit, err := strconv.Atoi(os.Getenv("INLINE_TEST_DATA_LIMIT"))
+	if err != nil || limit <= 0 {
+		return 64 * 1024
+	}
+	return limit
+}
+
+// moveLargeTestData stores inputs and outputs above the inline limit as blobs
+func moveLargeTestData(ioPairs []models.InputOutput) error {
+	limit := inlineTestDataLimit()
+	for i := range ioPairs {
+		if len(ioPairs[i].Input) > limit {
+			hash, err := putBlob(strings.NewReader(ioPairs[i].Input))
+			if err != nil {
+				return err
+			}
+			ioPairs[i].Input, ioPairs[i].InputBlob = "", hash
+		}
+		if len(ioPairs[i].Output) > limit {
+			hash, err := putBlob(strings.NewReader(ioPairs[i].Output))
+			if err != nil {
+				return err
+			}
+			ioPairs[i].Output, ioPairs[i].OutputBlob = "", hash
+		}
+	}
+	return nil
+}
+
+// validateTestDataBlobs checks that every referenced blob is stored
+func validateTestDataBlobs(ioPairs []models.InputOutput) error {
+	for i, pair := range ioPairs {
+		for _, hash := range []string{pair.InputBlob, pair.OutputBlob} {
+			if hash == "" {
+				continue
+			}
+			if storage.Blobs == nil || !storage.Blobs.Exists(hash) {
+				return fmt.Errorf("test %d references missing blob %s", i+1, hash)
+			}
+		}
+	}
+	return nil
+}
+
+func putBlob(content io.Reader) (string, error) {
+	if storage.Blobs == nil {
+		return "", errors.New("blob store is not configured")
+	}
+	hash, _, err := storage.Blobs.Put(content)
+	return hash, err
+}
+
+func openBlob(hash string) (io.ReadCloser, error) {
+	if storage.Blobs == nil {
+		return nil, errors.New("blob store is not configured")
+	}
+	return storage.Blobs.Open(hash)
+}
+
+// openTestData returns the inline data, or a reader over the blob when one is referenced
+func openTestData(inline string, hash string) (io.ReadCloser, error) {
+	if hash == "" {
+		return io.NopCloser(strings.NewReader(inline)), nil
+	}
+	return openBlob(hash)
+}
+
+// previewTestData returns the start of the data for failure reports
+func previewTestData(inline string, hash string) string {
+	if hash == "" {
+		return inline
+	}
+	reader, err := openBlob(hash)
+	if err != nil {
+		return ""
+	}
+	defer reader.Close()
+	preview := &cappedBuffer{limit: previewLimit}
+	io.Copy(preview, reader)
+	return preview.String()
+}
+
+// cappedBuffer keeps the first limit bytes written to it and discards the rest
+type cappedBuffer struct {
+	buffer    bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buffer.Len(); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.buffer.Write(p)
+}
+
+func (b *cappedBuffer) String() string {
+	if b.truncated {
+		return b.buffer.String() + "..."
+	}
+	return b.buffer.String()
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\t' || c == '\r' || c == '\v' || c == '\f'
+}
+
+// sameOutput compares two streams the way trimmed string comparison does:
+// leading and trailing whitespace is ignored, everything else must match.
+func sameOutput(actual io.Reader, expected io.Reader) (bool, error) {
+	a, e := bufio.NewReader(actual), bufio.NewReader(expected)
+	if err := skipSpace(a); err != nil {
+		return false, err
+	}
+	if err := skipSpace(e); err != nil {
+		return false, err
+	}
+	for {
+		ac, aErr := a.ReadByte()
+		ec, eErr := e.ReadByte()
+		if aErr != nil && aErr != io.EOF {
+			return false, aErr
+		}
+		if eErr != nil && eErr != io.EOF {
+			return false, eErr
+		}
+		if aErr == io.EOF && eErr == io.EOF {
+			return true, nil
+		}
+		if aErr == nil && eErr == nil && ac == ec {
+			continue
+		}
+		// From the first difference on, both sides may only hold trailing whitespace
+		if (aErr == nil && !isSpace(ac)) || (eErr == nil && !isSpace(ec)) {
+			return false, nil
+		}
+		aRest, err := onlySpace(a)
+		if err != nil || !aRest {
+			return false, err
+		}
+		return onlySpace(e)
+	}
+}
+
+func skipSpace(r *bufio.Reader) error {
+	for {
+		c, err := r.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !isSpace(c) {
+			return r.UnreadByte()
+		}
+	}
+}
+
+func onlySpace(r *bufio.Reader) (bool, error) {
+	for {
+		c, err := r.ReadByte()
+		if err == io.EOF {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if !isSpace(c) {
+			return false, nil
+		}
+	}
+}
+
+// runStreamedTestCase runs a test whose data lives in the blob store. The input
+// is streamed to the program's stdin and its output straight into the
+// comparator, only a short preview of each is kept in memory.
+func runStreamedTestCase(compiledFilePath string, testCase models.InputOutput, testCaseNumber int, language string) (*commontypes.TestResult, error) {
+	cmd, err := getCommandForLanguage(compiledFilePath, language)
+	if err != nil {
+		return nil, err
+	}
+	input, err := openTestData(testCase.Input, testCase.InputBlob)
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+	expected, err := openTestData(testCase.Output, testCase.OutputBlob)
+	if err != nil {
+		return nil, err
+	}
+	defer expected.Close()
+
+	outputReader, outputWriter := io.Pipe()
+	cmd.Stdin = input
+	cmd.Stdout = outputWriter
+	cmd.Stderr = outputWriter
+	preview := &cappedBuffer{limit: previewLimit}
+	compared := make(chan bool, 1)
+	go func() {
+		same, err := sameOutput(io.TeeReader(outputReader, preview), expected)
+		// Keep draining so the program never blocks on a full pipe
+		io.Copy(preview, outputReader)
+		compared <- same && err == nil
+	}()
+
+	if err := cmd.Start(); err != nil {
+		outputWriter.Close()
+		return nil, err
+	}
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- cmd.Wait()
+	}()
+
+	select {
+	case err = <-errChan:
+	case <-time.After(2 * time.Second):
+		cmd.Process.Kill()
+		<-errChan
+		outputWriter.Close()
+		<-compared
+		return nil, fmt.Errorf("test case %d %w", testCaseNumber, errTimedOut)
+	}
+	outputWriter.Close()
+	passed := <-compared
+	if err != nil {
+		return nil, fmt.Errorf("%s", preview.String())
+	}
+	result := &commontypes.TestResult{
+		TestCaseNumber: testCaseNumber,
+		Input:          previewTestData(testCase.Input, testCase.InputBlob),
+		ExpectedOutput: previewTestData(testCase.Output, testCase.OutputBlob),
+		ActualOutput:   strings.TrimSpace(preview.String()),
+		Passed:         passed,
+	}
+	recordUsage(result, cmd.ProcessState)
+	return result, nil
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"archive/zip"
+	"bufio"
+	"code-compiler/internal/models"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// newlineReader turns CRLF and lone CR line endings into LF and drops a leading BOM
+type newlineReader struct {
+	reader  *bufio.Reader
+	started bool
+}
+
+func newNewlineReader(r io.Reader) *newlineReader {
+	return &newlineReader{reader: bufio.NewReader(r)}
+}
+
+func (n *newlineReader) Read(p []byte) (int, error) {
+	if !n.started {
+		n.started = true
+		if bom, err := n.reader.Peek(3); err == nil && string(bom) == "\xef\xbb\xbf" {
+			n.reader.Discard(3)
+		}
+	}
+	count := 0
+	for count < len(p) {
+		c, err := n.reader.ReadByte()
+		if err != nil {
+			if count > 0 && err == io.EOF {
+				return count, nil
+			}
+			return count, err
+		}
+		if c == '\r' {
+			if next, err := n.reader.Peek(1); err == nil && next[0] == '\n' {
+				n.reader.Discard(1)
+			}
+			c = '\n'
+		}
+		p[count] = c
+		count++
+		if n.reader.Buffered() == 0 {
+			break
+		}
+	}
+	return count, nil
+}
+
+// naturalLess orders test file names so that 2.in comes before 10.in
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		aDigits, bDigits := leadingDigits(a), leadingDigits(b)
+		if aDigits != "" && bDigits != "" {
+			aNumber, _ := strconv.ParseUint(aDigits, 10, 64)
+			bNumber, _ := strconv.ParseUint(bDigits, 10, 64)
+			if aNumber != bNumber {
+				return aNumber < bNumber
+			}
+			a, b = a[len(aDigits):], b[len(bDigits):]
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+func leadingDigits(s string) string {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	return s[:end]
+}
+
+// testDataPair is an input file and its answer from an uploaded archive
+type testDataPair struct {
+	Stem   string
+	Input  *zip.File
+	Answer *zip.File
+}
+
+// UploadTestCases creates test case batches from a zip archive of NN.in files
+// paired with NN.ans (or NN.out) files. Every directory of the archive becomes
+// one batch, named after the directory unless a group is given. Line endings
+// are normalized to LF and every file gets an entry in the report.
+func (r *Question) UploadTestCases(archive *zip.Reader, questionId string, group string, visibility string, createdBy string) ([]models.TestCase, []models.TestDataFileReport, error) {
+	if _, err := r.GetQuestionById(questionId); err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve question: %v", err)
+	}
+	if err := models.ValidateVisibility(visibility); err != nil {
+		return nil, nil, err
+	}
+	if err := models.ValidateGroupName(group); err != nil {
+		return nil, nil, err
+	}
+
+	var reports []models.TestDataFileReport
+	pairs := map[string]*testDataPair{}
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		base := path.Base(file.Name)
+		if strings.HasPrefix(file.Name, "__MACOSX/") || strings.HasPrefix(base, ".") {
+			reports = append(reports, models.TestDataFileReport{File: file.Name, Status: "skipped", Message: "hidden or system file"})
+			continue
+		}
+		extension := path.Ext(file.Name)
+		stem := strings.TrimSuffix(file.Name, extension)
+		if pairs[stem] == nil {
+			pairs[stem] = &testDataPair{Stem: stem}
+		}
+		switch extension {
+		case ".in":
+			pairs[stem].Input = file
+		case ".ans", ".out":
+			if pairs[stem].Answer != nil {
+				reports = append(reports, models.TestDataFileReport{File: file.Name, Status: "rejected", Message: "duplicate answer file for " + stem})
+				continue
+			}
+			pairs[stem].Answer = file
+		default:
+			reports = append(reports, models.TestDataFileReport{File: file.Name, Status: "skipped", Message: "not a .in, .ans or .out file"})
+		}
+	}
+
+	batches := map[string][]*testDataPair{}
+	var directories []string
+	for _, pair := range pairs {
+		switch {
+		case pair.Input == nil && pair.Answer == nil:
+			continue
+		case pair.Input == nil:
+			reports = append(reports, models.TestDataFileReport{File: pair.Answer.Name, Status: "rejected", Message: "no matching .in file"})
+			continue
+		case pair.Answer == nil:
+			reports = append(reports, models.TestDataFileReport{File: pair.Input.Name, Status: "rejected", Message: "no matching .ans or .out file"})
+			continue
+		}
+		directory := path.Dir(pair.Stem)
+		if batches[directory] == nil {
+			directories = append(directories, directory)
+		}
+		batches[directory] = append(batches[directory], pair)
+	}
+	sort.Strings(directories)
+
+	var testCases []models.TestCase
+	for _, directory := range directories {
+		batch := batches[directory]
+		sort.Slice(batch, func(i, j int) bool { return naturalLess(batch[i].Stem, batch[j].Stem) })
+		testCase := models.TestCase{
+			QuestionID: questionId,
+			Group:      group,
+			Visibility: visibility,
+			CreatedBy:  createdBy,
+		}
+		if testCase.Group == "" && directory != "." {
+			testCase.Group = path.Base(directory)
+		}
+		var batchFiles []string
+		for _, pair := range batch {
+			ioPair, err := readUploadedPair(pair)
+			if err != nil {
+				reports = append(reports,
+					models.TestDataFileReport{File: pair.Input.Name, Status: "rejected", Message: err.Error()},
+					models.TestDataFileReport{File: pair.Answer.Name, Status: "rejected", Message: err.Error()})
+				continue
+			}
+			testCase.IOPairs = append(testCase.IOPairs, ioPair)
+			batchFiles = append(batchFiles, pair.Input.Name, pair.Answer.Name)
+		}
+		if len(testCase.IOPairs) == 0 {
+			continue
+		}
+		createdTestCase, err := r.CreateTestCase(&testCase)
+		for _, file := range batchFiles {
+			report := models.TestDataFileReport{File: file, Status: "created"}
+			if err != nil {
+				report.Status, report.Message = "rejected", err.Error()
+			} else {
+				report.TestCaseID = createdTestCase.ID
+			}
+			reports = append(reports, report)
+		}
+		if err == nil {
+			testCases = append(testCases, *createdTestCase)
+		}
+	}
+	sort.SliceStable(reports, func(i, j int) bool { return naturalLess(reports[i].File, reports[j].File) })
+	return testCases, reports, nil
+}
+
+func readUploadedPair(pair *testDataPair) (models.InputOutput, error) {
+	var ioPair models.InputOutput
+	var err error
+	if ioPair.Input, ioPair.InputBlob, err = readUploadedFile(pair.Input); err != nil {
+		return ioPair, err
+	}
+	if ioPair.Output, ioPair.OutputBlob, err = readUploadedFile(pair.Answer); err != nil {
+		return ioPair, err
+	}
+	// Answer files end with a newline, the judge compares trimmed output
+	ioPair.Output = strings.TrimSpace(ioPair.Output)
+	return ioPair, nil
+}
+
+func readUploadedFile(file *zip.File) (string, string, error) {
+	content, err := file.Open()
+	if err != nil {
+		return "", "", err
+	}
+	defer content.Close()
+	return loadTestData(newNewlineReader(content), int64(file.UncompressedSize64))
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"archive/zip"
+	"bytes"
+	"code-compiler/internal/models"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// sumProgram writes a Python program printing the sum of the numbers it reads
+func sumProgram(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "sum.py")
+	if err := os.WriteFile(path, []byte("import sys\nprint(sum(map(int, sys.stdin.read().split())))\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func zipArchive(t *testing.T, files map[string]string) *zip.Reader {
+	t.Helper()
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	for name, content := range files {
+		file, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := file.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reader
+}
+
+func TestUploadedAnswerWithTrailingNewlineIsAccepted(t *testing.T) {
+	program := sumProgram(t)
+	tests := []struct {
+		name   string
+		input  string
+		answer string
+	}{
+		{"LF", "1 2\n", "3\n"},
+		{"CRLF", "1 2\r\n", "3\r\n"},
+		{"blank lines", "1 2\n", "\n3\n\n"},
+		{"no newline", "1 2", "3"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			archive := zipArchive(t, map[string]string{"1.in": test.input, "1.ans": test.answer})
+			pair := &testDataPair{Stem: "1"}
+			for _, file := range archive.File {
+				if strings.HasSuffix(file.Name, ".in") {
+					pair.Input = file
+				} else {
+					pair.Answer = file
+				}
+			}
+			ioPair, err := readUploadedPair(pair)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ioPair.Output != "3" {
+				t.Errorf("stored answer = %q, want %q", ioPair.Output, "3")
+			}
+			result, err := runTestCase(program, ioPair, 1, "py")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !result.Passed {
+				t.Errorf("correct output %q judged wrong against %q", result.ActualOutput, result.ExpectedOutput)
+			}
+		})
+	}
+}
+
+func TestRunTestCaseTrimsStoredAnswers(t *testing.T) {
+	// Answers stored before uploads were trimmed still end with a newline
+	result, err := runTestCase(sumProgram(t), models.InputOutput{Input: "4 5\n", Output: "9\n"}, 1, "py")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Passed {
+		t.Errorf("correct output %q judged wrong against %q", result.ActualOutput, result.ExpectedOutput)
+	}
+}
+
+func TestNewlineReader(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"a\r\nb\r\n", "a\nb\n"},
+		{"a\rb\r", "a\nb\n"},
+		{"\xef\xbb\xbfa\n", "a\n"},
+		{"a\n\r\n", "a\n\n"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		got, err := io.ReadAll(newNewlineReader(strings.NewReader(test.input)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != test.want {
+			t.Errorf("newlineReader(%q) = %q, want %q", test.input, got, test.want)
+		}
+	}
+}
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"2.in", "10.in", true},
+		{"10.in", "2.in", false},
+		{"a/9.in", "b/1.in", true},
+		{"test2.in", "test10.in", true},
+		{"1.ans", "1.in", true},
+	}
+	for _, test := range tests {
+		if got := naturalLess(test.a, test.b); got != test.want {
+			t.Errorf("naturalLess(%q, %q) = %v, want %v", test.a, test.b, got, test.want)
+		}
+	}
+}
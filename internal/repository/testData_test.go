@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSameOutput(t *testing.T) {
+	tests := []struct {
+		actual, expected string
+		want             bool
+	}{
+		{"3\n", "3", true},
+		{"3", "3\n\n", true},
+		{"  3 4\n", "3 4", true},
+		{"3 4", "3  4", false},
+		{"3", "4", false},
+		{"3\n4", "3", false},
+		{"", "\n", true},
+	}
+	for _, test := range tests {
+		got, err := sameOutput(strings.NewReader(test.actual), strings.NewReader(test.expected))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("sameOutput(%q, %q) = %v, want %v", test.actual, test.expected, got, test.want)
+		}
+	}
+}
@@ -12,7 +12,11 @@ func RegisterCodeRoutes(r *mux.Router, codeRunService *usecases.CodeRunnerServic
 	wrappedRunTest := middlewares.IsValidUser(http.HandlerFunc(codeRunService.RunTest))
 	wrappedSubmitTest := middlewares.IsValidUser(http.HandlerFunc(codeRunService.SubmitTest))
 	wrappedGetSubmissions := middlewares.IsValidUser(http.HandlerFunc(codeRunService.GetUserSubmission))
+	wrappedStartRejudge := middlewares.IsValidAdmin(http.HandlerFunc(codeRunService.StartRejudge))
+	wrappedGetRejudgeJob := middlewares.IsValidAdmin(http.HandlerFunc(codeRunService.GetRejudgeJob))
 	r.Handle("/run-code", wrappedRunTest).Methods(http.MethodPost)
 	r.Handle("/code-submissions", wrappedGetSubmissions).Methods(http.MethodGet)
 	r.Handle("/submit-code", wrappedSubmitTest).Methods(http.MethodPost)
+	r.Handle("/rejudge", wrappedStartRejudge).Methods(http.MethodPost)
+	r.Handle("/rejudge", wrappedGetRejudgeJob).Methods(http.MethodGet)
 }
@@ -13,17 +13,82 @@ func RegisterQuestionRoutes(r *mux.Router, questionService *usecases.QuestionSer
 	wrappedGetQuestionById := middlewares.IsValidAdmin(http.HandlerFunc(questionService.GetQuestionById))
 	wrappedUpdateQuestionById := middlewares.IsValidAdmin(http.HandlerFunc(questionService.UpdateQuestionById))
 	wrappedCreateTestCases := middlewares.IsValidAdmin(http.HandlerFunc(questionService.CreateTestCase))
+	wrappedGetTestCases := middlewares.IsValidAdmin(http.HandlerFunc(questionService.GetTestCases))
 	wrappedUpdateTestCases := middlewares.IsValidAdmin(http.HandlerFunc(questionService.UpdateTestCases))
+	wrappedUploadTestData := middlewares.IsValidAdmin(http.HandlerFunc(questionService.UploadTestData))
+	wrappedGetPendingTestCases := middlewares.IsValidAdmin(http.HandlerFunc(questionService.GetPendingTestCases))
+	wrappedReviewTestCase := middlewares.IsValidAdmin(http.HandlerFunc(questionService.ReviewTestCase))
+	wrappedExportQuestion := middlewares.IsValidAdmin(http.HandlerFunc(questionService.ExportQuestion))
+	wrappedImportQuestion := middlewares.IsValidAdmin(http.HandlerFunc(questionService.ImportQuestion))
+	wrappedUploadTestCases := middlewares.IsValidAdmin(http.HandlerFunc(questionService.UploadTestCases))
+	wrappedGetQuestionRevisions := middlewares.IsValidAdmin(http.HandlerFunc(questionService.GetQuestionRevisions))
+	wrappedDiffQuestionRevisions := middlewares.IsValidAdmin(http.HandlerFunc(questionService.DiffQuestionRevisions))
+	wrappedRollbackQuestion := middlewares.IsValidAdmin(http.HandlerFunc(questionService.RollbackQuestion))
+	wrappedSetLifecycle := middlewares.IsValidAdmin(http.HandlerFunc(questionService.SetLifecycle))
+	wrappedDeleteQuestion := middlewares.IsValidAdmin(http.HandlerFunc(questionService.DeleteQuestion))
+	wrappedRestoreQuestion := middlewares.IsValidAdmin(http.HandlerFunc(questionService.RestoreQuestion))
+	wrappedPurgeQuestion := middlewares.IsValidAdmin(http.HandlerFunc(questionService.PurgeQuestion))
+	wrappedDeleteTestCase := middlewares.IsValidAdmin(http.HandlerFunc(questionService.DeleteTestCase))
+	wrappedRestoreTestCase := middlewares.IsValidAdmin(http.HandlerFunc(questionService.RestoreTestCase))
+	wrappedPurgeTestCase := middlewares.IsValidAdmin(http.HandlerFunc(questionService.PurgeTestCase))
+	wrappedCloneQuestion := middlewares.IsValidAdmin(http.HandlerFunc(questionService.CloneQuestion))
+	wrappedSetTranslation := middlewares.IsValidAdmin(http.HandlerFunc(questionService.SetTranslation))
+	wrappedDeleteTranslation := middlewares.IsValidAdmin(http.HandlerFunc(questionService.DeleteTranslation))
+	wrappedGetLocaleReport := middlewares.IsValidAdmin(http.HandlerFunc(questionService.GetLocaleReport))
+	wrappedCreateTag := middlewares.IsValidAdmin(http.HandlerFunc(questionService.CreateTag))
+	wrappedUpdateTag := middlewares.IsValidAdmin(http.HandlerFunc(questionService.UpdateTag))
+	wrappedRenameTag := middlewares.IsValidAdmin(http.HandlerFunc(questionService.RenameTag))
+	wrappedMergeTags := middlewares.IsValidAdmin(http.HandlerFunc(questionService.MergeTags))
+	wrappedUploadAttachment := middlewares.IsValidAdmin(http.HandlerFunc(questionService.UploadAttachment))
+	wrappedGetAttachments := middlewares.IsValidAdmin(http.HandlerFunc(questionService.GetAttachments))
+	wrappedDeleteAttachment := middlewares.IsValidAdmin(http.HandlerFunc(questionService.DeleteAttachment))
+	wrappedServeAttachment := middlewares.IsValidUserWithoutReturn(http.HandlerFunc(questionService.ServeAttachment))
+	wrappedGenerateTemplates := middlewares.IsValidAdmin(http.HandlerFunc(questionService.GenerateTemplates))
+	wrappedCheckTemplates := middlewares.IsValidAdmin(http.HandlerFunc(questionService.CheckTemplates))
+	wrappedPreviewTemplates := middlewares.IsValidAdmin(http.HandlerFunc(questionService.PreviewTemplates))
+	wrappedGetTags := middlewares.IsValidUserWithoutReturn(http.HandlerFunc(questionService.GetTags))
 	wrappedGetQuestions := middlewares.IsValidUserWithoutReturn(http.HandlerFunc(questionService.GetQuestions))
 	wrappedGetQuestionBySlug := middlewares.IsValidUserWithoutReturn(http.HandlerFunc(questionService.GetQuestionBySlug))
 	r.Handle("/question", wrappedCreateQuestion).Methods(http.MethodPost)
 	r.Handle("/question", wrappedGetQuestionById).Methods(http.MethodGet)
 	r.Handle("/question", wrappedUpdateQuestionById).Methods(http.MethodPut)
+	r.Handle("/question", wrappedDeleteQuestion).Methods(http.MethodDelete)
+	r.Handle("/question/restore", wrappedRestoreQuestion).Methods(http.MethodPost)
+	r.Handle("/question/purge", wrappedPurgeQuestion).Methods(http.MethodDelete)
+	r.Handle("/question/clone", wrappedCloneQuestion).Methods(http.MethodPost)
+	r.Handle("/question/export", wrappedExportQuestion).Methods(http.MethodGet)
+	r.Handle("/question/import", wrappedImportQuestion).Methods(http.MethodPost)
+	r.Handle("/question/revisions", wrappedGetQuestionRevisions).Methods(http.MethodGet)
+	r.Handle("/question/revisions/diff", wrappedDiffQuestionRevisions).Methods(http.MethodGet)
+	r.Handle("/question/revisions/rollback", wrappedRollbackQuestion).Methods(http.MethodPost)
+	r.Handle("/question/lifecycle", wrappedSetLifecycle).Methods(http.MethodPost)
+	r.Handle("/question/translation", wrappedSetTranslation).Methods(http.MethodPut)
+	r.Handle("/question/translation", wrappedDeleteTranslation).Methods(http.MethodDelete)
+	r.Handle("/questions/locales/report", wrappedGetLocaleReport).Methods(http.MethodGet)
+	r.Handle("/question/templates/generate", wrappedGenerateTemplates).Methods(http.MethodPost)
+	r.Handle("/question/templates/check", wrappedCheckTemplates).Methods(http.MethodPost)
+	r.Handle("/question/templates/preview", wrappedPreviewTemplates).Methods(http.MethodGet)
+	r.Handle("/question/attachments", wrappedUploadAttachment).Methods(http.MethodPost)
+	r.Handle("/question/attachments", wrappedGetAttachments).Methods(http.MethodGet)
+	r.Handle("/question/attachment", wrappedDeleteAttachment).Methods(http.MethodDelete)
+	r.Handle("/attachment", wrappedServeAttachment).Methods(http.MethodGet, http.MethodHead)
 	r.Handle("/questions", wrappedGetQuestions).Methods(http.MethodGet)
 	r.Handle("/question/slug", wrappedGetQuestionBySlug).Methods(http.MethodGet)
 	r.HandleFunc("/questions/tag", questionService.GetQuestionsByTag).Methods(http.MethodGet)
+	r.Handle("/tags", wrappedGetTags).Methods(http.MethodGet)
+	r.Handle("/tag", wrappedCreateTag).Methods(http.MethodPost)
+	r.Handle("/tag", wrappedUpdateTag).Methods(http.MethodPut)
+	r.Handle("/tag/rename", wrappedRenameTag).Methods(http.MethodPost)
+	r.Handle("/tag/merge", wrappedMergeTags).Methods(http.MethodPost)
 	r.Handle("/test-cases", wrappedCreateTestCases).Methods(http.MethodPost)
-	r.HandleFunc("/test-cases", questionService.GetTestCases).Methods(http.MethodGet)
+	r.Handle("/test-cases", wrappedGetTestCases).Methods(http.MethodGet)
 	r.Handle("/test-cases", wrappedUpdateTestCases).Methods(http.MethodPut)
+	r.Handle("/test-cases", wrappedDeleteTestCase).Methods(http.MethodDelete)
+	r.Handle("/test-cases/restore", wrappedRestoreTestCase).Methods(http.MethodPost)
+	r.Handle("/test-cases/purge", wrappedPurgeTestCase).Methods(http.MethodDelete)
+	r.Handle("/test-cases/blob", wrappedUploadTestData).Methods(http.MethodPost)
+	r.Handle("/test-cases/upload", wrappedUploadTestCases).Methods(http.MethodPost)
+	r.Handle("/test-cases/review", wrappedGetPendingTestCases).Methods(http.MethodGet)
+	r.Handle("/test-cases/review", wrappedReviewTestCase).Methods(http.MethodPost)
 	// r.HandleFunc("/validate-questions", questionService.GetInvalidQuestions).Methods(http.MethodPost)
 }
@@ -0,0 +1,402 @@
+// Package search keeps an in-memory inverted index of the question catalog
+// for ranked full-text search.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Fields of a document, a match in the title counts the most
+const (
+	fieldTitle = iota
+	fieldTags
+	fieldDescription
+	fieldCount
+)
+
+var fieldWeights = [fieldCount]float64{3, 2, 1}
+
+const (
+	// textGap keeps phrases from matching across two tags or two statements
+	textGap = 8
+	// maxPrefixExpansions bounds how many words a prefix query can stand for
+	maxPrefixExpansions = 50
+	prefixPenalty       = 0.8
+	phraseBoost         = 1.5
+)
+
+// Questions is the index of the question catalog
+var Questions = NewIndex()
+
+// Document is the searchable part of a question
+type Document struct {
+	ID           string
+	Title        string
+	Description  string
+	Tags         []string
+	Difficulty   string
+	Translations []Translation
+}
+
+// Translation is the statement of a document in another language, it is
+// searched like the title and description
+type Translation struct {
+	Title       string
+	Description string
+}
+
+// descriptions returns the description followed by its translations
+func (doc Document) descriptions() []string {
+	descriptions := []string{doc.Description}
+	for _, translation := range doc.Translations {
+		descriptions = append(descriptions, translation.Description)
+	}
+	return descriptions
+}
+
+// Filter narrows the results to difficulties and tags, every tag has to be
+// present under its name or one of its aliases
+type Filter struct {
+	Difficulties []string
+	Tags         []string
+	Aliases      map[string]string // alias -> tag
+}
+
+// Result is a matching document with its relevance and a highlighted snippet
+type Result struct {
+	ID      string
+	Score   float64
+	Snippet string
+}
+
+// positions of a term per field of one document
+type fieldPositions [fieldCount][]int
+
+// Index is an inverted index safe for concurrent use
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[string]Document
+	postings map[string]map[string]*fieldPositions // term -> document -> positions
+	terms    []string                              // sorted terms for prefix lookups
+}
+
+func NewIndex() *Index {
+	return &Index{
+		docs:     map[string]Document{},
+		postings: map[string]map[string]*fieldPositions{},
+	}
+}
+
+// Replace swaps the whole content of the index
+func (idx *Index) Replace(docs []Document) {
+	fresh := NewIndex()
+	for _, doc := range docs {
+		fresh.add(doc)
+	}
+	terms := make([]string, 0, len(fresh.postings))
+	for term := range fresh.postings {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.docs = fresh.docs
+	idx.postings = fresh.postings
+	idx.terms = terms
+}
+
+// Add indexes a document, replacing an earlier version with the same ID
+func (idx *Index) Add(doc Document) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, term := range idx.remove(doc.ID) {
+		idx.deleteTerm(term)
+	}
+	for _, term := range idx.add(doc) {
+		idx.insertTerm(term)
+	}
+}
+
+// Remove drops a document from the index
+func (idx *Index) Remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, term := range idx.remove(id) {
+		idx.deleteTerm(term)
+	}
+}
+
+// Len returns the number of indexed documents
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+func fieldTokens(doc Document) [fieldCount][]token {
+	titles := []string{doc.Title}
+	for _, translation := range doc.Translations {
+		titles = append(titles, translation.Title)
+	}
+	var fields [fieldCount][]token
+	fields[fieldTitle] = tokenizeAll(titles)
+	fields[fieldTags] = tokenizeAll(doc.Tags)
+	fields[fieldDescription] = tokenizeAll(doc.descriptions())
+	return fields
+}
+
+// tokenizeAll tokenizes the texts as one field, with a gap between them
+func tokenizeAll(texts []string) []token {
+	var tokens []token
+	offset := 0
+	for _, text := range texts {
+		textTokens := tokenize(text)
+		for _, t := range textTokens {
+			t.position += offset
+			tokens = append(tokens, t)
+		}
+		offset += len(textTokens) + textGap
+	}
+	return tokens
+}
+
+// add indexes the document and returns the terms new to the index
+func (idx *Index) add(doc Document) []string {
+	var added []string
+	idx.docs[doc.ID] = doc
+	for field, tokens := range fieldTokens(doc) {
+		for _, t := range tokens {
+			docs := idx.postings[t.term]
+			if docs == nil {
+				docs = map[string]*fieldPositions{}
+				idx.postings[t.term] = docs
+				added = append(added, t.term)
+			}
+			positions := docs[doc.ID]
+			if positions == nil {
+				positions = &fieldPositions{}
+				docs[doc.ID] = positions
+			}
+			positions[field] = append(positions[field], t.position)
+		}
+	}
+	return added
+}
+
+// remove drops the document and returns the terms no longer in the index
+func (idx *Index) remove(id string) []string {
+	var removed []string
+	doc, ok := idx.docs[id]
+	if !ok {
+		return nil
+	}
+	for _, tokens := range fieldTokens(doc) {
+		for _, t := range tokens {
+			if docs := idx.postings[t.term]; docs != nil {
+				delete(docs, id)
+				if len(docs) == 0 {
+					delete(idx.postings, t.term)
+					removed = append(removed, t.term)
+				}
+			}
+		}
+	}
+	delete(idx.docs, id)
+	return removed
+}
+
+// insertTerm adds a term to the sorted terms
+func (idx *Index) insertTerm(term string) {
+	i := sort.SearchStrings(idx.terms, term)
+	if i < len(idx.terms) && idx.terms[i] == term {
+		return
+	}
+	idx.terms = append(idx.terms, "")
+	copy(idx.terms[i+1:], idx.terms[i:])
+	idx.terms[i] = term
+}
+
+// deleteTerm drops a term from the sorted terms
+func (idx *Index) deleteTerm(term string) {
+	i := sort.SearchStrings(idx.terms, term)
+	if i < len(idx.terms) && idx.terms[i] == term {
+		idx.terms = append(idx.terms[:i], idx.terms[i+1:]...)
+	}
+}
+
+// expand returns the indexed terms starting with the prefix
+func (idx *Index) expand(prefix string) []string {
+	var expanded []string
+	for i := sort.SearchStrings(idx.terms, prefix); i < len(idx.terms) && strings.HasPrefix(idx.terms[i], prefix); i++ {
+		expanded = append(expanded, idx.terms[i])
+		if len(expanded) == maxPrefixExpansions {
+			break
+		}
+	}
+	return expanded
+}
+
+func (idx *Index) idf(term string) float64 {
+	return math.Log(1 + float64(len(idx.docs))/float64(1+len(idx.postings[term])))
+}
+
+// match scores every document satisfying the clause and collects the terms
+// that matched in each, for highlighting
+func (idx *Index) match(c clause, matched map[string]map[string]bool) map[string]float64 {
+	scores := map[string]float64{}
+	note := func(id string, term string) {
+		if matched[id] == nil {
+			matched[id] = map[string]bool{}
+		}
+		matched[id][term] = true
+	}
+	if len(c.terms) > 1 {
+		for id, positions := range idx.postings[c.terms[0]] {
+			for field := 0; field < fieldCount; field++ {
+				if count := idx.phraseCount(c.terms, id, field, positions[field]); count > 0 {
+					scores[id] += fieldWeights[field] * float64(count) * phraseBoost
+				}
+			}
+			if scores[id] == 0 {
+				delete(scores, id)
+				continue
+			}
+			weight := 0.0
+			for _, term := range c.terms {
+				weight += idx.idf(term)
+				note(id, term)
+			}
+			scores[id] *= weight
+		}
+		return scores
+	}
+	terms := []string{c.terms[0]}
+	penalty := 1.0
+	if c.prefix {
+		terms = idx.expand(c.terms[0])
+		penalty = prefixPenalty
+	}
+	for _, term := range terms {
+		idf := idx.idf(term)
+		if term != c.terms[0] {
+			idf *= penalty
+		}
+		for id, positions := range idx.postings[term] {
+			score := 0.0
+			for field, hits := range positions {
+				if len(hits) > 0 {
+					score += fieldWeights[field] * (1 + math.Log(float64(len(hits))))
+				}
+			}
+			// the best expansion of a prefix decides
+			if score*idf > scores[id] {
+				scores[id] = score * idf
+			}
+			note(id, term)
+		}
+	}
+	return scores
+}
+
+// phraseCount counts where the terms follow each other in a field
+func (idx *Index) phraseCount(terms []string, id string, field int, starts []int) int {
+	count := 0
+	for _, start := range starts {
+		found := true
+		for offset, term := range terms[1:] {
+			positions := idx.postings[term][id]
+			if positions == nil || !containsInt(positions[field], start+offset+1) {
+				found = false
+				break
+			}
+		}
+		if found {
+			count++
+		}
+	}
+	return count
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (doc Document) passes(filter Filter) bool {
+	if len(filter.Difficulties) > 0 && !containsFold(filter.Difficulties, doc.Difficulty) {
+		return false
+	}
+	tags := make([]string, len(doc.Tags))
+	for i, tag := range doc.Tags {
+		if canonical, ok := filter.Aliases[tag]; ok {
+			tag = canonical
+		}
+		tags[i] = tag
+	}
+	for _, tag := range filter.Tags {
+		if !containsFold(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Search returns the documents matching every part of the query, best first.
+// Words match whole words, "quoted words" match as a phrase and word* matches
+// words starting with it.
+func (idx *Index) Search(query string, filter Filter) []Result {
+	clauses := parseQuery(query)
+	if len(clauses) == 0 {
+		return nil
+	}
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	matched := map[string]map[string]bool{}
+	var scores map[string]float64
+	for _, c := range clauses {
+		clauseScores := idx.match(c, matched)
+		if scores == nil {
+			scores = clauseScores
+			continue
+		}
+		for id := range scores {
+			if clauseScore, ok := clauseScores[id]; ok {
+				scores[id] += clauseScore
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+	results := make([]Result, 0, len(scores))
+	for id, score := range scores {
+		doc := idx.docs[id]
+		if !doc.passes(filter) {
+			continue
+		}
+		results = append(results, Result{ID: id, Score: score, Snippet: snippet(doc, matched[id])})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+	return results
+}
@@ -0,0 +1,169 @@
+package search
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func catalog() *Index {
+	idx := NewIndex()
+	idx.Replace([]Document{
+		{ID: "1", Title: "Two Sum", Description: "Find two numbers adding up to the target.", Tags: []string{"array", "hash-table"}, Difficulty: "easy"},
+		{ID: "2", Title: "Three Sum", Description: "Find all triplets that sum to zero.", Tags: []string{"array", "two-pointers"}, Difficulty: "medium"},
+		{ID: "3", Title: "Reverse a Linked List", Description: "Reverse the list in place.", Tags: []string{"linked-list"}, Difficulty: "easy"},
+		{ID: "4", Title: "Résumé Parser", Description: "Parse a résumé into sections.", Tags: []string{"string"}, Difficulty: "hard"},
+	})
+	return idx
+}
+
+func resultIDs(results []Result) []string {
+	ids := []string{}
+	for _, result := range results {
+		ids = append(ids, result.ID)
+	}
+	return ids
+}
+
+func TestSearch(t *testing.T) {
+	idx := catalog()
+	tests := []struct {
+		name   string
+		query  string
+		filter Filter
+		want   []string
+	}{
+		{"empty query", "  ", Filter{}, nil},
+		{"more matching fields rank higher", "sum", Filter{}, []string{"2", "1"}},
+		{"every word must match", "sum zero", Filter{}, []string{"2"}},
+		{"phrase", `"linked list"`, Filter{}, []string{"3"}},
+		{"phrase out of order", `"list linked"`, Filter{}, []string{}},
+		{"phrase does not cross tags", `"table two"`, Filter{}, []string{}},
+		{"prefix", "rev*", Filter{}, []string{"3"}},
+		{"accents are folded", "resume", Filter{}, []string{"4"}},
+		{"difficulty filter", "sum", Filter{Difficulties: []string{"Medium"}}, []string{"2"}},
+		{"tag filter", "find", Filter{Tags: []string{"hash-table"}}, []string{"1"}},
+		{"tag filter resolves aliases", "find", Filter{Tags: []string{"hashing"}, Aliases: map[string]string{"hash-table": "hashing"}}, []string{"1"}},
+		{"every tag must be present", "sum", Filter{Tags: []string{"array", "two-pointers"}}, []string{"2"}},
+		{"unknown word", "graph", Filter{}, []string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results := idx.Search(test.query, test.filter)
+			if test.want == nil {
+				if results != nil {
+					t.Errorf("Search(%q) = %v, want nil", test.query, results)
+				}
+				return
+			}
+			if got := resultIDs(results); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Search(%q) = %v, want %v", test.query, got, test.want)
+			}
+		})
+	}
+}
+
+func TestIndexKeepsTermsSorted(t *testing.T) {
+	idx := catalog()
+	check := func(step string) {
+		t.Helper()
+		want := make([]string, 0, len(idx.postings))
+		for term := range idx.postings {
+			want = append(want, term)
+		}
+		sort.Strings(want)
+		if !reflect.DeepEqual(idx.terms, want) {
+			t.Errorf("after %s terms = %v, want %v", step, idx.terms, want)
+		}
+	}
+	check("replace")
+	idx.Add(Document{ID: "5", Title: "Zigzag Conversion", Description: "Write the string in a zigzag."})
+	check("add")
+	if got := resultIDs(idx.Search("zig*", Filter{})); !reflect.DeepEqual(got, []string{"5"}) {
+		t.Errorf("Search(zig*) = %v after add, want [5]", got)
+	}
+	idx.Add(Document{ID: "5", Title: "Zebra"})
+	check("re-add")
+	idx.Remove("3")
+	check("remove")
+	if got := resultIDs(idx.Search("rev*", Filter{})); len(got) != 0 {
+		t.Errorf("Search(rev*) = %v after remove, want none", got)
+	}
+	idx.Remove("missing")
+	check("removing a missing document")
+}
+
+func TestSearchConcurrently(t *testing.T) {
+	idx := catalog()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			idx.Add(Document{ID: fmt.Sprint(100 + i), Title: fmt.Sprintf("Sum variant %d", i)})
+		}(i)
+		go func() {
+			defer wg.Done()
+			idx.Search("su*", Filter{})
+		}()
+	}
+	wg.Wait()
+	if got := len(idx.Search("variant", Filter{})); got != 8 {
+		t.Errorf("found %d variants, want 8", got)
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []clause
+	}{
+		{"two sum", []clause{{terms: []string{"two"}}, {terms: []string{"sum"}}}},
+		{`"linked list" rev*`, []clause{{terms: []string{"linked", "list"}}, {terms: []string{"rev"}, prefix: true}}},
+		{"hash-table*", []clause{{terms: []string{"hash"}}, {terms: []string{"table"}, prefix: true}}},
+		{`"" * !`, nil},
+	}
+	for _, test := range tests {
+		if got := parseQuery(test.query); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseQuery(%q) = %#v, want %#v", test.query, got, test.want)
+		}
+	}
+}
+
+func TestSearchTranslations(t *testing.T) {
+	idx := catalog()
+	idx.Add(Document{
+		ID: "5", Title: "Binary Search", Description: "Find the target in a sorted array.",
+		Translations: []Translation{
+			{Title: "Recherche binaire", Description: "Trouvez la cible dans un tableau trié."},
+			{Title: "द्विआधारी खोज", Description: "क्रमबद्ध सरणी में लक्ष्य खोजें।"},
+		},
+	})
+	tests := []struct {
+		name    string
+		query   string
+		want    []string
+		snippet string
+	}{
+		{"translated title", "recherche", []string{"5"}, "Find the target in a sorted array."},
+		{"translated description", "tableau trie", []string{"5"}, "Trouvez la cible dans un <mark>tableau</mark> <mark>trié</mark>."},
+		{"other script", "खोजें", []string{"5"}, "क्रमबद्ध सरणी में लक्ष्य <mark>खोजें</mark>।"},
+		{"source description first", "target", []string{"1", "5"}, "Find the <mark>target</mark> in a sorted array."},
+		{"phrase does not cross statements", `"array trouvez"`, []string{}, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			results := idx.Search(test.query, Filter{})
+			if got := resultIDs(results); !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("Search(%q) = %v, want %v", test.query, got, test.want)
+			}
+			for _, result := range results {
+				if result.ID == "5" && result.Snippet != test.snippet {
+					t.Errorf("snippet = %q, want %q", result.Snippet, test.snippet)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// snippetLength is the rough number of bytes of text around the first match
+const snippetLength = 160
+
+// snippetText picks the first description, source or translated, with a
+// matched word in it
+func snippetText(doc Document, matched map[string]bool) string {
+	for _, description := range doc.descriptions() {
+		for _, t := range tokenize(description) {
+			if matched[t.term] {
+				return description
+			}
+		}
+	}
+	return doc.Description
+}
+
+// snippet cuts the part of the description around the first matched word and
+// wraps matched words in <mark>. The text is HTML escaped.
+func snippet(doc Document, matched map[string]bool) string {
+	text := snippetText(doc, matched)
+	tokens := tokenize(text)
+	first := -1
+	for i, t := range tokens {
+		if matched[t.term] {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		// only the title or tags matched, show the start of the description
+		if len(tokens) == 0 {
+			return ""
+		}
+		first = 0
+	}
+	start := 0
+	if tokens[first].end > snippetLength*3/4 {
+		// a long first match can start less than a quarter into the text
+		start = max(tokens[first].start-snippetLength/4, 0)
+	}
+	end := start + snippetLength
+	if end > len(text) {
+		end = len(text)
+	}
+	// keep whole words at both ends
+	for _, t := range tokens {
+		if t.start < start && t.end > start {
+			start = t.start
+		}
+		if t.start < end && t.end > end {
+			end = t.end
+		}
+	}
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+
+	var out strings.Builder
+	if start > 0 {
+		out.WriteString("…")
+	}
+	cursor := start
+	for _, t := range tokens {
+		if t.start < start || t.end > end || !matched[t.term] {
+			continue
+		}
+		out.WriteString(html.EscapeString(text[cursor:t.start]))
+		out.WriteString("<mark>")
+		out.WriteString(html.EscapeString(text[t.start:t.end]))
+		out.WriteString("</mark>")
+		cursor = t.end
+	}
+	out.WriteString(html.EscapeString(text[cursor:end]))
+	if end < len(text) {
+		out.WriteString("…")
+	}
+	return strings.Join(strings.Fields(out.String()), " ")
+}
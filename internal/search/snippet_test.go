@@ -0,0 +1,56 @@
+package search
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSnippet(t *testing.T) {
+	long := strings.Repeat("a", 120)
+	tests := []struct {
+		name        string
+		description string
+		matched     []string
+		want        string
+	}{
+		{"no description", "", []string{"sum"}, ""},
+		{"title match shows the start", "Add two numbers.", nil, "Add two numbers."},
+		{"marks every match", "Sum the sums, then sum.", []string{"sum"}, "<mark>Sum</mark> the sums, then <mark>sum</mark>."},
+		{"escapes html", "Is a < b & sum?", []string{"sum"}, "Is a &lt; b &amp; <mark>sum</mark>?"},
+		{"long first match near the start", "x " + long + " tail", []string{long}, "x <mark>" + long + "</mark> tail"},
+		{"long word at the start", long + strings.Repeat(" word", 30), []string{long}, "<mark>" + long + "</mark>" + strings.Repeat(" word", 8) + "…"},
+		{
+			"cuts around a late match",
+			strings.Repeat("word ", 40) + "needle" + strings.Repeat(" word", 40),
+			[]string{"needle"},
+			"…" + strings.Repeat("word ", 8) + "<mark>needle</mark>" + strings.Repeat(" word", 23) + "…",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matched := map[string]bool{}
+			for _, term := range test.matched {
+				matched[term] = true
+			}
+			got := snippet(Document{Description: test.description}, matched)
+			if got != test.want {
+				t.Errorf("snippet() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSnippetKeepsWholeRunes(t *testing.T) {
+	description := strings.Repeat("é ", 100) + "needle" + strings.Repeat(" ü—", 100)
+	got := snippet(Document{Description: description}, map[string]bool{"needle": true})
+	if !strings.Contains(got, "<mark>needle</mark>") {
+		t.Errorf("snippet() = %q, want the match marked", got)
+	}
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") {
+		t.Errorf("snippet() = %q, want it cut at both ends", got)
+	}
+	if !utf8.ValidString(got) {
+		t.Errorf("snippet() = %q cuts a rune", got)
+	}
+}
@@ -0,0 +1,93 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// token is one indexed word with its position among the words of a field and
+// its byte range in the original text
+type token struct {
+	term     string
+	position int
+	start    int
+	end      int
+}
+
+// fold lowercases a rune and drops its accents, so "Résumé" matches "resume"
+func fold(r rune) string {
+	if r < unicode.MaxASCII {
+		return string(unicode.ToLower(r))
+	}
+	var folded strings.Builder
+	for _, d := range norm.NFKD.String(string(r)) {
+		if !unicode.Is(unicode.Mn, d) {
+			folded.WriteRune(unicode.ToLower(d))
+		}
+	}
+	return folded.String()
+}
+
+// tokenize splits text into folded words of letters and digits. Marks inside
+// a word belong to it, like the vowel signs of Devanagari.
+func tokenize(text string) []token {
+	var tokens []token
+	var term strings.Builder
+	start := -1
+	flush := func(end int) {
+		if start >= 0 && term.Len() > 0 {
+			tokens = append(tokens, token{term: term.String(), position: len(tokens), start: start, end: end})
+		}
+		term.Reset()
+		start = -1
+	}
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || (start >= 0 && unicode.IsMark(r)) {
+			if start < 0 {
+				start = i
+			}
+			term.WriteString(fold(r))
+			continue
+		}
+		flush(i)
+	}
+	flush(len(text))
+	return tokens
+}
+
+// clause is one part of a query every match must satisfy
+type clause struct {
+	terms  []string // several terms form a phrase
+	prefix bool     // the single term also matches longer words
+}
+
+// parseQuery reads plain words, "quoted phrases" and prefix* words
+func parseQuery(query string) []clause {
+	var clauses []clause
+	for i, part := range strings.Split(query, `"`) {
+		if i%2 == 1 {
+			// inside quotes
+			var terms []string
+			for _, t := range tokenize(part) {
+				terms = append(terms, t.term)
+			}
+			if len(terms) > 0 {
+				clauses = append(clauses, clause{terms: terms})
+			}
+			continue
+		}
+		for _, word := range strings.Fields(part) {
+			tokens := tokenize(word)
+			if len(tokens) == 0 {
+				continue
+			}
+			for j, t := range tokens {
+				prefix := j == len(tokens)-1 && strings.HasSuffix(word, "*")
+				clauses = append(clauses, clause{terms: []string{t.term}, prefix: prefix})
+			}
+		}
+	}
+	return clauses
+}
@@ -0,0 +1,25 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []token
+	}{
+		{"", nil},
+		{"Two Sum", []token{{"two", 0, 0, 3}, {"sum", 1, 4, 7}}},
+		{"hash-table, 2D!", []token{{"hash", 0, 0, 4}, {"table", 1, 5, 10}, {"2d", 2, 12, 14}}},
+		{"Résumé", []token{{"resume", 0, 0, 8}}},
+		{"खोजें सरणी", []token{{"खोज", 0, 0, 15}, {"सरणी", 1, 16, 28}}},
+		{"́a", []token{{"a", 0, 2, 3}}},
+	}
+	for _, test := range tests {
+		if got := tokenize(test.text); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("tokenize(%q) = %#v, want %#v", test.text, got, test.want)
+		}
+	}
+}
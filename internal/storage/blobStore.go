@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// Blobs is the store used by the server, set up by Init
+var Blobs *BlobStore
+
+// ErrTooLarge is returned for content over the limit of PutLimited
+var ErrTooLarge = errors.New("the content is over the size limit")
+
+var blobHashPattern = regexp.MustCompile("^[0-9a-f]{64}$")
+
+// BlobStore keeps files in a local content-addressed directory. Every blob is
+// named by the SHA-256 of its content, so storing the same data twice is free.
+type BlobStore struct {
+	Root string
+}
+
+// Init opens the blob store directory from BLOB_STORE_DIR, defaulting to ./blobs
+func Init() {
+	root := os.Getenv("BLOB_STORE_DIR")
+	if root == "" {
+		root = "./blobs"
+	}
+	store, err := NewBlobStore(root)
+	if err != nil {
+		fmt.Println("Could not open blob store:", err)
+		return
+	}
+	Blobs = store
+}
+
+// NewBlobStore creates the root directory if needed
+func NewBlobStore(root string) (*BlobStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &BlobStore{Root: root}, nil
+}
+
+// Path returns where the blob with the given hash lives on disk
+func (s *BlobStore) Path(hash string) (string, error) {
+	if !blobHashPattern.MatchString(hash) {
+		return "", fmt.Errorf("invalid blob hash: %q", hash)
+	}
+	return filepath.Join(s.Root, hash[:2], hash), nil
+}
+
+// Put streams the content into the store and returns its hash and size
+func (s *BlobStore) Put(content io.Reader) (string, int64, error) {
+	return s.put(content, -1)
+}
+
+// PutLimited is Put for content of at most limit bytes. Larger content is
+// rejected with ErrTooLarge and nothing is stored.
+func (s *BlobStore) PutLimited(content io.Reader, limit int64) (string, int64, error) {
+	return s.put(io.LimitReader(content, limit+1), limit)
+}
+
+// put stores the content, a negative limit means no limit
+func (s *BlobStore) put(content io.Reader, limit int64) (string, int64, error) {
+	tmp, err := os.CreateTemp(s.Root, "upload-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	if limit >= 0 && size > limit {
+		return "", 0, ErrTooLarge
+	}
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	path, _ := s.Path(hash)
+	if _, err := os.Stat(path); err == nil {
+		return hash, size, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", 0, err
+	}
+	return hash, size, nil
+}
+
+// Open returns a reader for the blob, the caller has to close it
+func (s *BlobStore) Open(hash string) (*os.File, error) {
+	path, err := s.Path(hash)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("blob %s not found", hash)
+	}
+	return file, err
+}
+
+// Exists reports whether the blob is stored
+func (s *BlobStore) Exists(hash string) bool {
+	path, err := s.Path(hash)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
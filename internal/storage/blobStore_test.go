@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// storedFiles lists every file under the root, blobs and leftovers alike
+func storedFiles(t *testing.T, root string) []string {
+	t.Helper()
+	var files []string
+	err := filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			files = append(files, path)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestPutLimited(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		limit   int64
+		wantErr error
+	}{
+		{"empty", "", 4, nil},
+		{"under the limit", "abc", 4, nil},
+		{"at the limit", "abcd", 4, nil},
+		{"over the limit", "abcde", 4, ErrTooLarge},
+		{"far over the limit", strings.Repeat("x", 1<<16), 4, ErrTooLarge},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store, err := NewBlobStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			hash, size, err := store.PutLimited(strings.NewReader(test.content), test.limit)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("PutLimited() error = %v, want %v", err, test.wantErr)
+			}
+			files := storedFiles(t, store.Root)
+			if test.wantErr != nil {
+				if len(files) != 0 {
+					t.Errorf("rejected content left %v in the store", files)
+				}
+				return
+			}
+			if size != int64(len(test.content)) || len(files) != 1 {
+				t.Fatalf("size = %d, files = %v, want %d bytes in one blob", size, files, len(test.content))
+			}
+			file, err := store.Open(hash)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			if stored, _ := io.ReadAll(file); string(stored) != test.content {
+				t.Errorf("stored %q, want %q", stored, test.content)
+			}
+		})
+	}
+}
+
+func TestPutDeduplicates(t *testing.T) {
+	store, err := NewBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _, err := store.Put(strings.NewReader("same"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _, err := store.Put(strings.NewReader("same"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second || len(storedFiles(t, store.Root)) != 1 {
+		t.Errorf("storing the same content twice gave %s and %s", first, second)
+	}
+	if !store.Exists(first) || store.Exists(strings.Repeat("0", 64)) {
+		t.Error("Exists does not tell stored blobs apart")
+	}
+	if _, err := store.Path("../etc/passwd"); err == nil {
+		t.Error("Path accepted an invalid hash")
+	}
+}
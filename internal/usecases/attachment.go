@@ -0,0 +1,123 @@
+package usecases
+
+import (
+	"code-compiler/internal/middlewares"
+	"code-compiler/internal/models"
+	"encoding/json"
+	"mime"
+	"net/http"
+)
+
+// UploadAttachment stores an image or file sent as the multipart field "file"
+// for the question of the query. The response carries the URL to use in the
+// statement.
+func (svc *QuestionService) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	questionID := r.URL.Query().Get("questionId")
+	if questionID == "" {
+		res.Message = "questionId is required"
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, models.MaxFileSize+1<<20)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		res.Message = "file is required: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	defer file.Close()
+	userId, _ := r.Context().Value(middlewares.UserIDKey).(string)
+	attachment, err := svc.Controller.AddAttachment(questionID, header.Filename, file, header.Size, userId)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = attachment
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (svc *QuestionService) GetAttachments(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	questionID := r.URL.Query().Get("questionId")
+	if questionID == "" {
+		res.Message = "questionId is required"
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	attachments, err := svc.Controller.GetAttachments(questionID)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = attachments
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (svc *QuestionService) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		res.Message = "id is required"
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	if err := svc.Controller.DeleteAttachment(id); err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Message = "attachment deleted"
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// ServeAttachment sends the content of an attachment. The content never
+// changes for an id, so it is cached for good and revalidated by its hash.
+func (svc *QuestionService) ServeAttachment(w http.ResponseWriter, r *http.Request) {
+	res := &models.Response{}
+	id := r.URL.Query().Get("id")
+	attachment, file, err := svc.Controller.OpenAttachment(id, middlewares.IsAdminRequest(r))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	defer file.Close()
+	disposition := "inline"
+	if attachment.Kind != models.AttachmentImage {
+		disposition = "attachment"
+	}
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+	w.Header().Set("ETag", `"`+attachment.Blob+`"`)
+	w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	http.ServeContent(w, r, attachment.Name, attachment.CreatedAt, file)
+}
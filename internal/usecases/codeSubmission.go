@@ -6,6 +6,7 @@ import (
 	"code-compiler/internal/models"
 	"code-compiler/internal/repository"
 	"encoding/json"
+	"errors"
 	"net/http"
 )
 
@@ -43,6 +44,7 @@ func (svc *CodeRunnerService) RunTest(w http.ResponseWriter, r *http.Request) {
 		Language:   data.Language,
 		Code:       data.Code,
 		QuestionId: data.QuestionId,
+		IsAdmin:    middlewares.IsAdminRequest(r),
 	})
 
 	if err != nil {
@@ -87,24 +89,41 @@ func (svc *CodeRunnerService) SubmitTest(w http.ResponseWriter, r *http.Request)
 		w.WriteHeader(http.StatusBadRequest)
 	}
 	// Call the repository function to execute the code
-	failedCase, passedTestCases, totalTestCases, err := svc.Runner.ExecuteSubmit(commontypes.CodeRunnerType{
+	submission, err := svc.Runner.ExecuteSubmit(commontypes.CodeRunnerType{
 		UserId:     userId,
 		Language:   data.Language,
 		Code:       data.Code,
 		QuestionId: data.QuestionId,
+		IsAdmin:    middlewares.IsAdminRequest(r),
 	})
 
+	if submission != nil && !middlewares.IsAdminRequest(r) {
+		redacted := submission.Redact()
+		submission = &redacted
+		if err != nil {
+			err = errors.New(submission.Err)
+		}
+	}
 	if err != nil {
 		res.Status = false
 		res.Message = err.Error()
 		w.WriteHeader(http.StatusInternalServerError)
 	}
-	if res.Status {
+	if submission != nil {
 		res.Data = map[string]interface{}{
-			"failedCase":      failedCase,
-			"passedTestCases": passedTestCases,
-			"totalTestCases":  totalTestCases,
+			"failedCase":      submission.FailedCase,
+			"passedTestCases": submission.PassedTestCases,
+			"totalTestCases":  submission.TotalTestCases,
+			"verdict":         submission.Verdict,
+			"score":           submission.Score,
+			"maxScore":        submission.MaxScore,
+			"groupResults":    submission.GroupResults,
+			"runtime":         submission.Runtime,
+			"memory":          submission.Memory,
+			"performance":     submission.Performance,
 		}
+	}
+	if res.Status {
 		w.WriteHeader(http.StatusOK)
 	}
 	if err := json.NewEncoder(w).Encode(res); err != nil {
@@ -136,6 +155,11 @@ func (svc *CodeRunnerService) GetUserSubmission(w http.ResponseWriter, r *http.R
 	}
 	// Call the controller to get questions by the tag
 	questions, err := svc.Runner.GetUserSubmission(userId, question)
+	if !middlewares.IsAdminRequest(r) {
+		for i := range questions {
+			questions[i] = questions[i].Redact()
+		}
+	}
 	if err != nil {
 		res.Status = false
 		res.Message = err.Error()
@@ -153,3 +177,56 @@ func (svc *CodeRunnerService) GetUserSubmission(w http.ResponseWriter, r *http.R
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
+
+// StartRejudge queues a background rejudge of the selected submissions
+func (svc *CodeRunnerService) StartRejudge(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	userId, _ := r.Context().Value(middlewares.UserIDKey).(string)
+	var request models.RejudgeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		res.Message = "Invalid request body: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	job, err := svc.Runner.StartRejudge(request, userId)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = job
+	res.Message = "Rejudge queued"
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (svc *CodeRunnerService) GetRejudgeJob(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	jobId := r.URL.Query().Get("id")
+	if jobId == "" {
+		res.Message = "id is required"
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	job, err := svc.Runner.GetRejudgeJob(jobId)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = job
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
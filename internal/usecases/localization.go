@@ -0,0 +1,136 @@
+package usecases
+
+import (
+	"code-compiler/internal/middlewares"
+	"code-compiler/internal/models"
+	"code-compiler/internal/repository"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// preferredLocales reads the statement languages the client asks for, the
+// lang query parameter first and then the Accept-Language header
+func preferredLocales(r *http.Request) []language.Tag {
+	var preferred []language.Tag
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		if tag, err := language.Parse(lang); err == nil {
+			preferred = append(preferred, tag)
+		}
+	}
+	if accepted, _, err := language.ParseAcceptLanguage(r.Header.Get("Accept-Language")); err == nil {
+		preferred = append(preferred, accepted...)
+	}
+	return preferred
+}
+
+// localizeQuestions puts the listed questions in the preferred locale
+func localizeQuestions(questions []models.Question, preferred []language.Tag) {
+	for i := range questions {
+		questions[i] = questions[i].Localize(questions[i].MatchLocale(preferred))
+		questions[i].Translations = nil
+	}
+}
+
+// SetTranslation adds or replaces the statement of the question in the locale of the query
+func (svc *QuestionService) SetTranslation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	questionID := r.URL.Query().Get("id")
+	locale := r.URL.Query().Get("locale")
+	if questionID == "" || locale == "" {
+		res.Message = "id and locale are required"
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	var translation models.Translation
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&translation); err != nil {
+		res.Message = "Invalid request body: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	userId, _ := r.Context().Value(middlewares.UserIDKey).(string)
+	question, err := svc.Controller.SetTranslation(questionID, locale, translation, userId)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = question
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (svc *QuestionService) DeleteTranslation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	questionID := r.URL.Query().Get("id")
+	locale := r.URL.Query().Get("locale")
+	if questionID == "" || locale == "" {
+		res.Message = "id and locale are required"
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	userId, _ := r.Context().Value(middlewares.UserIDKey).(string)
+	question, err := svc.Controller.DeleteTranslation(questionID, locale, userId)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = question
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// GetLocaleReport lists the questions with missing or stale translations. The
+// locales query parameter overrides the configured required locales.
+func (svc *QuestionService) GetLocaleReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	required := repository.RequiredLocales()
+	if locales := r.URL.Query().Get("locales"); locales != "" {
+		required = []string{}
+		for _, locale := range strings.Split(locales, ",") {
+			normalized, err := models.NormalizeLocale(locale)
+			if err != nil {
+				res.Message = err.Error()
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(res)
+				return
+			}
+			required = append(required, normalized)
+		}
+	}
+	reports, err := svc.Controller.GetLocaleReport(required)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = map[string]interface{}{
+		"requiredLocales": required,
+		"questions":       reports,
+	}
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -1,11 +1,17 @@
 package usecases
 
 import (
+	"archive/zip"
 	"code-compiler/internal/middlewares"
 	"code-compiler/internal/models"
 	"code-compiler/internal/repository"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type QuestionService struct {
@@ -22,6 +28,9 @@ func (svc *QuestionService) CreateQuestion(w http.ResponseWriter, r *http.Reques
 		res.Message = err.Error()
 		w.WriteHeader(http.StatusInternalServerError)
 	}
+	if question.CreatedBy == "" {
+		question.CreatedBy, _ = r.Context().Value(middlewares.UserIDKey).(string)
+	}
 	createdQuestion, err := svc.Controller.CreateQuestion(&question)
 	if err != nil {
 		res.Status = false
@@ -93,9 +102,28 @@ func (svc *QuestionService) GetQuestionBySlug(w http.ResponseWriter, r *http.Req
 		res.Message = err.Error()
 		w.WriteHeader(http.StatusInternalServerError)
 	}
-	// Populate the response with the retrieved question
+	if res.Status && question == nil {
+		// Links to a slug from before a rename move to the current one
+		if current, err := svc.Controller.FindSlugRedirect(slug); err == nil && current != "" {
+			redirect := *r.URL
+			query := redirect.Query()
+			query.Set("slug", current)
+			redirect.RawQuery = query.Encode()
+			http.Redirect(w, r, redirect.String(), http.StatusMovedPermanently)
+			return
+		}
+	}
+	if res.Status && (question == nil || !question.IsPublished() && !middlewares.IsAdminRequest(r)) {
+		res.Status = false
+		res.Message = "question not found"
+		w.WriteHeader(http.StatusNotFound)
+	}
+	// Populate the response with the view of the question the user may see, in their language
 	if res.Status {
-		res.Data = question
+		locale := question.MatchLocale(preferredLocales(r))
+		w.Header().Set("Content-Language", locale)
+		w.Header().Set("Vary", "Accept-Language")
+		res.Data = question.Localize(locale).ForView(models.QuestionViewFor(question, middlewares.IsAdminRequest(r)))
 		w.WriteHeader(http.StatusOK)
 	}
 	if err := json.NewEncoder(w).Encode(res); err != nil {
@@ -109,16 +137,50 @@ func (svc *QuestionService) GetQuestions(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Content-Type", "application/json")
 	res := &models.Response{}
 	queryParams := r.URL.Query()
-	title := queryParams.Get("search")
-	difficulty := queryParams.Get("difficulty")
-	status := queryParams.Get("status")
 	userId, ok := r.Context().Value(middlewares.UserIDKey).(string)
 	if !ok {
 		userId = ""
 	}
+	query := models.QuestionQuery{
+		UserID:             userId,
+		Search:             strings.TrimSpace(queryParams.Get("search")),
+		Status:             queryParams.Get("status"),
+		Lifecycle:          queryParams.Get("lifecycle"),
+		IncludeUnpublished: middlewares.IsAdminRequest(r),
+		Sort:               queryParams.Get("sort"),
+		Descending:         queryParams.Get("order") == "desc",
+		Page:               1,
+		Limit:              defaultPageLimit(),
+	}
+	// Filters repeat the parameter or separate values with commas
+	query.Difficulties = listParam(queryParams["difficulty"], strings.ToLower)
+	query.Tags = models.NormalizeTags(listParam(queryParams["tags"], nil))
+	var err error
+	if page := queryParams.Get("page"); page != "" {
+		if query.Page, err = strconv.Atoi(page); err != nil {
+			query.Page = 0
+		}
+	}
+	if limit := queryParams.Get("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil {
+			query.Limit = 0
+		}
+	}
+	if order := queryParams.Get("order"); order != "" && order != "asc" && order != "desc" {
+		res.Message = "order must be asc or desc"
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	if err := query.Validate(); err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
 	res.Status = true
 	// Call the controller to get all questions
-	questions, totalCount, err := svc.Controller.GetQuestions(userId, 0, title, difficulty, status)
+	questions, totalCount, err := svc.Controller.GetQuestions(query)
 	if err != nil {
 		res.Status = false
 		res.Message = err.Error()
@@ -126,9 +188,14 @@ func (svc *QuestionService) GetQuestions(w http.ResponseWriter, r *http.Request)
 	}
 	// Populate the response with the retrieved questions
 	if res.Status {
+		localizeQuestions(questions, preferredLocales(r))
 		res.Data = map[string]interface{}{
 			"questions":  questions,
 			"totalCount": totalCount,
+			"page":       query.Page,
+			"limit":      query.Limit,
+			"totalPages": (totalCount + int64(query.Limit) - 1) / int64(query.Limit),
+			"sort":       query.SortOrder(),
 		}
 		w.WriteHeader(http.StatusOK)
 	}
@@ -139,6 +206,32 @@ func (svc *QuestionService) GetQuestions(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// defaultPageLimit is the page size when the client asks for none
+func defaultPageLimit() int {
+	limit, err := strconv.Atoi(os.Getenv("PAGE_LIMIT"))
+	if err != nil || limit < 1 || limit > models.MaxPageLimit {
+		return 10
+	}
+	return limit
+}
+
+// listParam splits repeated and comma separated query values, dropping empty ones
+func listParam(values []string, normalize func(string) string) []string {
+	var list []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if normalize != nil {
+				item = normalize(item)
+			}
+			if item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
 func (svc *QuestionService) GetTestCases(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	res := &models.Response{}
@@ -173,36 +266,40 @@ func (svc *QuestionService) GetTestCases(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// UpdateTestCases applies a partial update to a test case batch. Unknown fields
+// are rejected.
 func (svc *QuestionService) UpdateTestCases(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	res := &models.Response{}
-	res.Status = true
-	// Get the question ID from the URL parameters
-	vars := r.URL.Query()
-	testCaseId := vars.Get("id")
-	// Decode the incoming JSON request for the updated data
-	var updatedData map[string]interface{}
-	if err := json.NewDecoder(r.Body).Decode(&updatedData); err != nil {
-		res.Status = false
+	testCaseId := r.URL.Query().Get("id")
+	var patch models.TestCasePatch
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patch); err != nil {
 		res.Message = err.Error()
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
 	}
-	// Call the controller to update the question by ID
-	updatedTestCase, err := svc.Controller.UpdateTestCases(testCaseId, updatedData)
+	if err := patch.Validate(); err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	userId, _ := r.Context().Value(middlewares.UserIDKey).(string)
+	updatedTestCase, err := svc.Controller.UpdateTestCases(testCaseId, &patch, userId)
 	if err != nil {
-		res.Status = false
 		res.Message = err.Error()
 		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(res)
+		return
 	}
-	// Populate the response with the updated question
-	if res.Status {
-		res.Data = updatedTestCase
-		w.WriteHeader(http.StatusCreated)
-	}
+	res.Status = true
+	res.Data = updatedTestCase
+	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(res); err != nil {
-		res.Status = false
-		res.Message = err.Error()
-		w.WriteHeader(http.StatusInternalServerError)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
@@ -226,9 +323,13 @@ func (svc *QuestionService) GetQuestionsByTag(w http.ResponseWriter, r *http.Req
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 
-	// Populate the response with the retrieved questions
+	// Populate the response with the public view of the questions
 	if res.Status {
 		res.Status = false
+		localizeQuestions(questions, preferredLocales(r))
+		for i := range questions {
+			questions[i] = questions[i].ForView(models.ViewPublic)
+		}
 		res.Data = questions
 		w.WriteHeader(http.StatusOK)
 	}
@@ -239,36 +340,40 @@ func (svc *QuestionService) GetQuestionsByTag(w http.ResponseWriter, r *http.Req
 	}
 }
 
+// UpdateQuestionById applies a partial update to a question. Unknown fields,
+// including identity and statistics, are rejected.
 func (svc *QuestionService) UpdateQuestionById(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	res := &models.Response{}
-	res.Status = true
-	// Get the question ID from the URL parameters
-	vars := r.URL.Query()
-	questionID := vars.Get("id")
-	// Decode the incoming JSON request for the updated data
-	var updatedData map[string]interface{}
-	if err := json.NewDecoder(r.Body).Decode(&updatedData); err != nil {
-		res.Status = false
+	questionID := r.URL.Query().Get("id")
+	var patch models.QuestionPatch
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patch); err != nil {
 		res.Message = err.Error()
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
 	}
-	// Call the controller to update the question by ID
-	updatedQuestion, err := svc.Controller.UpdateQuestionById(questionID, updatedData)
+	if err := patch.Validate(); err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	userId, _ := r.Context().Value(middlewares.UserIDKey).(string)
+	updatedQuestion, err := svc.Controller.UpdateQuestionById(questionID, &patch, userId)
 	if err != nil {
-		res.Status = false
 		res.Message = err.Error()
 		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(res)
+		return
 	}
-	// Populate the response with the updated question
-	if res.Status {
-		res.Data = updatedQuestion
-		w.WriteHeader(http.StatusCreated)
-	}
+	res.Status = true
+	res.Data = updatedQuestion
+	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(res); err != nil {
-		res.Status = false
-		res.Message = err.Error()
-		w.WriteHeader(http.StatusInternalServerError)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
@@ -283,6 +388,7 @@ func (svc *QuestionService) CreateTestCase(w http.ResponseWriter, r *http.Reques
 		res.Message = err.Error()
 		w.WriteHeader(http.StatusInternalServerError)
 	}
+	testCase.CreatedBy, _ = r.Context().Value(middlewares.UserIDKey).(string)
 	// Call the controller to create the test case
 	createdTestCase, err := svc.Controller.CreateTestCase(&testCase)
 	if err != nil {
@@ -301,3 +407,578 @@ func (svc *QuestionService) CreateTestCase(w http.ResponseWriter, r *http.Reques
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
+
+// UploadTestData stores a large test input or output sent as the multipart field "file"
+func (svc *QuestionService) UploadTestData(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		res.Message = "file is required: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	defer file.Close()
+	hash, size, err := svc.Controller.StoreTestData(file)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = map[string]interface{}{
+		"blob": hash,
+		"size": size,
+	}
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// GetPendingTestCases lists the batches waiting for review, or shows one batch
+// with the reference solution's dry run when an id is passed
+func (svc *QuestionService) GetPendingTestCases(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	queryParams := r.URL.Query()
+	if testCaseId := queryParams.Get("id"); testCaseId != "" {
+		testCase, err := svc.Controller.GetTestCasesById(testCaseId)
+		if err != nil {
+			res.Message = err.Error()
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(res)
+			return
+		}
+		dryRun, err := svc.Controller.DryRunTestCase(testCase)
+		if err != nil {
+			res.Message = err.Error()
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(res)
+			return
+		}
+		res.Status = true
+		res.Data = map[string]interface{}{
+			"testCase": testCase,
+			"dryRun":   dryRun,
+		}
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(res); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		}
+		return
+	}
+	testCases, err := svc.Controller.GetPendingTestCases(queryParams.Get("questionId"))
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = testCases
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// ReviewTestCase approves or rejects a test case batch
+func (svc *QuestionService) ReviewTestCase(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	reviewerId, _ := r.Context().Value(middlewares.UserIDKey).(string)
+	testCaseId := r.URL.Query().Get("id")
+	var requestBody struct {
+		Status  string `json:"status"`
+		Comment string `json:"comment"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		res.Message = "Invalid request body: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	if testCaseId == "" {
+		res.Message = "id is required"
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	testCase, err := svc.Controller.ReviewTestCase(testCaseId, requestBody.Status, reviewerId, requestBody.Comment)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = testCase
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// ExportQuestion downloads the question as a zipped Kattis problem package
+func (svc *QuestionService) ExportQuestion(w http.ResponseWriter, r *http.Request) {
+	res := &models.Response{}
+	questionID := r.URL.Query().Get("id")
+	archive, err := os.CreateTemp("", "export-*.zip")
+	if err != nil {
+		res.Message = err.Error()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+	if err := svc.Controller.ExportQuestion(questionID, archive); err != nil {
+		res.Message = err.Error()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"question-%s.zip\"", questionID))
+	http.ServeContent(w, r, "", time.Time{}, archive)
+}
+
+// ImportQuestion creates a question from a zipped Kattis problem package sent
+// as the multipart field "file". The optional "difficulty" field is used when
+// the package does not carry one.
+func (svc *QuestionService) ImportQuestion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	userId, _ := r.Context().Value(middlewares.UserIDKey).(string)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		res.Message = "file is required: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	defer file.Close()
+	archive, err := zip.NewReader(file, header.Size)
+	if err != nil {
+		res.Message = "file is not a zip archive: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	question, testCases, err := svc.Controller.ImportQuestion(archive, r.FormValue("difficulty"), userId)
+	if err != nil {
+		res.Message = err.Error()
+		if question != nil {
+			res.Data = map[string]interface{}{
+				"question":  question,
+				"testCases": testCases,
+			}
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Message = "Question imported successfully"
+	res.Data = map[string]interface{}{
+		"question":  question,
+		"testCases": testCases,
+	}
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// UploadTestCases creates test case batches from a zip of NN.in/NN.ans files
+// sent as the multipart field "file" together with "questionId" and the
+// optional "group" and "visibility" fields
+func (svc *QuestionService) UploadTestCases(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	userId, _ := r.Context().Value(middlewares.UserIDKey).(string)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		res.Message = "file is required: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	defer file.Close()
+	questionId := r.FormValue("questionId")
+	if questionId == "" {
+		res.Message = "questionId is required"
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	archive, err := zip.NewReader(file, header.Size)
+	if err != nil {
+		res.Message = "file is not a zip archive: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	testCases, report, err := svc.Controller.UploadTestCases(archive, questionId, r.FormValue("group"), r.FormValue("visibility"), userId)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = len(testCases) > 0
+	res.Message = fmt.Sprintf("%d test case batches created", len(testCases))
+	res.Data = map[string]interface{}{
+		"testCases": testCases,
+		"report":    report,
+	}
+	if len(testCases) == 0 {
+		// Nothing was created, the report says why for every file
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// GetQuestionRevisions lists the revisions of a question, or returns one
+// revision with its snapshot when "revision" is passed
+func (svc *QuestionService) GetQuestionRevisions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	queryParams := r.URL.Query()
+	questionID := queryParams.Get("id")
+	var data interface{}
+	var err error
+	if revisionParam := queryParams.Get("revision"); revisionParam != "" {
+		revision, convErr := strconv.Atoi(revisionParam)
+		if convErr != nil {
+			res.Message = "revision must be a number"
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(res)
+			return
+		}
+		data, err = svc.Controller.GetQuestionRevision(questionID, revision)
+	} else {
+		data, err = svc.Controller.GetQuestionRevisions(questionID)
+	}
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = data
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// DiffQuestionRevisions compares the revisions "from" and "to" field by field
+func (svc *QuestionService) DiffQuestionRevisions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	queryParams := r.URL.Query()
+	from, fromErr := strconv.Atoi(queryParams.Get("from"))
+	to, toErr := strconv.Atoi(queryParams.Get("to"))
+	if fromErr != nil || toErr != nil {
+		res.Message = "from and to revisions are required"
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	changes, err := svc.Controller.DiffQuestionRevisions(queryParams.Get("id"), from, to)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = changes
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// RollbackQuestion restores an earlier revision of the question as a new revision
+func (svc *QuestionService) RollbackQuestion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	userId, _ := r.Context().Value(middlewares.UserIDKey).(string)
+	queryParams := r.URL.Query()
+	revision, err := strconv.Atoi(queryParams.Get("revision"))
+	if err != nil {
+		res.Message = "revision is required"
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	question, err := svc.Controller.RollbackQuestion(queryParams.Get("id"), revision, userId)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Message = fmt.Sprintf("Question rolled back to revision %d", revision)
+	res.Data = question
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// SetLifecycle moves a question to another lifecycle state. A publishAt is
+// required when scheduling.
+func (svc *QuestionService) SetLifecycle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	questionID := r.URL.Query().Get("id")
+	var body struct {
+		Lifecycle string     `json:"lifecycle"`
+		PublishAt *time.Time `json:"publishAt"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		res.Message = "Invalid request body: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	if questionID == "" || body.Lifecycle == "" {
+		res.Message = "id and lifecycle are required"
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	userId, _ := r.Context().Value(middlewares.UserIDKey).(string)
+	question, err := svc.Controller.SetLifecycle(questionID, body.Lifecycle, body.PublishAt, userId)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = question
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// DeleteQuestion archives a question and its test cases, it can be restored later
+func (svc *QuestionService) DeleteQuestion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		res.Message = "id is required"
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	userId, _ := r.Context().Value(middlewares.UserIDKey).(string)
+	result, err := svc.Controller.ArchiveQuestion(id, userId)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = result
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// RestoreQuestion brings an archived question back with the test cases archived along with it
+func (svc *QuestionService) RestoreQuestion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		res.Message = "id is required"
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	userId, _ := r.Context().Value(middlewares.UserIDKey).(string)
+	result, err := svc.Controller.RestoreQuestion(id, userId)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = result
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// PurgeQuestion permanently deletes an archived question and everything that belongs to it
+func (svc *QuestionService) PurgeQuestion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		res.Message = "id is required"
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	result, err := svc.Controller.PurgeQuestion(id)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = result
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// DeleteTestCase archives a test case batch, the judge stops using it
+func (svc *QuestionService) DeleteTestCase(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		res.Message = "id is required"
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	userId, _ := r.Context().Value(middlewares.UserIDKey).(string)
+	result, err := svc.Controller.ArchiveTestCase(id, userId)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = result
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// RestoreTestCase brings an archived test case batch back
+func (svc *QuestionService) RestoreTestCase(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		res.Message = "id is required"
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	result, err := svc.Controller.RestoreTestCase(id)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = result
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// PurgeTestCase permanently deletes an archived test case batch
+func (svc *QuestionService) PurgeTestCase(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		res.Message = "id is required"
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	if err := svc.Controller.PurgeTestCase(id); err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = id
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// CloneQuestion copies a question into a new draft, optionally with its test
+// cases copied or linked to the originals
+func (svc *QuestionService) CloneQuestion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	questionID := r.URL.Query().Get("id")
+	if questionID == "" {
+		res.Message = "id is required"
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	var cloneOptions models.CloneOptions
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&cloneOptions); err != nil {
+			res.Message = "Invalid request body: " + err.Error()
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(res)
+			return
+		}
+	}
+	userId, _ := r.Context().Value(middlewares.UserIDKey).(string)
+	question, testCases, err := svc.Controller.CloneQuestion(questionID, cloneOptions, userId)
+	if err != nil {
+		res.Message = err.Error()
+		if question != nil {
+			res.Data = map[string]interface{}{
+				"question":  question,
+				"testCases": testCases,
+			}
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = map[string]interface{}{
+		"question":  question,
+		"testCases": testCases,
+	}
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,40 @@
+package usecases
+
+import (
+	"code-compiler/internal/models"
+	"encoding/json"
+	"net/http"
+)
+
+// GenerateTemplates returns the code templates of every language generated
+// from the function signature in the body, nothing is saved
+func (svc *QuestionService) GenerateTemplates(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	var signature models.FunctionSignature
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&signature); err != nil {
+		res.Message = "Invalid request body: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	templates, err := svc.Controller.GenerateTemplates(&signature)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = map[string]interface{}{
+		"signature":             signature,
+		"testCaseVariableNames": signature.VariableNames(),
+		"codeTemplates":         templates,
+	}
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
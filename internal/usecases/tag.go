@@ -0,0 +1,143 @@
+package usecases
+
+import (
+	"code-compiler/internal/middlewares"
+	"code-compiler/internal/models"
+	"encoding/json"
+	"net/http"
+)
+
+// GetTags lists the tag taxonomy as a tree with question counts
+func (svc *QuestionService) GetTags(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	tags, err := svc.Controller.GetTagTree(middlewares.IsAdminRequest(r))
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = tags
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func (svc *QuestionService) CreateTag(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	var tag models.Tag
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&tag); err != nil {
+		res.Message = "Invalid request body: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	created, err := svc.Controller.CreateTag(&tag)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = created
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// UpdateTag changes the description, aliases or parent of the tag named in the query
+func (svc *QuestionService) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		res.Message = "name is required"
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	var patch models.TagPatch
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patch); err != nil {
+		res.Message = "Invalid request body: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	tag, err := svc.Controller.UpdateTag(name, &patch)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = tag
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// RenameTag renames a tag across all questions
+func (svc *QuestionService) RenameTag(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	var rename models.TagRename
+	if err := json.NewDecoder(r.Body).Decode(&rename); err != nil {
+		res.Message = "Invalid request body: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	userId, _ := r.Context().Value(middlewares.UserIDKey).(string)
+	tag, updated, err := svc.Controller.RenameTag(rename, userId)
+	writeRetagResponse(w, res, tag, updated, err)
+}
+
+// MergeTags folds tags into a target tag across all questions
+func (svc *QuestionService) MergeTags(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	var merge models.TagMerge
+	if err := json.NewDecoder(r.Body).Decode(&merge); err != nil {
+		res.Message = "Invalid request body: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	userId, _ := r.Context().Value(middlewares.UserIDKey).(string)
+	tag, updated, err := svc.Controller.MergeTags(merge, userId)
+	writeRetagResponse(w, res, tag, updated, err)
+}
+
+// writeRetagResponse reports the tag and the number of questions changed,
+// also when some questions could not be changed
+func writeRetagResponse(w http.ResponseWriter, res *models.Response, tag *models.Tag, updated int, err error) {
+	if tag != nil {
+		res.Data = map[string]interface{}{
+			"tag":              tag,
+			"questionsUpdated": updated,
+		}
+	}
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
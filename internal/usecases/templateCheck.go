@@ -0,0 +1,71 @@
+package usecases
+
+import (
+	"code-compiler/internal/models"
+	"encoding/json"
+	"net/http"
+)
+
+// CheckTemplates compiles the code templates of a question and runs the
+// reference solutions in them. Failing checks are reported with a 200, ok
+// telling whether every language passed.
+func (svc *QuestionService) CheckTemplates(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	var request models.TemplateCheckRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		res.Message = "Invalid request body: " + err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	checks, err := svc.Controller.CheckTemplates(&request)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	ok := true
+	for _, check := range checks {
+		ok = ok && check.Ok()
+	}
+	res.Status = true
+	res.Data = map[string]interface{}{
+		"ok":     ok,
+		"checks": checks,
+	}
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// PreviewTemplates returns the code templates of a question exactly as the
+// users' editor shows them
+func (svc *QuestionService) PreviewTemplates(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	res := &models.Response{}
+	questionID := r.URL.Query().Get("questionId")
+	if questionID == "" {
+		res.Message = "questionId is required"
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	templates, err := svc.Controller.PreviewTemplates(questionID)
+	if err != nil {
+		res.Message = err.Error()
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(res)
+		return
+	}
+	res.Status = true
+	res.Data = templates
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -2,30 +2,154 @@ package utils
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
 )
 
-func MakeSlug(title string) string {
-	// Convert to lowercase
-	slug := strings.ToLower(title)
+// maxSlugLength leaves room for a numeric suffix within typical URL segments
+const maxSlugLength = 60
+
+var slugPattern = regexp.MustCompile("^[a-z0-9]+(-[a-z0-9]+)*$")
+
+// transliterations covers letters that do not decompose into a base letter
+// and an accent, plus the Greek and Cyrillic alphabets
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'ł': "l", 'đ': "d", 'ð': "d", 'þ': "th", 'ı': "i",
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th", 'ι': "i",
+	'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s",
+	'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye",
+}
+
+// devanagariConsonants carry an inherent a, dropped before a vowel sign, a
+// virama or the end of a word
+var devanagariConsonants = map[rune]string{
+	'क': "k", 'ख': "kh", 'ग': "g", 'घ': "gh", 'ङ': "n", 'च': "ch", 'छ': "chh", 'ज': "j", 'झ': "jh",
+	'ञ': "n", 'ट': "t", 'ठ': "th", 'ड': "d", 'ढ': "dh", 'ण': "n", 'त': "t", 'थ': "th", 'द': "d",
+	'ध': "dh", 'न': "n", 'प': "p", 'फ': "ph", 'ब': "b", 'भ': "bh", 'म': "m", 'य': "y", 'र': "r",
+	'ल': "l", 'ळ': "l", 'व': "v", 'श': "sh", 'ष': "sh", 'स': "s", 'ह': "h",
+}
 
-	// Replace spaces with hyphens
-	slug = strings.ReplaceAll(slug, " ", "-")
+// devanagariSigns are the vowels, vowel signs, marks and digits of Devanagari
+var devanagariSigns = map[rune]string{
+	'अ': "a", 'आ': "aa", 'इ': "i", 'ई': "ii", 'उ': "u", 'ऊ': "uu", 'ऋ': "ri", 'ए': "e", 'ऐ': "ai",
+	'ओ': "o", 'औ': "au", 'ा': "aa", 'ि': "i", 'ी': "ii", 'ु': "u", 'ू': "uu", 'ृ': "ri", 'े': "e",
+	'ै': "ai", 'ो': "o", 'ौ': "au", '्': "", '़': "", 'ं': "n", 'ँ': "n", 'ः': "h",
+	'०': "0", '१': "1", '२': "2", '३': "3", '४': "4", '५': "5", '६': "6", '७': "7", '८': "8", '९': "9",
+}
+
+// devanagariVowelSigns replace the inherent a of the consonant before them
+const devanagariVowelSigns = "ािीुूृेैोौ्"
+
+// transliterateDevanagari transliterates the rune at i, looking at the next
+// one to tell whether a consonant keeps its inherent a
+func transliterateDevanagari(runes []rune, i int) (string, bool) {
+	if sign, ok := devanagariSigns[runes[i]]; ok {
+		return sign, true
+	}
+	consonant, ok := devanagariConsonants[runes[i]]
+	if !ok {
+		return "", false
+	}
+	next := i + 1
+	for next < len(runes) && runes[next] == '़' {
+		next++
+	}
+	if next == len(runes) || strings.ContainsRune(devanagariVowelSigns, runes[next]) {
+		return consonant, true
+	}
+	if _, ok := devanagariConsonants[runes[next]]; ok {
+		return consonant + "a", true
+	}
+	if sign, ok := devanagariSigns[runes[next]]; ok && sign != "" && (runes[next] < '०' || runes[next] > '९') {
+		return consonant + "a", true
+	}
+	return consonant, true
+}
 
-	// Remove special characters (keep alphanumeric and hyphens)
-	re := regexp.MustCompile("[^a-z0-9-]")
-	slug = re.ReplaceAllString(slug, "")
+// MakeSlug turns a title into a URL slug. Accents are dropped, Greek,
+// Cyrillic and Devanagari are transliterated and other scripts are left out,
+// so the slug can be empty and callers need a fallback.
+func MakeSlug(title string) string {
+	var slug strings.Builder
+	runes := []rune(norm.NFC.String(strings.ToLower(title)))
+	for i, r := range runes {
+		if transliteration, ok := transliterateDevanagari(runes, i); ok {
+			slug.WriteString(transliteration)
+			continue
+		}
+		if transliteration, ok := transliterations[r]; ok {
+			slug.WriteString(transliteration)
+			continue
+		}
+		for _, d := range norm.NFKD.String(string(r)) {
+			if transliteration, ok := transliterations[d]; ok {
+				slug.WriteString(transliteration)
+				continue
+			}
+			switch {
+			case d >= 'a' && d <= 'z', d >= '0' && d <= '9':
+				slug.WriteRune(d)
+			case unicode.Is(unicode.Mn, d):
+				// accent split off by the decomposition
+			default:
+				slug.WriteRune('-')
+			}
+		}
+	}
 
 	// Remove consecutive hyphens
-	slug = regexp.MustCompile("-+").ReplaceAllString(slug, "-")
+	result := regexp.MustCompile("-+").ReplaceAllString(slug.String(), "-")
 
 	// Trim hyphens from start and end
-	slug = strings.Trim(slug, "-")
+	result = strings.Trim(result, "-")
 
-	// Trim to a maximum length of 30 characters
-	if len(slug) > 30 {
-		slug = slug[:30]
+	return cutSlug(result, maxSlugLength)
+}
+
+// cutSlug cuts a slug longer than limit at a word boundary
+func cutSlug(slug string, limit int) string {
+	if len(slug) <= limit {
+		return slug
 	}
+	slug = slug[:limit]
+	if cut := strings.LastIndex(slug, "-"); cut > limit/2 {
+		slug = slug[:cut]
+	}
+	return strings.Trim(slug, "-")
+}
 
-	return slug
+// IsValidSlug reports whether a custom slug is lowercase words joined by single hyphens
+func IsValidSlug(slug string) bool {
+	return len(slug) <= maxSlugLength && slugPattern.MatchString(slug)
+}
+
+// SlugWithSuffix numbers a slug to tell apart questions with the same title,
+// the first one keeps the plain slug. The base is cut shorter to leave room
+// for the number.
+func SlugWithSuffix(base string, n int) string {
+	if n <= 1 {
+		return base
+	}
+	suffix := "-" + strconv.Itoa(n)
+	return cutSlug(base, maxSlugLength-len(suffix)) + suffix
+}
+
+// IsSlugVariant reports whether the slug is the base slug or a numbered variant of it
+func IsSlugVariant(slug string, base string) bool {
+	if slug == base {
+		return true
+	}
+	cut := strings.LastIndex(slug, "-")
+	if cut < 0 {
+		return false
+	}
+	n, err := strconv.Atoi(slug[cut+1:])
+	return err == nil && n > 1 && SlugWithSuffix(base, n) == slug
 }
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMakeSlug(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Two Sum", "two-sum"},
+		{"  Reverse -- a   Linked List!  ", "reverse-a-linked-list"},
+		{"Crème brûlée", "creme-brulee"},
+		{"Straße", "strasse"},
+		{"Ωμέγα", "omega"},
+		{"Задача о рюкзаке", "zadacha-o-ryukzake"},
+		{"नमस्ते दुनिया", "namaste-duniyaa"},
+		{"भारत", "bhaarat"},
+		{"संख्या १२", "sankhyaa-12"},
+		{"क़िला", "kilaa"},
+		{"二数之和", ""},
+		{"二数之和 2", "2"},
+		{strings.Repeat("word ", 20), "word-word-word-word-word-word-word-word-word-word-word-word"},
+		{strings.Repeat("x", 70), strings.Repeat("x", 60)},
+	}
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			got := MakeSlug(test.title)
+			if got != test.want {
+				t.Errorf("MakeSlug(%q) = %q, want %q", test.title, got, test.want)
+			}
+			if got != "" && !IsValidSlug(got) {
+				t.Errorf("MakeSlug(%q) = %q is not a valid slug", test.title, got)
+			}
+		})
+	}
+}
+
+func TestSlugWithSuffix(t *testing.T) {
+	long := MakeSlug(strings.Repeat("word ", 20))
+	unbroken := strings.Repeat("x", 60)
+	tests := []struct {
+		base string
+		n    int
+		want string
+	}{
+		{"two-sum", 1, "two-sum"},
+		{"two-sum", 2, "two-sum-2"},
+		{"two-sum", 1000, "two-sum-1000"},
+		{long, 2, "word-word-word-word-word-word-word-word-word-word-word-2"},
+		{long, 1000, "word-word-word-word-word-word-word-word-word-word-word-1000"},
+		{unbroken, 2, strings.Repeat("x", 58) + "-2"},
+		{unbroken, 1000, strings.Repeat("x", 55) + "-1000"},
+	}
+	for _, test := range tests {
+		got := SlugWithSuffix(test.base, test.n)
+		if got != test.want {
+			t.Errorf("SlugWithSuffix(%q, %d) = %q, want %q", test.base, test.n, got, test.want)
+		}
+		if !IsValidSlug(got) {
+			t.Errorf("SlugWithSuffix(%q, %d) = %q is not a valid slug", test.base, test.n, got)
+		}
+		if !IsSlugVariant(got, test.base) {
+			t.Errorf("IsSlugVariant(%q, %q) = false", got, test.base)
+		}
+	}
+}
+
+func TestIsSlugVariant(t *testing.T) {
+	tests := []struct {
+		slug string
+		base string
+		want bool
+	}{
+		{"two-sum", "two-sum", true},
+		{"two-sum-3", "two-sum", true},
+		{"two-sum-1", "two-sum", false},
+		{"two-sum-03", "two-sum", false},
+		{"two-sum-x", "two-sum", false},
+		{"two-sum-3", "two", false},
+		{"three-sum-2", "two-sum", false},
+		{"sum", "two-sum", false},
+	}
+	for _, test := range tests {
+		if got := IsSlugVariant(test.slug, test.base); got != test.want {
+			t.Errorf("IsSlugVariant(%q, %q) = %v, want %v", test.slug, test.base, got, test.want)
+		}
+	}
+}
+
+func TestIsValidSlug(t *testing.T) {
+	tests := []struct {
+		slug string
+		want bool
+	}{
+		{"two-sum", true},
+		{"a1", true},
+		{"", false},
+		{"Two-Sum", false},
+		{"two--sum", false},
+		{"-two", false},
+		{"two_sum", false},
+		{strings.Repeat("a", 61), false},
+	}
+	for _, test := range tests {
+		if got := IsValidSlug(test.slug); got != test.want {
+			t.Errorf("IsValidSlug(%q) = %v, want %v", test.slug, got, test.want)
+		}
+	}
+}
@@ -6,25 +6,67 @@ import (
 	"code-compiler/internal/routes"
 	"code-compiler/internal/usecases"
 	"code-compiler/internal/middlewares"
+	"code-compiler/internal/storage"
 	"context"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 )
 
 func main() {
+	backfillStats := flag.Bool("backfill-stats", false, "recompute the question statistics from the stored submissions and exit")
+	renderStatements := flag.Bool("render-statements", false, "render the Markdown statements of every question to HTML and exit")
+	flag.Parse()
 	db.ConnectDB()
+	if *renderStatements {
+		updated, err := repository.RenderAllStatements()
+		if err != nil {
+			fmt.Println("Statement rendering failed:", err)
+		}
+		fmt.Println("Rendered the statements of", updated, "questions")
+		db.DisconnectDB()
+		if err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+	if *backfillStats {
+		updated, err := repository.BackfillQuestionStats()
+		if err != nil {
+			fmt.Println("Stats backfill failed:", err)
+		}
+		fmt.Println("Stats backfill updated", updated, "questions")
+		db.DisconnectDB()
+		if err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+	storage.Init()
 	port := os.Getenv("PORT")
 	r := mux.NewRouter()
 	fmt.Println("port is", port)
 	questionController := &repository.Question{}
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	questionController.StartPublishScheduler(schedulerCtx, time.Minute)
+	if err := questionController.BuildSearchIndex(); err != nil {
+		fmt.Println("Search index could not be built:", err)
+	}
+	questionController.StartSearchIndexRefresher(schedulerCtx, 5*time.Minute)
 	questionService := &usecases.QuestionService{Controller: questionController}
 	codeRunner := &repository.CodeRunner{}
+	if resumed, err := codeRunner.ResumeRejudgeJobs(); err != nil {
+		fmt.Println("Rejudge jobs could not be resumed:", err)
+	} else if resumed > 0 {
+		fmt.Println("Resumed", resumed, "rejudge jobs")
+	}
 	codeRunService := &usecases.CodeRunnerService{Runner: codeRunner}
 	testRunner := &repository.Test{}
 	testService := &usecases.TestService{Controller: testRunner}
@@ -63,6 +105,7 @@ func main() {
 	}()
 	<-stop // Wait for an interrupt signal
 	fmt.Println("Shutting down server...")
+	stopScheduler()
 	if err := srv.Shutdown(context.Background()); err != nil {
 		fmt.Println("Server Shutdown:", err)
 	}